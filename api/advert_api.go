@@ -1,17 +1,25 @@
 package api
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	"github.com/sirupsen/logrus"
 	application "github.com/ukrainian-brothers/board-backend/app"
 	"github.com/ukrainian-brothers/board-backend/domain"
 	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
 	"github.com/ukrainian-brothers/board-backend/internal/common"
 	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+	"io"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -29,7 +37,13 @@ type AdvertAPI struct {
 func NewAdvertAPI(r *mux.Router, log *logrus.Entry, app application.Application, middleware *MiddlewareProvider, sessionStore *sessions.CookieStore, cfg *common.Config) *AdvertAPI {
 	advertApi := AdvertAPI{router: r, app: app, log: log, sessionStore: sessionStore, cfg: cfg}
 	r.HandleFunc("/api/adverts", middleware.AuthMiddleware(advertApi.AddAdvert, log)).Methods("POST")
-	r.HandleFunc("/api/adverts", advertApi.AdvertsList).Methods("GET")
+	r.HandleFunc("/api/adverts", middleware.AuthMiddleware(advertApi.AdvertsList, log)).Methods("GET")
+	r.HandleFunc("/api/adverts/{id}", middleware.AuthMiddleware(advertApi.GetAdvert, log)).Methods("GET")
+	r.HandleFunc("/api/adverts/{id}", middleware.AuthMiddleware(advertApi.UpdateAdvert, log)).Methods("PUT", "PATCH")
+	r.HandleFunc("/api/adverts/{id}", middleware.AuthMiddleware(advertApi.DeleteAdvert, log)).Methods("DELETE")
+	r.HandleFunc("/api/adverts/{id}/history", middleware.AuthMiddleware(advertApi.AdvertHistory, log)).Methods("GET")
+	r.HandleFunc("/api/adverts/{id}/renew", middleware.AuthMiddleware(advertApi.RenewAdvert, log)).Methods("POST")
+	r.HandleFunc("/api/adverts/{id}/contact", middleware.AuthMiddleware(advertApi.RevealContact, log)).Methods("POST")
 	return &advertApi
 }
 
@@ -37,16 +51,125 @@ type contactPayload struct {
 	Mail        string `json:"mail"`
 	PhoneNumber string `json:"phone"`
 }
+
+type locationPayload struct {
+	City      string   `json:"city"`
+	Region    string   `json:"region,omitempty"`
+	Country   string   `json:"country"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+func (p locationPayload) Location() (domain.Location, error) {
+	var coordinates *domain.Coordinates
+	if p.Latitude != nil || p.Longitude != nil {
+		if p.Latitude == nil || p.Longitude == nil {
+			return domain.Location{}, domain.InvalidCoordinatesErr
+		}
+		coordinates = &domain.Coordinates{Latitude: *p.Latitude, Longitude: *p.Longitude}
+	}
+	return domain.NewLocation(p.City, p.Region, p.Country, coordinates)
+}
+
 type newAdvertPayload struct {
 	Title          MultilingualString `json:"title"`
 	Description    MultilingualString `json:"description"`
 	Type           domain.AdvertType  `json:"type"`
 	ContactDetails contactPayload     `json:"contact_details"`
+	Location       *locationPayload   `json:"location"`
+	// Attributes depend on the advert type, e.g. {"capacity": 4, "pets_allowed": true} for place_to_stay
+	Attributes json.RawMessage `json:"attributes,omitempty"`
+}
+
+// decodeAttributes reads the attributes of the given advert type, unknown fields are rejected
+func decodeAttributes(advertType domain.AdvertType, raw json.RawMessage) (domain.AdvertAttributes, error) {
+	attributes := domain.AdvertAttributes{}
+	var target interface{}
+	switch advertType {
+	case domain.AdvertTypePlaceToStay:
+		attributes.PlaceToStay = &domain.PlaceToStayAttributes{}
+		target = attributes.PlaceToStay
+	case domain.AdvertTypeTransport:
+		attributes.Transport = &domain.TransportAttributes{}
+		target = attributes.Transport
+	case domain.AdvertTypeJob:
+		attributes.Job = &domain.JobAttributes{}
+		target = attributes.Job
+	case domain.AdvertTypeLawyer:
+		attributes.Lawyer = &domain.LawyerAttributes{}
+		target = attributes.Lawyer
+	default:
+		return domain.AdvertAttributes{}, domain.InvalidAttributesErr
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(target)
+	if err != nil {
+		return domain.AdvertAttributes{}, fmt.Errorf("%w: %s", domain.InvalidAttributesErr, err)
+	}
+	return attributes, nil
+}
+
+// attributesResponse returns only the attributes of the advert type, so they have the same shape as in the payload
+func attributesResponse(details domain.AdvertDetails) interface{} {
+	attributes := details.Attributes
+	switch {
+	case attributes.PlaceToStay != nil:
+		return attributes.PlaceToStay
+	case attributes.Transport != nil:
+		return attributes.Transport
+	case attributes.Job != nil:
+		return attributes.Job
+	case attributes.Lawyer != nil:
+		return attributes.Lawyer
+	}
+	return nil
+}
+
+// hasAttributes tells if the attributes were sent, explicit null is treated as missing
+func hasAttributes(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
 }
 
-func (p *newAdvertPayload) RemoveUnsupportedLanguages() {
-	p.Title.RemoveUnsupported()
-	p.Description.RemoveUnsupported()
+// advertTextsPayload are the multilingual fields of the advert payloads kept raw for DecodeStrict
+type advertTextsPayload struct {
+	Title       json.RawMessage `json:"title"`
+	Description json.RawMessage `json:"description"`
+}
+
+// decodeAdvertTexts decodes the title and description of already decoded payload in strict mode,
+// so the client learns which languages were rejected instead of having them dropped
+func decodeAdvertTexts(body []byte) (MultilingualString, MultilingualString, error) {
+	texts := advertTextsPayload{}
+	err := json.Unmarshal(body, &texts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs validation.Errors
+	title, err := DecodeStrict(texts.Title, "title", advert.MaxTitleLength)
+	if textErrors, ok := validation.As(err); ok {
+		errs = append(errs, textErrors...)
+	}
+	description, err := DecodeStrict(texts.Description, "description", advert.MaxDescriptionLength)
+	if textErrors, ok := validation.As(err); ok {
+		errs = append(errs, textErrors...)
+	}
+	return title, description, errs.Err()
+}
+
+// advertErrorDetails describes why the advert was rejected by the domain, the rejected fields are listed by WriteValidationError
+func advertErrorDetails(err error) (code string, details string) {
+	switch {
+	case errors.Is(err, domain.InvalidAttributesErr):
+		return "advert.invalid_attributes", "invalid attributes"
+	case errors.Is(err, advert.InvalidLanguages):
+		return "advert.no_common_language", "title and description have no common language"
+	case errors.Is(err, advert.InvalidAdvertTypeErr):
+		return "advert.invalid_type", "unknown advert type"
+	}
+	return "advert.invalid_details", "invalid advert details"
 }
 
 func (a AdvertAPI) AddAdvert(w http.ResponseWriter, r *http.Request) {
@@ -56,54 +179,91 @@ func (a AdvertAPI) AddAdvert(w http.ResponseWriter, r *http.Request) {
 	userLogin := ctx.Value("user_login")
 	if userLogin == nil {
 		log.Info("not authorized user tries to add advert")
-		WriteError(w, http.StatusForbidden, "not authorized")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
 		return
 	}
 
 	log = log.WithField("user_login", userLogin.(string))
-	dec := json.NewDecoder(r.Body)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.WithError(err).Error("failed reading newAdvert payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
 	dec.DisallowUnknownFields()
 
 	payload := newAdvertPayload{}
-	err := dec.Decode(&payload)
+	err = dec.Decode(&payload)
 	if err != nil {
 		log.WithError(err).Error("failed decoding newAdvert payload")
-		WriteError(w, http.StatusUnprocessableEntity, "invalid payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+
+	payload.Title, payload.Description, err = decodeAdvertTexts(body)
+	if err != nil {
+		WriteValidationError(w, "advert.invalid_text", "invalid multilingual text", err)
 		return
 	}
-	payload.RemoveUnsupportedLanguages()
 
 	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
 	if err != nil {
 		if errors.Unwrap(err) == sql.ErrNoRows {
 			log.Info("not authorized user tries to add advert")
-			WriteError(w, http.StatusForbidden, "user does not exists anymore")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
 			return
 		}
 		log.WithError(err).Error("AddAdvert failed getting user by login")
-		WriteError(w, http.StatusInternalServerError, "")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
 	advertContact, err := domain.NewContactDetails(payload.ContactDetails.Mail, payload.ContactDetails.PhoneNumber)
 	if err != nil {
 		log.WithError(err).Error("AddAdvert failed creating contact details")
-		WriteError(w, http.StatusUnprocessableEntity, "invalid payload")
+		WriteValidationError(w, "invalid_payload", "invalid payload", validation.Prefix(err, "contact_details"))
 		return
 	}
 
-	adv, err := advert.NewAdvert(usr, payload.Title, payload.Description, payload.Type, advert.WithContactDetails(advertContact))
+	opts := []advert.AdvertOption{advert.WithContactDetails(advertContact)}
+	if payload.Location != nil {
+		location, err := payload.Location.Location()
+		if err != nil {
+			log.WithError(err).Error("AddAdvert failed creating location")
+			WriteError(w, http.StatusUnprocessableEntity, "location.invalid", "invalid location")
+			return
+		}
+		opts = append(opts, advert.WithLocation(location))
+	}
+
+	if hasAttributes(payload.Attributes) {
+		attributes, err := decodeAttributes(payload.Type, payload.Attributes)
+		if err != nil {
+			log.WithError(err).Error("AddAdvert failed decoding attributes")
+			WriteError(w, http.StatusUnprocessableEntity, "advert.invalid_attributes", "invalid attributes")
+			return
+		}
+		opts = append(opts, advert.WithAttributes(attributes))
+	}
+
+	adv, err := advert.NewAdvert(usr, payload.Title, payload.Description, payload.Type, opts...)
 	if err != nil {
 		log.WithError(err).Error("AddAdvert failed creating advert")
-		WriteError(w, http.StatusUnprocessableEntity, "invalid advert details")
+		code, details := advertErrorDetails(err)
+		WriteValidationError(w, code, details, err)
 		return
 	}
 
 	err = a.app.Commands.AddAdvert.Execute(ctx, adv)
 	if err != nil {
-		log.WithError(err).Error("AddAdvert failed inserting advert")
-		WriteError(w, http.StatusInternalServerError, "")
-		return
+		if !errors.Is(err, search.MatchingFailedErr) {
+			log.WithError(err).Error("AddAdvert failed inserting advert")
+			WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+			return
+		}
+		// the advert is stored, missed notifications shouldn't make the author add it again
+		log.WithError(err).Error("AddAdvert failed matching saved searches")
 	}
 
 	response := advertResponse{}
@@ -111,15 +271,612 @@ func (a AdvertAPI) AddAdvert(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, 201, response)
 }
 
+type updateAdvertPayload struct {
+	Title          MultilingualString `json:"title"`
+	Description    MultilingualString `json:"description"`
+	Type           domain.AdvertType  `json:"type"`
+	ContactDetails *contactPayload    `json:"contact_details"`
+	Location       *locationPayload   `json:"location"`
+	Attributes     json.RawMessage    `json:"attributes,omitempty"`
+}
+
+// complete reports whether all fields are provided, which is required for PUT requests
+func (p updateAdvertPayload) complete() bool {
+	return !p.Title.Empty() && !p.Description.Empty() && p.Type != "" && p.ContactDetails != nil
+}
+
+// isAdvertNotFound checks both the repository error and the raw sql one, as not every repository method translates it
+func isAdvertNotFound(err error) bool {
+	return errors.Is(err, advert.AdvertNotFound) || errors.Is(err, sql.ErrNoRows)
+}
+
+// sessionUser returns the logged in user, nil is returned for anonymous requests and users which don't exist anymore
+func sessionUser(r *http.Request, app application.Application) (*user.User, error) {
+	userLogin := r.Context().Value("user_login")
+	if userLogin == nil {
+		return nil, nil
+	}
+
+	usr, err := app.Queries.GetUserByLogin.Execute(r.Context(), userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return usr, nil
+}
+
+// viewerKey identifies the viewer by the session if the user is logged in, otherwise by the IP address resolved by ClientIPMiddleware
+func viewerKey(r *http.Request) string {
+	userLogin := r.Context().Value("user_login")
+	if userLogin != nil {
+		return "user:" + userLogin.(string)
+	}
+
+	return "ip:" + clientIP(r)
+}
+
+// clientIP returns the address resolved by ClientIPMiddleware, the peer of the connection is used without the middleware
+func clientIP(r *http.Request) string {
+	if address, ok := r.Context().Value("client_ip").(string); ok {
+		return address
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host
+}
+
+func (a AdvertAPI) GetAdvert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log.WithField("advert_id", mux.Vars(r)["id"])
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	// Will load languages from url param &langs=uk,pl,en into slice
+	langs := LanguageTags{}.FromStrings(strings.Split(r.FormValue("langs"), ","))
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("GetAdvert failed while fetching advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	usr, err := sessionUser(r, a.app)
+	if err != nil {
+		log.WithError(err).Error("GetAdvert failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	// not published advert is reported as missing, so its existence doesn't leak before the review
+	if adv.Status != advert.StatusPublished && !adv.CanBeViewedBy(usr) {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	// explicitly selected langs hide the advert without them, negotiated languages fall back to the best available translation
+	if !langs.Empty() {
+		adv.Details.Title = adv.Details.Title.Filter(langs)
+		adv.Details.Description = adv.Details.Description.Filter(langs)
+		if adv.Details.Title.Empty() || adv.Details.Description.Empty() {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+	}
+
+	// previews of not published adverts by their owner and moderators are not counted
+	if adv.Status == advert.StatusPublished {
+		counted, err := a.app.Commands.CountAdvertView.Execute(ctx, adv.ID, viewerKey(r))
+		if err != nil {
+			// not counted view shouldn't prevent the user from seeing the advert
+			log.WithError(err).Error("GetAdvert failed counting advert view")
+		}
+		if counted {
+			adv.Details.Views++
+		}
+	}
+
+	response := advertResponse{}
+	response.LoadAdvert(&adv)
+	response.LoadAuthor(adv.User)
+	response.LoadContact(&adv, usr, a.app.Commands.RevealContact.Policy)
+	if chain := a.negotiateLanguages(r); chain != nil {
+		response.LoadNegotiated(chain)
+	}
+	w.Header().Add("Vary", "Accept-Language")
+	if favourites := favouritesOf(ctx, a.app, log, usr, &adv); favourites != nil {
+		response.LoadFavourite(favourites[adv.ID])
+	}
+	WriteJSON(w, 200, response)
+}
+
+// UpdateAdvert replaces the advert on PUT, PATCH changes only the sent fields and languages of the title and description
+func (a AdvertAPI) UpdateAdvert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to update advert")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.WithError(err).Error("failed reading updateAdvert payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	payload := updateAdvertPayload{}
+	err = dec.Decode(&payload)
+	if err != nil {
+		log.WithError(err).Error("failed decoding updateAdvert payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+
+	payload.Title, payload.Description, err = decodeAdvertTexts(body)
+	if err != nil {
+		WriteValidationError(w, "advert.invalid_text", "invalid multilingual text", err)
+		return
+	}
+
+	if r.Method == "PUT" && !payload.complete() {
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to update advert")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("UpdateAdvert failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("UpdateAdvert failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if r.Method == "PATCH" {
+		// PATCH changes only the sent languages, PUT replaces the whole title and description
+		payload.Title, payload.Description = adv.MergeTexts(payload.Title, payload.Description)
+	}
+
+	var opts []advert.AdvertOption
+	if payload.ContactDetails != nil {
+		advertContact, err := domain.NewContactDetails(payload.ContactDetails.Mail, payload.ContactDetails.PhoneNumber)
+		if err != nil {
+			log.WithError(err).Error("UpdateAdvert failed creating contact details")
+			WriteValidationError(w, "invalid_payload", "invalid payload", validation.Prefix(err, "contact_details"))
+			return
+		}
+		opts = append(opts, advert.WithContactDetails(advertContact))
+	}
+
+	if payload.Location != nil {
+		location, err := payload.Location.Location()
+		if err != nil {
+			log.WithError(err).Error("UpdateAdvert failed creating location")
+			WriteError(w, http.StatusUnprocessableEntity, "location.invalid", "invalid location")
+			return
+		}
+		opts = append(opts, advert.WithLocation(location))
+	}
+
+	if hasAttributes(payload.Attributes) {
+		advertType := payload.Type
+		if advertType == "" {
+			advertType = adv.Details.Type
+		}
+		attributes, err := decodeAttributes(advertType, payload.Attributes)
+		if err != nil {
+			log.WithError(err).Error("UpdateAdvert failed decoding attributes")
+			WriteError(w, http.StatusUnprocessableEntity, "advert.invalid_attributes", "invalid attributes")
+			return
+		}
+		opts = append(opts, advert.WithAttributes(attributes))
+	}
+
+	err = adv.Update(usr, payload.Title, payload.Description, payload.Type, opts...)
+	if err != nil {
+		if errors.Is(err, advert.NotAdvertOwnerErr) {
+			log.Info("user tries to update not owned advert")
+			WriteError(w, http.StatusForbidden, "advert.not_owner", "not advert owner")
+			return
+		}
+		log.WithError(err).Error("UpdateAdvert failed updating advert")
+		code, details := advertErrorDetails(err)
+		WriteValidationError(w, code, details, err)
+		return
+	}
+
+	err = a.app.Commands.UpdateAdvert.Execute(ctx, &adv)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		if errors.Is(err, advert.AdvertChangedErr) {
+			WriteError(w, http.StatusConflict, "advert.changed", "advert has been changed in the meantime, load it again")
+			return
+		}
+		log.WithError(err).Error("UpdateAdvert failed updating advert in repository")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := advertResponse{}
+	response.LoadAdvert(&adv)
+	WriteJSON(w, 200, response)
+}
+
+func (a AdvertAPI) DeleteAdvert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to delete advert")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to delete advert")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("DeleteAdvert failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("DeleteAdvert failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = adv.Destroy(usr)
+	if err != nil {
+		if errors.Is(err, advert.NotAdvertOwnerErr) {
+			log.Info("user tries to delete not owned advert")
+			WriteError(w, http.StatusForbidden, "advert.not_owner", "not advert owner")
+			return
+		}
+		log.WithError(err).Error("DeleteAdvert failed destroying advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.DeleteAdvert.Execute(ctx, &adv)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("DeleteAdvert failed deleting advert in repository")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
+
+func (a AdvertAPI) RenewAdvert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to renew advert")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to renew advert")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("RenewAdvert failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("RenewAdvert failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = adv.Renew(usr)
+	if err != nil {
+		if errors.Is(err, advert.NotAdvertOwnerErr) {
+			log.Info("user tries to renew not owned advert")
+			WriteError(w, http.StatusForbidden, "advert.not_owner", "not advert owner")
+			return
+		}
+		log.WithError(err).Error("RenewAdvert failed renewing advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.RenewAdvert.Execute(ctx, &adv)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		if errors.Is(err, advert.AdvertChangedErr) {
+			WriteError(w, http.StatusConflict, "advert.changed", "advert has been changed in the meantime, load it again")
+			return
+		}
+		log.WithError(err).Error("RenewAdvert failed updating advert in repository")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := advertResponse{}
+	response.LoadAdvert(&adv)
+	WriteJSON(w, 200, response)
+}
+
+func (a AdvertAPI) RevealContact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to reveal advert contact")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to reveal advert contact")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("RevealContact failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("RevealContact failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if !adv.CanBeViewedBy(usr) {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	contact, err := a.app.Commands.RevealContact.Execute(ctx, &adv, usr)
+	if err != nil {
+		if errors.Is(err, advert.TooManyContactRevealsErr) {
+			log.Info("user reveals too many contacts")
+			WriteError(w, http.StatusTooManyRequests, "contact.too_many_reveals", "too many contacts revealed, try again later")
+			return
+		}
+		if errors.Is(err, advert.ContactEmptyErr) {
+			WriteError(w, http.StatusNotFound, "contact.not_found", "advert has no contact details")
+			return
+		}
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("RevealContact failed revealing contact")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, newContactPayload(contact))
+}
+
+type advertLogResponse struct {
+	Trigger   advert.AdvertLogTrigger `json:"trigger"`
+	UserID    string                  `json:"user_id"`
+	Meta      json.RawMessage         `json:"meta,omitempty"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+func (a AdvertAPI) AdvertHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to get advert history")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to get advert history")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("AdvertHistory failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	history, err := a.app.Queries.GetAdvertHistory.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("AdvertHistory failed getting advert history")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if !history.CanBeViewedBy(usr) {
+		log.Info("user tries to get history of not owned advert")
+		WriteError(w, http.StatusForbidden, "advert.not_owner", "not advert owner")
+		return
+	}
+
+	response := []advertLogResponse{}
+	for _, advLog := range history.Logs {
+		response = append(response, advertLogResponse{
+			Trigger:   advLog.Trigger,
+			UserID:    advLog.UserID.String(),
+			Meta:      advLog.Meta,
+			CreatedAt: advLog.CreatedAt,
+		})
+	}
+
+	WriteJSON(w, 200, response)
+}
+
+type authorResponse struct {
+	ID        string `json:"id"`
+	FirstName string `json:"firstname"`
+	Surname   string `json:"surname"`
+}
+
 type advertResponse struct {
 	ID             string             `json:"id"`
+	Author         *authorResponse    `json:"author,omitempty"`
 	Title          MultilingualString `json:"title"`
 	Description    MultilingualString `json:"description"`
 	Type           domain.AdvertType  `json:"type"`
-	ContactDetails contactPayload     `json:"contact_details"`
+	Views          int                `json:"views"`
+	ContactDetails *contactPayload    `json:"contact_details,omitempty"`
+	Location       *locationPayload   `json:"location,omitempty"`
+	Attributes     interface{}        `json:"attributes,omitempty"`
 	CreatedAt      time.Time          `json:"created_at"`
 	UpdatedAt      *time.Time         `json:"updated_at,omitempty"`
 	DestroyedAt    *time.Time         `json:"destroyed_at,omitempty"`
+	ExpiresAt      time.Time          `json:"expires_at"`
+	ArchivedAt     *time.Time         `json:"archived_at,omitempty"`
+	Status         advert.Status      `json:"status"`
+	// ModerationReason is shown to the owner of rejected or suspended advert
+	ModerationReason string          `json:"moderation_reason,omitempty"`
+	Images           []imageResponse `json:"images"`
+	// IsFavourite is present only when the request comes from the logged in user
+	IsFavourite *bool `json:"is_favourite,omitempty"`
+	// ContactProtected tells the contact details were omitted and have to be revealed by POST /api/adverts/{id}/contact
+	ContactProtected bool `json:"contact_protected,omitempty"`
+	// ContactReveals is shown only to the owner of the advert
+	ContactReveals *int `json:"contact_reveals,omitempty"`
+	// MachineTranslated lists languages of the title and description translated by machine, so clients can label them
+	MachineTranslated LanguageTags `json:"machine_translated,omitempty"`
+	// ServedLanguages tells which language each field was served in, it's present only when the languages were negotiated by Accept-Language header
+	ServedLanguages map[string]LanguageTag `json:"served_languages,omitempty"`
 }
 
 func (a *advertResponse) LoadAdvert(adv *advert.Advert) {
@@ -127,57 +884,330 @@ func (a *advertResponse) LoadAdvert(adv *advert.Advert) {
 	a.Title = adv.Details.Title
 	a.Description = adv.Details.Description
 	a.Type = adv.Details.Type
+	a.Views = adv.Details.Views
+	a.Attributes = attributesResponse(adv.Details)
 	a.CreatedAt = adv.CreatedAt
 	a.UpdatedAt = adv.UpdatedAt
 	a.DestroyedAt = adv.DestroyedAt
-	if adv.Details.ContactDetails.Mail != nil {
-		a.ContactDetails.Mail = *adv.Details.ContactDetails.Mail
+	a.ExpiresAt = adv.ExpiresAt
+	a.ArchivedAt = adv.ArchivedAt
+	a.Status = adv.Status
+	a.ModerationReason = adv.ModerationReason
+	for _, lang := range adv.Details.MachineTranslated {
+		if _, ok := adv.Details.Title[lang]; ok {
+			a.MachineTranslated = append(a.MachineTranslated, lang)
+		}
+	}
+	a.Images = []imageResponse{}
+	for _, img := range adv.Images {
+		imgResponse := imageResponse{}
+		imgResponse.LoadImage(img)
+		a.Images = append(a.Images, imgResponse)
 	}
 
-	if adv.Details.ContactDetails.PhoneNumber != nil {
-		a.ContactDetails.PhoneNumber = *adv.Details.ContactDetails.PhoneNumber
+	contact := newContactPayload(adv.Details.ContactDetails)
+	a.ContactDetails = &contact
+
+	if location := adv.Details.Location; location != nil {
+		a.Location = &locationPayload{City: location.City, Region: location.Region, Country: location.Country}
+		if location.Coordinates != nil {
+			a.Location.Latitude = &location.Coordinates.Latitude
+			a.Location.Longitude = &location.Coordinates.Longitude
+		}
 	}
 }
 
-const MaxAdvertsInResponse = 50
+// LoadNegotiated keeps only the best available translation of the title and description, the first language of the chain wins
+func (a *advertResponse) LoadNegotiated(chain LanguageTags) {
+	a.ServedLanguages = map[string]LanguageTag{}
+	fields := map[string]*MultilingualString{"title": &a.Title, "description": &a.Description}
+	for field, text := range fields {
+		lang, ok := text.Best(chain)
+		if !ok {
+			continue
+		}
+		*text = MultilingualString{lang: (*text)[lang]}
+		a.ServedLanguages[field] = lang
+	}
 
-func (a AdvertAPI) AdvertsList(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	var machineTranslated LanguageTags
+	for _, lang := range a.MachineTranslated {
+		if a.ServedLanguages["title"] == lang || a.ServedLanguages["description"] == lang {
+			machineTranslated = append(machineTranslated, lang)
+		}
+	}
+	a.MachineTranslated = machineTranslated
+}
 
-	limit, err := strconv.Atoi(r.FormValue("limit"))
+func newContactPayload(contactDetails domain.ContactDetails) contactPayload {
+	contact := contactPayload{}
+	if contactDetails.Mail != nil {
+		contact.Mail = *contactDetails.Mail
+	}
+	if contactDetails.PhoneNumber != nil {
+		contact.PhoneNumber = *contactDetails.PhoneNumber
+	}
+	return contact
+}
+
+// LoadContact omits the contact details the user isn't allowed to see right away, the owner gets the number of reveals
+func (a *advertResponse) LoadContact(adv *advert.Advert, usr *user.User, policy advert.ContactPolicy) {
+	if adv.IsOwnedBy(usr) {
+		reveals := adv.Details.ContactReveals
+		a.ContactReveals = &reveals
+	}
+	if !policy.ContactVisibleTo(*adv, usr) {
+		a.ContactDetails = nil
+		a.ContactProtected = true
+	}
+}
+
+func (a *advertResponse) LoadFavourite(isFavourite bool) {
+	a.IsFavourite = &isFavourite
+}
+
+// LoadAuthor fills only the public part of the user, so no credentials or private contact data leak to the response
+func (a *advertResponse) LoadAuthor(usr *user.User) {
+	if usr == nil {
+		return
+	}
+	a.Author = &authorResponse{
+		ID:        usr.ID.String(),
+		FirstName: usr.Person.FirstName,
+		Surname:   usr.Person.Surname,
+	}
+}
+
+const (
+	MaxAdvertsInResponse = 50
+	MaxSearchQueryLength = 100
+	// NextCursorHeader holds the cursor which should be passed as ?cursor= to get the next page of adverts
+	NextCursorHeader = "X-Next-Cursor"
+)
+
+func isInvalidFilter(err error) bool {
+	return errors.Is(err, advert.InvalidAdvertTypeErr) ||
+		errors.Is(err, advert.InvalidStatusErr) ||
+		errors.Is(err, advert.InvalidDateRangeErr) ||
+		errors.Is(err, advert.CursorWithQueryErr) ||
+		errors.Is(err, advert.CursorWithOffsetErr) ||
+		errors.Is(err, advert.InvalidRadiusErr) ||
+		errors.Is(err, domain.InvalidCoordinatesErr)
+}
+
+// invalidFilterErr carries the code and the details shown to the client when the list query parameters can't be parsed
+type invalidFilterErr struct {
+	code    string
+	details string
+}
+
+func (e invalidFilterErr) Error() string {
+	return e.details
+}
+
+func parseTimeParam(r *http.Request, name string) (*time.Time, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
 	if err != nil {
+		return nil, invalidFilterErr{code: "list.invalid_" + name, details: fmt.Sprintf("invalid %s, RFC 3339 date expected", name)}
+	}
+	return &parsed, nil
+}
+
+// parseListFilter loads the filter from url params, e.g. ?langs=uk,pl&types=job,transport&user_id=...&created_after=2022-03-01T00:00:00Z&status=pending
+func parseListFilter(r *http.Request) (advert.ListFilter, error) {
+	// negative values would fail in the database and bigger limits would bypass MaxAdvertsInResponse
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil || limit <= 0 || limit > MaxAdvertsInResponse {
 		limit = MaxAdvertsInResponse
 	}
 
 	offset, err := strconv.Atoi(r.FormValue("offset"))
-	if err != nil {
+	if err != nil || offset < 0 {
 		offset = 0
 	}
 
-	// Will load languages from url param &langs=ua,pl,en into slice
-	langs := LanguageTags{}.FromStrings(strings.Split(r.FormValue("langs"), ","))
+	filter := advert.ListFilter{
+		Query:  strings.TrimSpace(r.FormValue("q")),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if langs := r.FormValue("langs"); langs != "" {
+		// Will load languages from url param &langs=uk,pl,en into slice
+		filter.Languages = LanguageTags{}.FromStrings(strings.Split(langs, ","))
+	}
+
+	if len([]rune(filter.Query)) > MaxSearchQueryLength {
+		return advert.ListFilter{}, invalidFilterErr{code: "search.query_too_long", details: "search query too long"}
+	}
+
+	if types := r.FormValue("types"); types != "" {
+		for _, advertType := range strings.Split(types, ",") {
+			filter.Types = append(filter.Types, domain.AdvertType(strings.TrimSpace(advertType)))
+		}
+	}
+
+	if userID := r.FormValue("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return advert.ListFilter{}, invalidFilterErr{code: "list.invalid_user_id", details: "invalid user_id"}
+		}
+		filter.UserID = &id
+	}
+
+	filter.CreatedAfter, err = parseTimeParam(r, "created_after")
+	if err != nil {
+		return advert.ListFilter{}, err
+	}
+
+	filter.CreatedBefore, err = parseTimeParam(r, "created_before")
+	if err != nil {
+		return advert.ListFilter{}, err
+	}
+
+	filter.City = strings.TrimSpace(r.FormValue("city"))
+	filter.Region = strings.TrimSpace(r.FormValue("region"))
+
+	// radius search: &lat=50.45&lon=30.52&radius=20 (km)
+	if r.FormValue("lat") != "" || r.FormValue("lon") != "" || r.FormValue("radius") != "" {
+		latitude, latErr := strconv.ParseFloat(r.FormValue("lat"), 64)
+		longitude, lonErr := strconv.ParseFloat(r.FormValue("lon"), 64)
+		radius, radiusErr := strconv.ParseFloat(r.FormValue("radius"), 64)
+		if latErr != nil || lonErr != nil || radiusErr != nil {
+			return advert.ListFilter{}, invalidFilterErr{code: "list.radius_search_params", details: "lat, lon and radius are required for radius search"}
+		}
+		filter.Near = &domain.Coordinates{Latitude: latitude, Longitude: longitude}
+		filter.RadiusKm = radius
+	}
+
+	if cursor := r.FormValue("cursor"); cursor != "" {
+		after, err := advert.ParseCursor(cursor)
+		if err != nil {
+			return advert.ListFilter{}, invalidFilterErr{code: "list.invalid_cursor", details: "invalid cursor"}
+		}
+		filter.After = &after
+	}
+
+	if statuses := r.FormValue("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			filter.Statuses = append(filter.Statuses, advert.Status(strings.TrimSpace(status)))
+		}
+	}
+
+	if includeDestroyed := r.FormValue("include_destroyed"); includeDestroyed != "" {
+		filter.IncludeDestroyed, err = strconv.ParseBool(includeDestroyed)
+		if err != nil {
+			return advert.ListFilter{}, invalidFilterErr{code: "list.invalid_include_destroyed", details: "invalid include_destroyed"}
+		}
+	}
+
+	if includeExpired := r.FormValue("include_expired"); includeExpired != "" {
+		filter.IncludeExpired, err = strconv.ParseBool(includeExpired)
+		if err != nil {
+			return advert.ListFilter{}, invalidFilterErr{code: "list.invalid_include_expired", details: "invalid include_expired"}
+		}
+	}
+
+	return filter, nil
+}
+
+// fallbackLanguages is the configured order of languages served when none of the accepted ones is available
+func fallbackLanguages(cfg *common.Config) LanguageTags {
+	fallback := LanguageTags{}.FromStrings(cfg.Languages.Fallback).Supported()
+	if fallback.Empty() {
+		return DefaultFallback()
+	}
+	return fallback
+}
+
+// negotiateLanguages returns the order in which languages of the adverts are served according to Accept-Language header,
+// nil means all translations are served, either because the header is missing or the languages were selected by langs param
+func (a AdvertAPI) negotiateLanguages(r *http.Request) LanguageTags {
+	accepted := r.Header.Get("Accept-Language")
+	if accepted == "" || r.FormValue("langs") != "" {
+		return nil
+	}
+	return Negotiate(ParseAcceptLanguage(accepted), fallbackLanguages(a.cfg))
+}
+
+func (a AdvertAPI) AdvertsList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Add("Vary", "Accept-Language")
+
+	filter, err := parseListFilter(r)
+	if err != nil {
+		WriteDomainError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
 
 	log := a.log.WithFields(logrus.Fields{
-		"limit":  limit,
-		"offset": offset,
+		"limit":             filter.Limit,
+		"offset":            filter.Offset,
+		"query":             filter.Query,
+		"types":             filter.Types,
+		"include_destroyed": filter.IncludeDestroyed,
+		"include_expired":   filter.IncludeExpired,
+		"statuses":          filter.Statuses,
 	})
 
-	adverts, err := a.app.Queries.GetAdvertsList.Execute(ctx, langs, limit, offset)
+	usr, err := sessionUser(r, a.app)
 	if err != nil {
+		log.WithError(err).Error("AdvertsList failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if filter.IncludeDestroyed || filter.IncludeExpired || !filter.OnlyPublished() || filter.UserID != nil {
+		// owners see their adverts waiting for the review or rejected ones, unless they asked for specific statuses
+		if usr != nil && filter.UserID != nil && *filter.UserID == usr.ID && len(filter.Statuses) == 0 {
+			filter.Statuses = advert.Statuses()
+		}
+
+		if !filter.CanBeUsedBy(usr) {
+			log.Info("user tries to list hidden adverts of someone else")
+			WriteError(w, http.StatusForbidden, "advert.hidden", "destroyed, expired and not published adverts are visible only to their owner")
+			return
+		}
+	}
+
+	adverts, err := a.app.Queries.GetAdvertsList.Execute(ctx, filter)
+	if err != nil {
+		if isInvalidFilter(err) {
+			WriteDomainError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
 		log.WithError(err).Error("AdvertsList failed while fetching list of adverts")
-		WriteError(w, http.StatusInternalServerError, "")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
+	// full page means there may be more adverts, search results are ordered by relevance so they can't be continued by cursor
+	if filter.Query == "" && len(adverts) > 0 && len(adverts) == filter.Limit {
+		w.Header().Set(NextCursorHeader, advert.CursorOf(adverts[len(adverts)-1]).String())
+	}
+
 	if len(adverts) == 0 {
 		WriteJSON(w, 200, []advertResponse{})
 		return
 	}
 
+	favourites := favouritesOf(ctx, a.app, log, usr, adverts...)
+	chain := a.negotiateLanguages(r)
 	var response []advertResponse
 	for _, adv := range adverts {
 		advResponse := advertResponse{}
 		advResponse.LoadAdvert(adv)
+		advResponse.LoadContact(adv, usr, a.app.Commands.RevealContact.Policy)
+		if chain != nil {
+			advResponse.LoadNegotiated(chain)
+		}
+		if favourites != nil {
+			advResponse.LoadFavourite(favourites[adv.ID])
+		}
 		response = append(response, advResponse)
 	}
 
@@ -1,17 +1,31 @@
 package api
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/app/board"
 	"github.com/ukrainian-brothers/board-backend/domain"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
+	user_domain "github.com/ukrainian-brothers/board-backend/domain/user"
 	"github.com/ukrainian-brothers/board-backend/internal"
 	"github.com/ukrainian-brothers/board-backend/internal/advert"
 	"github.com/ukrainian-brothers/board-backend/internal/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/clientip"
 	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
 	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestAddAdvertE2E(t *testing.T) {
@@ -88,6 +102,46 @@ func TestAddAdvertE2E(t *testing.T) {
 				assert.NoError(t, err)
 			},
 		},
+		{
+			name:     "invalid location",
+			loggedIn: true,
+			payload: newAdvertPayload{
+				Title:       MultilingualString{English: "x"},
+				Description: MultilingualString{English: "x"},
+				Type:        domain.AdvertTypeTransport,
+				ContactDetails: contactPayload{
+					Mail: *test_helpers.RandomMail(),
+				},
+				Location: &locationPayload{City: "Lviv", Latitude: newFloatPtr(49.84)},
+			},
+			expected: expected{
+				status: http.StatusUnprocessableEntity,
+				errorStruct: errorStruct{
+					Error:   "Unprocessable Entity",
+					Details: "invalid location",
+				},
+			},
+		},
+		{
+			name:     "success with location",
+			loggedIn: true,
+			payload: newAdvertPayload{
+				Title:       MultilingualString{English: "x"},
+				Description: MultilingualString{English: "x"},
+				Type:        domain.AdvertTypePlaceToStay,
+				ContactDetails: contactPayload{
+					Mail: *test_helpers.RandomMail(),
+				},
+				Location: &locationPayload{City: "Lviv", Country: "Ukraine", Latitude: newFloatPtr(49.84), Longitude: newFloatPtr(24.03)},
+			},
+			expected: expected{
+				status: http.StatusCreated,
+			},
+			cleanUp: func(t *testing.T, userID uuid.UUID) {
+				_, err := db.Exec("DELETE FROM adverts WHERE user_id=$1", userID.String())
+				assert.NoError(t, err)
+			},
+		},
 	}
 
 	for _, tC := range testCases {
@@ -116,6 +170,96 @@ func TestAddAdvert(t *testing.T) {
 	// TODO: In another branch add tests for handling errors from DB
 }
 
+func TestAdvertValidationErrors(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	contactDetails := user.GetValidContactDetails()
+	author := &user_domain.User{ID: uuid.New(), Login: "author", Role: user_domain.RoleUser, ContactDetails: contactDetails}
+	userRepo.On("GetByLogin", mock.Anything, author.Login).Return(author, nil)
+	advertRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+	type testCase struct {
+		name           string
+		payload        map[string]interface{}
+		expectedStatus int
+		expectedFields []string
+	}
+
+	testCases := []testCase{
+		{
+			name: "rejected languages",
+			payload: map[string]interface{}{
+				"title":           map[string]string{"en": "Room", "xx": "Room"},
+				"description":     map[string]string{"en": strings.Repeat("x", advert_domain.MaxDescriptionLength+1), "pl": " "},
+				"type":            domain.AdvertTypePlaceToStay,
+				"contact_details": contactPayload{Mail: *contactDetails.Mail},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"title.xx unsupported_language", "description.en too_long", "description.pl required"},
+		},
+		{
+			name: "no common language",
+			payload: map[string]interface{}{
+				"title":           map[string]string{"en": "Room"},
+				"description":     map[string]string{"pl": "Pokój"},
+				"type":            domain.AdvertTypePlaceToStay,
+				"contact_details": contactPayload{Mail: *contactDetails.Mail},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"description no_common_language"},
+		},
+		{
+			name: "invalid contact details",
+			payload: map[string]interface{}{
+				"title":           map[string]string{"en": "Room"},
+				"description":     map[string]string{"en": "Free room"},
+				"type":            domain.AdvertTypePlaceToStay,
+				"contact_details": contactPayload{Mail: "adam", PhoneNumber: "+48 111 222 333 444"},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"contact_details.mail invalid_format", "contact_details.phone invalid_format"},
+		},
+		{
+			name: "unknown type",
+			payload: map[string]interface{}{
+				"title":           map[string]string{"en": "Boat"},
+				"description":     map[string]string{"en": "Free boat"},
+				"type":            "boat",
+				"contact_details": contactPayload{Mail: *contactDetails.Mail},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"type invalid"},
+		},
+		{
+			name: "trimmed legacy tags",
+			payload: map[string]interface{}{
+				"title":           map[string]string{"ua": " Кімната ", "pl-PL": "Pokój"},
+				"description":     map[string]string{"ua": "Вільна кімната", "pl-PL": "Wolny pokój"},
+				"type":            domain.AdvertTypePlaceToStay,
+				"contact_details": contactPayload{Mail: *contactDetails.Mail},
+			},
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			response := struct {
+				errorStruct
+				Title MultilingualString `json:"title"`
+			}{}
+			resp := doRequest(t, client, "POST", server.URL+"/api/adverts", tC.payload, &response, user.CreateTestSession(t, author, sessionStore))
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tC.expectedFields, fieldCodes(response.Fields))
+			if tC.expectedStatus == http.StatusCreated {
+				assert.Equal(t, MultilingualString{Ukrainian: "Кімната", Polish: "Pokój"}, response.Title)
+			}
+		})
+	}
+	advertRepo.AssertNumberOfCalls(t, "Add", 1)
+}
+
 func TestAdvertsListE2E(t *testing.T) {
 	userRepo, advertRepo, db := getPostgresRepos(t)
 	server, client, _ := createTestAPIs(t, advertRepo, userRepo)
@@ -223,3 +367,1277 @@ func TestAdvertsListE2E(t *testing.T) {
 	}
 	// TODO: More test cases for testing limit, offset,
 }
+
+func TestUpdateAdvert(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", ContactDetails: user.GetValidContactDetails()}
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger", ContactDetails: user.GetValidContactDetails()}
+	userRepo.On("GetByLogin", mock.Anything, owner.Login).Return(owner, nil)
+	userRepo.On("GetByLogin", mock.Anything, stranger.Login).Return(stranger, nil)
+
+	existingAdvertID := uuid.New()
+	changedAdvertID := uuid.New()
+	publishedAdvertID := uuid.New()
+	for _, id := range []uuid.UUID{existingAdvertID, changedAdvertID, publishedAdvertID} {
+		status := advert_domain.StatusPending
+		if id == publishedAdvertID {
+			status = advert_domain.StatusPublished
+		}
+		advertRepo.On("Get", mock.Anything, id).Return(advert_domain.Advert{
+			ID: id,
+			Details: domain.AdvertDetails{
+				Title:          MultilingualString{English: "x", Polish: "x"},
+				Description:    MultilingualString{English: "x", Polish: "x"},
+				Type:           domain.AdvertTypeTransport,
+				ContactDetails: user.GetValidContactDetails(),
+			},
+			User:   owner,
+			Status: status,
+		}, nil)
+	}
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("Update", mock.Anything, mock.MatchedBy(func(adv *advert_domain.Advert) bool {
+		return adv.ID == changedAdvertID
+	})).Return(advert_domain.AdvertChangedErr)
+	advertRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	type expected struct {
+		status       int
+		errorStruct  errorStruct
+		title        MultilingualString
+		advertStatus advert_domain.Status
+	}
+	type testCase struct {
+		name     string
+		method   string
+		user     *user_domain.User
+		advertID uuid.UUID
+		payload  updateAdvertPayload
+		expected expected
+	}
+
+	testCases := []testCase{
+		{
+			name:     "not authorised",
+			method:   "PATCH",
+			advertID: existingAdvertID,
+			expected: expected{
+				status:      http.StatusForbidden,
+				errorStruct: errorStruct{Error: "Forbidden", Details: "not authorized"},
+			},
+		},
+		{
+			name:     "not advert owner",
+			method:   "PATCH",
+			user:     stranger,
+			advertID: existingAdvertID,
+			payload:  updateAdvertPayload{Title: MultilingualString{English: "y"}},
+			expected: expected{
+				status:      http.StatusForbidden,
+				errorStruct: errorStruct{Error: "Forbidden", Details: "not advert owner"},
+			},
+		},
+		{
+			name:     "advert not found",
+			method:   "PATCH",
+			user:     owner,
+			advertID: uuid.New(),
+			expected: expected{
+				status:      http.StatusNotFound,
+				errorStruct: errorStruct{Error: "Not Found", Details: "advert not found"},
+			},
+		},
+		{
+			name:     "incomplete PUT payload",
+			method:   "PUT",
+			user:     owner,
+			advertID: existingAdvertID,
+			payload:  updateAdvertPayload{Title: MultilingualString{English: "y"}},
+			expected: expected{
+				status:      http.StatusUnprocessableEntity,
+				errorStruct: errorStruct{Error: "Unprocessable Entity", Details: "invalid payload"},
+			},
+		},
+		{
+			name:     "unknown type",
+			method:   "PATCH",
+			user:     owner,
+			advertID: existingAdvertID,
+			payload:  updateAdvertPayload{Type: "boat"},
+			expected: expected{
+				status:      http.StatusUnprocessableEntity,
+				errorStruct: errorStruct{Error: "Unprocessable Entity", Details: "unknown advert type"},
+			},
+		},
+		{
+			name:     "advert changed in the meantime",
+			method:   "PATCH",
+			user:     owner,
+			advertID: changedAdvertID,
+			payload:  updateAdvertPayload{Title: MultilingualString{English: "y"}},
+			expected: expected{
+				status:      http.StatusConflict,
+				errorStruct: errorStruct{Error: "Conflict", Details: "advert has been changed in the meantime, load it again"},
+			},
+		},
+		{
+			name:     "PATCH keeps languages which weren't sent",
+			method:   "PATCH",
+			user:     owner,
+			advertID: existingAdvertID,
+			payload:  updateAdvertPayload{Title: MultilingualString{English: "y"}},
+			expected: expected{
+				status: http.StatusOK,
+				title:  MultilingualString{English: "y", Polish: "x"},
+			},
+		},
+		{
+			name:     "PUT replaces all languages",
+			method:   "PUT",
+			user:     owner,
+			advertID: existingAdvertID,
+			payload: updateAdvertPayload{
+				Title:          MultilingualString{English: "y"},
+				Description:    MultilingualString{English: "y"},
+				Type:           domain.AdvertTypeTransport,
+				ContactDetails: &contactPayload{Mail: "mail@example.com"},
+			},
+			expected: expected{
+				status: http.StatusOK,
+				title:  MultilingualString{English: "y"},
+			},
+		},
+		{
+			name:     "edited published advert waits for the review",
+			method:   "PATCH",
+			user:     owner,
+			advertID: publishedAdvertID,
+			payload:  updateAdvertPayload{Title: MultilingualString{English: "y"}},
+			expected: expected{
+				status:       http.StatusOK,
+				title:        MultilingualString{English: "y", Polish: "x"},
+				advertStatus: advert_domain.StatusPending,
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			response := struct {
+				errorStruct
+				Title  MultilingualString   `json:"title"`
+				Status advert_domain.Status `json:"status"`
+			}{}
+			resp := doRequest(t, client, tC.method, fmt.Sprintf("%s/api/adverts/%s", server.URL, tC.advertID), tC.payload, &response, cookies)
+			assert.Equal(t, tC.expected.status, resp.StatusCode)
+			assert.Equal(t, tC.expected.errorStruct.Error, response.Error)
+			assert.Equal(t, tC.expected.errorStruct.Details, response.Details)
+			if tC.expected.title != nil {
+				assert.Equal(t, tC.expected.title, response.Title)
+			}
+			if tC.expected.advertStatus != "" {
+				assert.Equal(t, tC.expected.advertStatus, response.Status)
+			}
+		})
+	}
+}
+
+func TestDeleteAdvert(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", ContactDetails: user.GetValidContactDetails()}
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger", ContactDetails: user.GetValidContactDetails()}
+	userRepo.On("GetByLogin", mock.Anything, owner.Login).Return(owner, nil)
+	userRepo.On("GetByLogin", mock.Anything, stranger.Login).Return(stranger, nil)
+
+	existingAdvertID := uuid.New()
+	advertRepo.On("Get", mock.Anything, existingAdvertID).Return(advert_domain.Advert{ID: existingAdvertID, User: owner}, nil)
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	type expected struct {
+		status      int
+		errorStruct errorStruct
+	}
+	type testCase struct {
+		name     string
+		user     *user_domain.User
+		advertID uuid.UUID
+		expected expected
+	}
+
+	testCases := []testCase{
+		{
+			name:     "not authorised",
+			advertID: existingAdvertID,
+			expected: expected{
+				status:      http.StatusForbidden,
+				errorStruct: errorStruct{Error: "Forbidden", Details: "not authorized"},
+			},
+		},
+		{
+			name:     "not advert owner",
+			user:     stranger,
+			advertID: existingAdvertID,
+			expected: expected{
+				status:      http.StatusForbidden,
+				errorStruct: errorStruct{Error: "Forbidden", Details: "not advert owner"},
+			},
+		},
+		{
+			name:     "advert not found",
+			user:     owner,
+			advertID: uuid.New(),
+			expected: expected{
+				status:      http.StatusNotFound,
+				errorStruct: errorStruct{Error: "Not Found", Details: "advert not found"},
+			},
+		},
+		{
+			name:     "success",
+			user:     owner,
+			advertID: existingAdvertID,
+			expected: expected{
+				status: http.StatusOK,
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			errResponse := errorStruct{}
+			resp := doRequest(t, client, "DELETE", fmt.Sprintf("%s/api/adverts/%s", server.URL, tC.advertID), nil, &errResponse, cookies)
+			assert.Equal(t, tC.expected.status, resp.StatusCode)
+			assert.Equal(t, tC.expected.errorStruct.Error, errResponse.Error)
+			assert.Equal(t, tC.expected.errorStruct.Details, errResponse.Details)
+		})
+	}
+}
+
+func TestGetAdvert(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	author := &user_domain.User{
+		ID:             uuid.New(),
+		Login:          "author",
+		Password:       newStringPtr("secret_hash"),
+		Person:         domain.Person{FirstName: "Mac", Surname: "Cheese"},
+		ContactDetails: user.GetValidContactDetails(),
+	}
+	existingAdvertID := uuid.New()
+	advertRepo.On("Get", mock.Anything, existingAdvertID).Return(advert_domain.Advert{
+		ID: existingAdvertID,
+		Details: domain.AdvertDetails{
+			Title:          MultilingualString{English: "title", Polish: "tytuł"},
+			Description:    MultilingualString{English: "description", Polish: "opis"},
+			Type:           domain.AdvertTypeTransport,
+			ContactDetails: user.GetValidContactDetails(),
+		},
+		User:   author,
+		Status: advert_domain.StatusPublished,
+	}, nil)
+	pendingAdvertID := uuid.New()
+	advertRepo.On("Get", mock.Anything, pendingAdvertID).Return(advert_domain.Advert{
+		ID: pendingAdvertID,
+		Details: domain.AdvertDetails{
+			Title:          MultilingualString{English: "pending"},
+			Description:    MultilingualString{English: "description"},
+			Type:           domain.AdvertTypeTransport,
+			ContactDetails: user.GetValidContactDetails(),
+		},
+		User:   author,
+		Status: advert_domain.StatusPending,
+	}, nil)
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("IncrementViews", mock.Anything, existingAdvertID).Return(nil)
+
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{author, stranger} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	type expected struct {
+		status int
+		title  MultilingualString
+	}
+	type testCase struct {
+		name     string
+		url      string
+		user     *user_domain.User
+		expected expected
+	}
+
+	testCases := []testCase{
+		{
+			name: "success",
+			url:  fmt.Sprintf("%s/api/adverts/%s", server.URL, existingAdvertID),
+			expected: expected{
+				status: http.StatusOK,
+				title:  MultilingualString{English: "title", Polish: "tytuł"},
+			},
+		},
+		{
+			name: "filtered languages",
+			url:  fmt.Sprintf("%s/api/adverts/%s?langs=pl,ua", server.URL, existingAdvertID),
+			expected: expected{
+				status: http.StatusOK,
+				title:  MultilingualString{Polish: "tytuł"},
+			},
+		},
+		{
+			name: "no requested language",
+			url:  fmt.Sprintf("%s/api/adverts/%s?langs=ua", server.URL, existingAdvertID),
+			expected: expected{
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "advert not found",
+			url:  fmt.Sprintf("%s/api/adverts/%s", server.URL, uuid.New()),
+			expected: expected{
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "invalid id",
+			url:  fmt.Sprintf("%s/api/adverts/not_an_uuid", server.URL),
+			expected: expected{
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "pending advert hidden from anonymous",
+			url:  fmt.Sprintf("%s/api/adverts/%s", server.URL, pendingAdvertID),
+			expected: expected{
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "pending advert hidden from stranger",
+			url:  fmt.Sprintf("%s/api/adverts/%s", server.URL, pendingAdvertID),
+			user: stranger,
+			expected: expected{
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "pending advert visible to owner",
+			url:  fmt.Sprintf("%s/api/adverts/%s", server.URL, pendingAdvertID),
+			user: author,
+			expected: expected{
+				status: http.StatusOK,
+				title:  MultilingualString{English: "pending"},
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			response := map[string]interface{}{}
+			resp := doRequest(t, client, "GET", tC.url, nil, &response, cookies)
+			assert.Equal(t, tC.expected.status, resp.StatusCode)
+			if tC.expected.status != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, map[string]interface{}{
+				"id":        author.ID.String(),
+				"firstname": author.Person.FirstName,
+				"surname":   author.Person.Surname,
+			}, response["author"])
+
+			title := MultilingualString{}
+			for lang, value := range response["title"].(map[string]interface{}) {
+				title[LanguageTag(lang)] = value.(string)
+			}
+			assert.Equal(t, tC.expected.title, title)
+		})
+	}
+}
+
+func TestAdvertLanguageNegotiation(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, _ := createTestAPIs(t, &advertRepo, &userRepo)
+
+	bilingual := advert_domain.Advert{
+		ID: uuid.New(),
+		Details: domain.AdvertDetails{
+			Title:             MultilingualString{English: "title", Polish: "tytuł"},
+			Description:       MultilingualString{English: "description", Polish: "opis"},
+			Type:              domain.AdvertTypeTransport,
+			MachineTranslated: LanguageTags{Polish},
+		},
+		Status: advert_domain.StatusPublished,
+	}
+	english := advert_domain.Advert{
+		ID: uuid.New(),
+		Details: domain.AdvertDetails{
+			Title:       MultilingualString{English: "only english"},
+			Description: MultilingualString{English: "description"},
+			Type:        domain.AdvertTypeTransport,
+		},
+		Status: advert_domain.StatusPublished,
+	}
+	advertRepo.On("Get", mock.Anything, bilingual.ID).Return(bilingual, nil)
+	advertRepo.On("IncrementViews", mock.Anything, bilingual.ID).Return(nil)
+	advertRepo.On("GetList", mock.Anything, advert_domain.ListFilter{Limit: MaxAdvertsInResponse}).Return([]*advert_domain.Advert{&bilingual, &english}, nil)
+
+	get := func(t *testing.T, url string, acceptLanguage string, response interface{}) *http.Response {
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		responseToStruct(t, resp, response)
+		return resp
+	}
+
+	type testCase struct {
+		name              string
+		acceptLanguage    string
+		query             string
+		expectedTitle     MultilingualString
+		expectedServed    map[string]LanguageTag
+		machineTranslated LanguageTags
+	}
+
+	testCases := []testCase{
+		{
+			name:              "no header",
+			expectedTitle:     MultilingualString{English: "title", Polish: "tytuł"},
+			machineTranslated: LanguageTags{Polish},
+		},
+		{
+			name:           "preferred language",
+			acceptLanguage: "pl;q=0.5, en-GB;q=0.9",
+			expectedTitle:  MultilingualString{English: "title"},
+			expectedServed: map[string]LanguageTag{"title": English, "description": English},
+		},
+		{
+			name:              "fallback chain",
+			acceptLanguage:    "ua, de;q=0.8",
+			expectedTitle:     MultilingualString{Polish: "tytuł"},
+			expectedServed:    map[string]LanguageTag{"title": Polish, "description": Polish},
+			machineTranslated: LanguageTags{Polish},
+		},
+		{
+			name:           "langs param wins",
+			acceptLanguage: "pl",
+			query:          "?langs=en",
+			expectedTitle:  MultilingualString{English: "title"},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			response := advertResponse{}
+			resp := get(t, fmt.Sprintf("%s/api/adverts/%s%s", server.URL, bilingual.ID, tC.query), tC.acceptLanguage, &response)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "Accept-Language", resp.Header.Get("Vary"))
+			assert.Equal(t, tC.expectedTitle, response.Title)
+			assert.Equal(t, tC.expectedServed, response.ServedLanguages)
+			assert.Equal(t, tC.machineTranslated, response.MachineTranslated)
+		})
+	}
+
+	t.Run("list keeps adverts without accepted language", func(t *testing.T) {
+		var response []advertResponse
+		resp := get(t, server.URL+"/api/adverts", "ua,pl;q=0.9", &response)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, response, 2)
+		assert.Equal(t, MultilingualString{Polish: "tytuł"}, response[0].Title)
+		assert.Equal(t, MultilingualString{English: "only english"}, response[1].Title)
+		assert.Equal(t, map[string]LanguageTag{"title": English, "description": English}, response[1].ServedLanguages)
+	})
+}
+
+func TestAdvertHistory(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	logRepo := advert.LogRepositoryMock{}
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Queries.GetAdvertHistory = board.NewGetAdvertHistory(&logRepo)
+	server, client, sessionStore := createTestServer(t, app)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	admin := &user_domain.User{ID: uuid.New(), Login: "admin", Role: user_domain.RoleAdmin}
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{owner, admin, stranger} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	existingAdvertID := uuid.New()
+	logRepo.On("GetHistory", mock.Anything, existingAdvertID).Return(advert_domain.AdvertHistory{
+		AdvertID: existingAdvertID,
+		OwnerID:  owner.ID,
+		Logs: []advert_domain.AdvertLog{
+			{AdvertID: existingAdvertID, UserID: owner.ID, Trigger: advert_domain.AdvertCreatedEvent, Meta: []byte(`{"type":"job"}`)},
+			{AdvertID: existingAdvertID, UserID: owner.ID, Trigger: advert_domain.AdvertDeletedEvent},
+		},
+	}, nil)
+	logRepo.On("GetHistory", mock.Anything, mock.Anything).Return(advert_domain.AdvertHistory{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+
+	type testCase struct {
+		name           string
+		user           *user_domain.User
+		advertID       uuid.UUID
+		expectedStatus int
+	}
+
+	testCases := []testCase{
+		{name: "not authorised", advertID: existingAdvertID, expectedStatus: http.StatusForbidden},
+		{name: "not advert owner", user: stranger, advertID: existingAdvertID, expectedStatus: http.StatusForbidden},
+		{name: "advert not found", user: owner, advertID: uuid.New(), expectedStatus: http.StatusNotFound},
+		{name: "owner", user: owner, advertID: existingAdvertID, expectedStatus: http.StatusOK},
+		{name: "admin", user: admin, advertID: existingAdvertID, expectedStatus: http.StatusOK},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts/%s/history", server.URL, tC.advertID), nil, nil, cookies)
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			if tC.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var logs []advertLogResponse
+			responseToStruct(t, resp, &logs)
+			assert.Len(t, logs, 2)
+			assert.Equal(t, advert_domain.AdvertCreatedEvent, logs[0].Trigger)
+			assert.JSONEq(t, `{"type":"job"}`, string(logs[0].Meta))
+		})
+	}
+}
+
+func TestGetAdvertViews(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	advertID := uuid.New()
+	advertRepo.On("Get", mock.Anything, advertID).Return(advert_domain.Advert{
+		ID: advertID,
+		Details: domain.AdvertDetails{
+			Title:       MultilingualString{English: "title"},
+			Description: MultilingualString{English: "description"},
+			Views:       10,
+		},
+		User:   &user_domain.User{ID: uuid.New()},
+		Status: advert_domain.StatusPublished,
+	}, nil)
+	advertRepo.On("IncrementViews", mock.Anything, advertID).Return(nil)
+
+	url := fmt.Sprintf("%s/api/adverts/%s", server.URL, advertID)
+
+	response := advertResponse{}
+	doRequest(t, client, "GET", url, nil, &response, nil)
+	assert.Equal(t, 11, response.Views)
+
+	// the same anonymous viewer is counted once within the window
+	response = advertResponse{}
+	doRequest(t, client, "GET", url, nil, &response, nil)
+	assert.Equal(t, 10, response.Views)
+	advertRepo.AssertNumberOfCalls(t, "IncrementViews", 1)
+
+	// logged in user is identified by the session
+	viewer := &user_domain.User{ID: uuid.New(), Login: "viewer"}
+	userRepo.On("GetByLogin", mock.Anything, viewer.Login).Return(viewer, nil)
+	cookies := user.CreateTestSession(t, viewer, sessionStore)
+	response = advertResponse{}
+	doRequest(t, client, "GET", url, nil, &response, cookies)
+	assert.Equal(t, 11, response.Views)
+	advertRepo.AssertNumberOfCalls(t, "IncrementViews", 2)
+}
+
+func TestViewerKeyBehindProxy(t *testing.T) {
+	resolver, err := clientip.NewResolver([]string{"10.0.0.1"}, "")
+	require.NoError(t, err)
+
+	var key string
+	handler := MiddlewareProvider{}.ClientIPMiddleware(resolver)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = viewerKey(r)
+	}))
+	viewerKeyOf := func(remoteAddr string, forwardedFor string) string {
+		req := httptest.NewRequest("GET", "/api/adverts", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return key
+	}
+
+	// anonymous viewers behind the proxy are told apart, the header of anybody else is ignored
+	assert.Equal(t, "ip:198.51.100.1", viewerKeyOf("10.0.0.1:4000", "198.51.100.1"))
+	assert.Equal(t, "ip:198.51.100.2", viewerKeyOf("10.0.0.1:4000", "198.51.100.2"))
+	assert.Equal(t, "ip:203.0.113.7", viewerKeyOf("203.0.113.7:4000", "198.51.100.1"))
+}
+
+func TestRevealContact(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.RevealContact = board.NewRevealContact(&advertRepo, advert_domain.ContactPolicy{Protected: true}, 2, time.Hour)
+	server, client, sessionStore := createTestServer(t, app)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	seeker := &user_domain.User{ID: uuid.New(), Login: "seeker", Role: user_domain.RoleUser}
+	scraper := &user_domain.User{ID: uuid.New(), Login: "scraper", Role: user_domain.RoleUser}
+	neighbour := &user_domain.User{ID: uuid.New(), Login: "neighbour", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{owner, seeker, scraper, neighbour} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	contactDetails := user.GetValidContactDetails()
+	newAdvert := func(status advert_domain.Status, contact domain.ContactDetails) advert_domain.Advert {
+		return advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:          MultilingualString{English: "title"},
+				Description:    MultilingualString{English: "description"},
+				ContactDetails: contact,
+				ContactReveals: 3,
+			},
+			User:   owner,
+			Status: status,
+		}
+	}
+	published := newAdvert(advert_domain.StatusPublished, contactDetails)
+	second := newAdvert(advert_domain.StatusPublished, contactDetails)
+	pending := newAdvert(advert_domain.StatusPending, contactDetails)
+	noContact := newAdvert(advert_domain.StatusPublished, domain.ContactDetails{})
+	for _, adv := range []advert_domain.Advert{published, second, pending, noContact} {
+		advertRepo.On("Get", mock.Anything, adv.ID).Return(adv, nil)
+	}
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("IncrementViews", mock.Anything, mock.Anything).Return(nil)
+	advertRepo.On("IncrementContactReveals", mock.Anything, mock.Anything).Return(nil)
+	advertRepo.On("GetList", mock.Anything, mock.Anything).Return([]*advert_domain.Advert{&published}, nil)
+
+	t.Run("contacts omitted", func(t *testing.T) {
+		response := advertResponse{}
+		doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts/%s", server.URL, published.ID), nil, &response, user.CreateTestSession(t, seeker, sessionStore))
+		assert.Nil(t, response.ContactDetails)
+		assert.True(t, response.ContactProtected)
+		assert.Nil(t, response.ContactReveals)
+
+		var list []advertResponse
+		doRequest(t, client, "GET", server.URL+"/api/adverts", nil, &list, nil)
+		require.Len(t, list, 1)
+		assert.Nil(t, list[0].ContactDetails)
+		assert.True(t, list[0].ContactProtected)
+
+		// the owner sees own contact together with the counter
+		response = advertResponse{}
+		doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts/%s", server.URL, published.ID), nil, &response, user.CreateTestSession(t, owner, sessionStore))
+		require.NotNil(t, response.ContactDetails)
+		assert.Equal(t, *contactDetails.Mail, response.ContactDetails.Mail)
+		assert.False(t, response.ContactProtected)
+		require.NotNil(t, response.ContactReveals)
+		assert.Equal(t, 3, *response.ContactReveals)
+	})
+
+	t.Run("reveal", func(t *testing.T) {
+		type testCase struct {
+			name           string
+			user           *user_domain.User
+			advertID       uuid.UUID
+			expectedStatus int
+		}
+
+		testCases := []testCase{
+			{name: "not authorized", advertID: published.ID, expectedStatus: http.StatusForbidden},
+			{name: "advert not found", user: seeker, advertID: uuid.New(), expectedStatus: http.StatusNotFound},
+			{name: "advert not published", user: seeker, advertID: pending.ID, expectedStatus: http.StatusNotFound},
+			{name: "no contact", user: seeker, advertID: noContact.ID, expectedStatus: http.StatusNotFound},
+			{name: "success", user: seeker, advertID: published.ID, expectedStatus: http.StatusOK},
+			{name: "revealed again", user: seeker, advertID: published.ID, expectedStatus: http.StatusOK},
+			{name: "owner", user: owner, advertID: published.ID, expectedStatus: http.StatusOK},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.name, func(t *testing.T) {
+				var cookies []*http.Cookie
+				if tC.user != nil {
+					cookies = user.CreateTestSession(t, tC.user, sessionStore)
+				}
+
+				response := contactPayload{}
+				resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts/%s/contact", server.URL, tC.advertID), nil, &response, cookies)
+				assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+				if tC.expectedStatus != http.StatusOK {
+					return
+				}
+				assert.Equal(t, *contactDetails.Mail, response.Mail)
+			})
+		}
+		// repeated reveals by the same user and reveals by the owner are not counted
+		advertRepo.AssertNumberOfCalls(t, "IncrementContactReveals", 1)
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		cookies := user.CreateTestSession(t, scraper, sessionStore)
+		for _, adv := range []advert_domain.Advert{published, second} {
+			resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts/%s/contact", server.URL, adv.ID), nil, nil, cookies)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts/%s/contact", server.URL, published.ID), nil, nil, cookies)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+		// other users are limited separately
+		resp = doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts/%s/contact", server.URL, second.ID), nil, nil, user.CreateTestSession(t, neighbour, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestTranslateAdverts(t *testing.T) {
+	libreTranslate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		switch {
+		case request["target"] == "en":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "en is not available as a target language"}`))
+		case strings.HasPrefix(request["q"], "fail"):
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error": "model crashed"}`))
+		case request["q"] == "Pokój na długo":
+			_ = json.NewEncoder(w).Encode(map[string]string{"translatedText": strings.Repeat("Room for a long time ", 10)})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]string{"translatedText": fmt.Sprintf("[%s] %s", request["target"], request["q"])})
+		}
+	}))
+	defer libreTranslate.Close()
+
+	userRepo, advertRepo := getMockedRepo()
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.TranslateAdverts = board.NewTranslateAdverts(&advertRepo, NewLibreTranslate(libreTranslate.URL, "", libreTranslate.Client()), 10)
+	server, client, _ := createTestServer(t, app)
+
+	newAdvert := func(title string, description string) *advert_domain.Advert {
+		return &advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{Polish: title},
+				Description: MultilingualString{Polish: description},
+			},
+			User:   &user_domain.User{ID: uuid.New()},
+			Status: advert_domain.StatusPublished,
+		}
+	}
+	translated := newAdvert("Pokój", "Wolny pokój")
+	failing := newAdvert("fail", "Wolny pokój")
+	outdated := newAdvert("Mieszkanie", "Wolne mieszkanie")
+	tooLong := newAdvert("Pokój na długo", "Wolny pokój")
+
+	isAdvert := func(adv *advert_domain.Advert) interface{} {
+		return mock.MatchedBy(func(saved *advert_domain.Advert) bool { return saved.ID == adv.ID })
+	}
+	advertRepo.On("GetUntranslated", mock.Anything, 10).Return([]*advert_domain.Advert{translated, failing, outdated, tooLong}, nil)
+	advertRepo.On("SaveTranslations", mock.Anything, isAdvert(translated)).Return(nil)
+	advertRepo.On("SaveTranslations", mock.Anything, isAdvert(outdated)).Return(advert_domain.TranslationOutdatedErr)
+	advertRepo.On("SaveTranslations", mock.Anything, isAdvert(tooLong)).Return(nil)
+	advertRepo.On("FailTranslation", mock.Anything, failing.ID, advert_domain.MaxTranslationAttempts).Return(nil)
+
+	count, err := app.Commands.TranslateAdverts.Execute(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), failing.ID.String())
+	assert.Equal(t, 2, count)
+	advertRepo.AssertNotCalled(t, "SaveTranslations", mock.Anything, isAdvert(failing))
+	advertRepo.AssertNumberOfCalls(t, "FailTranslation", 1)
+
+	// translations longer than the source are shortened to fit the advert limits
+	assert.Equal(t, advert_domain.MaxTitleLength, utf8.RuneCountInString(tooLong.Details.Title[Ukrainian]))
+
+	// english isn't supported by the fake service, so it's left missing
+	assert.Equal(t, MultilingualString{Polish: "Pokój", Ukrainian: "[uk] Pokój"}, translated.Details.Title)
+	assert.Equal(t, MultilingualString{Polish: "Wolny pokój", Ukrainian: "[uk] Wolny pokój"}, translated.Details.Description)
+	assert.Equal(t, LanguageTags{Ukrainian}, translated.Details.MachineTranslated)
+	assert.NotNil(t, translated.TranslatedAt)
+	assert.Nil(t, failing.TranslatedAt)
+
+	advertRepo.On("Get", mock.Anything, translated.ID).Return(*translated, nil)
+	advertRepo.On("IncrementViews", mock.Anything, translated.ID).Return(nil)
+
+	response := advertResponse{}
+	resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts/%s", server.URL, translated.ID), nil, &response, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, LanguageTags{Ukrainian}, response.MachineTranslated)
+
+	response = advertResponse{}
+	doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts/%s?langs=pl", server.URL, translated.ID), nil, &response, nil)
+	assert.Nil(t, response.MachineTranslated)
+}
+
+// TestTranslateAdvertsFailing checks that adverts failing every time don't stop the translation of the other ones
+func TestTranslateAdvertsFailing(t *testing.T) {
+	libreTranslate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": "model crashed"}`))
+	}))
+	defer libreTranslate.Close()
+
+	_, advertRepo := getMockedRepo()
+	translateAdverts := board.NewTranslateAdverts(&advertRepo, NewLibreTranslate(libreTranslate.URL, "", libreTranslate.Client()), 3)
+
+	var batch []*advert_domain.Advert
+	for i := 0; i < 3; i++ {
+		batch = append(batch, &advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{Polish: "fail"},
+				Description: MultilingualString{Polish: "fail"},
+			},
+		})
+	}
+	advertRepo.On("GetUntranslated", mock.Anything, 3).Return(batch, nil)
+	advertRepo.On("FailTranslation", mock.Anything, mock.Anything, advert_domain.MaxTranslationAttempts).Return(nil)
+
+	count, err := translateAdverts.Execute(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, count)
+	// every failure is recorded, so the repository moves the adverts behind the other ones and gives up on them eventually
+	for _, adv := range batch {
+		advertRepo.AssertCalled(t, "FailTranslation", mock.Anything, adv.ID, advert_domain.MaxTranslationAttempts)
+	}
+	advertRepo.AssertNotCalled(t, "SaveTranslations", mock.Anything, mock.Anything)
+}
+
+func TestAdvertsListSearch(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, _ := createTestAPIs(t, &advertRepo, &userRepo)
+
+	advertRepo.On("GetList", mock.Anything, advert_domain.ListFilter{
+		Languages: LanguageTags{English},
+		Query:     "free room",
+		Limit:     MaxAdvertsInResponse,
+	}).Return([]*advert_domain.Advert{
+		{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{English: "Free room"},
+				Description: MultilingualString{English: "description"},
+			},
+		},
+	}, nil)
+
+	var response []advertResponse
+	resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts?langs=en&q=%s", server.URL, url.QueryEscape(" free room ")), nil, &response, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, response, 1)
+
+	resp = doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts?q=%s", server.URL, strings.Repeat("a", MaxSearchQueryLength+1)), nil, nil, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	advertRepo.AssertNumberOfCalls(t, "GetList", 1)
+}
+
+func TestAdvertsListFilter(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{owner, stranger} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	createdAfter := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	advertRepo.On("GetList", mock.Anything, mock.Anything).Return([]*advert_domain.Advert{}, nil)
+
+	type testCase struct {
+		name           string
+		user           *user_domain.User
+		params         url.Values
+		expectedStatus int
+		expectedFilter *advert_domain.ListFilter
+	}
+
+	testCases := []testCase{
+		{
+			name: "all filters",
+			params: url.Values{
+				"types":         {"job,transport"},
+				"user_id":       {owner.ID.String()},
+				"created_after": {createdAfter.Format(time.RFC3339)},
+				"limit":         {"10"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				Types:        []domain.AdvertType{domain.AdvertTypeJob, domain.AdvertTypeTransport},
+				UserID:       &owner.ID,
+				CreatedAfter: &createdAfter,
+				Limit:        10,
+			},
+		},
+		{
+			name: "location filters",
+			params: url.Values{
+				"city":   {"Lviv"},
+				"region": {"Lvivska"},
+				"lat":    {"49.84"},
+				"lon":    {"24.03"},
+				"radius": {"25"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				City:     "Lviv",
+				Region:   "Lvivska",
+				Near:     &domain.Coordinates{Latitude: 49.84, Longitude: 24.03},
+				RadiusKm: 25,
+				Limit:    MaxAdvertsInResponse,
+			},
+		},
+		{
+			name:           "negative limit and offset",
+			params:         url.Values{"limit": {"-1"}, "offset": {"-10"}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{Limit: MaxAdvertsInResponse},
+		},
+		{
+			name:           "zero limit",
+			params:         url.Values{"limit": {"0"}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{Limit: MaxAdvertsInResponse},
+		},
+		{
+			name:           "limit over the maximum",
+			params:         url.Values{"limit": {"100000"}, "offset": {"20"}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{Limit: MaxAdvertsInResponse, Offset: 20},
+		},
+		{
+			name:           "radius without point",
+			params:         url.Values{"radius": {"25"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "too big radius",
+			params:         url.Values{"lat": {"49.84"}, "lon": {"24.03"}, "radius": {"5000"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unknown type",
+			params:         url.Values{"types": {"job,spaceship"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid user id",
+			params:         url.Values{"user_id": {"abc"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid date",
+			params:         url.Values{"created_before": {"yesterday"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "inverted date range",
+			params:         url.Values{"created_after": {"2022-03-02T00:00:00Z"}, "created_before": {"2022-03-01T00:00:00Z"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "destroyed adverts of anonymous",
+			params:         url.Values{"include_destroyed": {"true"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "destroyed adverts of someone else",
+			user:           stranger,
+			params:         url.Values{"include_destroyed": {"true"}, "user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "expired adverts of anonymous",
+			params:         url.Values{"include_expired": {"true"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "own expired adverts",
+			user:           owner,
+			params:         url.Values{"include_expired": {"true"}, "user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				UserID:         &owner.ID,
+				IncludeExpired: true,
+				Statuses:       advert_domain.Statuses(),
+				Limit:          MaxAdvertsInResponse,
+			},
+		},
+		{
+			name:           "own destroyed adverts",
+			user:           owner,
+			params:         url.Values{"include_destroyed": {"true"}, "user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				UserID:           &owner.ID,
+				IncludeDestroyed: true,
+				Statuses:         advert_domain.Statuses(),
+				Limit:            MaxAdvertsInResponse,
+			},
+		},
+		{
+			name:           "own adverts of any status",
+			user:           owner,
+			params:         url.Values{"user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				UserID:   &owner.ID,
+				Statuses: advert_domain.Statuses(),
+				Limit:    MaxAdvertsInResponse,
+			},
+		},
+		{
+			name:           "own pending adverts",
+			user:           owner,
+			params:         url.Values{"status": {"pending"}, "user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				UserID:   &owner.ID,
+				Statuses: []advert_domain.Status{advert_domain.StatusPending},
+				Limit:    MaxAdvertsInResponse,
+			},
+		},
+		{
+			name:           "published adverts of someone else",
+			user:           stranger,
+			params:         url.Values{"user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				UserID: &owner.ID,
+				Limit:  MaxAdvertsInResponse,
+			},
+		},
+		{
+			name:           "pending adverts of someone else",
+			user:           stranger,
+			params:         url.Values{"status": {"pending"}, "user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "pending adverts of anonymous",
+			params:         url.Values{"status": {"pending"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unknown status",
+			user:           owner,
+			params:         url.Values{"status": {"hidden"}, "user_id": {owner.ID.String()}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			advertRepo.Calls = nil
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts?%s", server.URL, tC.params.Encode()), nil, nil, cookies)
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			if tC.expectedFilter == nil {
+				advertRepo.AssertNotCalled(t, "GetList", mock.Anything, mock.Anything)
+				return
+			}
+			advertRepo.AssertCalled(t, "GetList", mock.Anything, *tC.expectedFilter)
+		})
+	}
+}
+
+func TestAdvertsListCursor(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, _ := createTestAPIs(t, &advertRepo, &userRepo)
+
+	firstPage := []*advert_domain.Advert{
+		{ID: uuid.New(), CreatedAt: time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), CreatedAt: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	lastCursor := advert_domain.CursorOf(firstPage[1])
+	advertRepo.On("GetList", mock.Anything, advert_domain.ListFilter{Limit: 2}).Return(firstPage, nil)
+	advertRepo.On("GetList", mock.Anything, advert_domain.ListFilter{Limit: 2, After: &lastCursor}).Return([]*advert_domain.Advert{
+		{ID: uuid.New(), CreatedAt: time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	var response []advertResponse
+	resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts?limit=2", server.URL), nil, &response, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, response, 2)
+	cursor := resp.Header.Get(NextCursorHeader)
+	assert.Equal(t, lastCursor.String(), cursor)
+
+	response = nil
+	resp = doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts?limit=2&cursor=%s", server.URL, cursor), nil, &response, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, response, 1)
+	assert.Empty(t, resp.Header.Get(NextCursorHeader))
+
+	resp = doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts?cursor=%s", server.URL, "garbage"), nil, nil, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	resp = doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts?q=room&cursor=%s", server.URL, cursor), nil, nil, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	advertRepo.AssertNumberOfCalls(t, "GetList", 2)
+}
+
+func TestAddAdvertAttributes(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner"}
+	userRepo.On("GetByLogin", mock.Anything, owner.Login).Return(owner, nil)
+	advertRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+	cookies := user.CreateTestSession(t, owner, sessionStore)
+
+	type testCase struct {
+		name           string
+		advertType     domain.AdvertType
+		attributes     string
+		expectedStatus int
+		expected       map[string]interface{}
+	}
+
+	testCases := []testCase{
+		{
+			name:           "place to stay",
+			advertType:     domain.AdvertTypePlaceToStay,
+			attributes:     `{"capacity": 4, "rooms": 2, "pets_allowed": true}`,
+			expectedStatus: http.StatusCreated,
+			expected:       map[string]interface{}{"capacity": 4.0, "rooms": 2.0, "pets_allowed": true, "children_allowed": false},
+		},
+		{
+			name:           "without attributes",
+			advertType:     domain.AdvertTypeJob,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "attributes of other type",
+			advertType:     domain.AdvertTypePlaceToStay,
+			attributes:     `{"origin": "Przemyśl", "destination": "Kraków", "free_seats": 3}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid value",
+			advertType:     domain.AdvertTypePlaceToStay,
+			attributes:     `{"capacity": 0}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unknown advert type",
+			advertType:     "spaceship",
+			attributes:     `{"capacity": 1}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			payload := newAdvertPayload{
+				Title:          MultilingualString{English: "x"},
+				Description:    MultilingualString{English: "x"},
+				Type:           tC.advertType,
+				ContactDetails: contactPayload{Mail: *test_helpers.RandomMail()},
+			}
+			if tC.attributes != "" {
+				payload.Attributes = json.RawMessage(tC.attributes)
+			}
+
+			response := advertResponse{}
+			resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts", server.URL), payload, &response, cookies)
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			if tC.expectedStatus != http.StatusCreated {
+				return
+			}
+			if tC.expected == nil {
+				assert.Nil(t, response.Attributes)
+				return
+			}
+			assert.Equal(t, tC.expected, response.Attributes)
+		})
+	}
+}
+
+func TestRenewAdvert(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner"}
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger"}
+	for _, usr := range []*user_domain.User{owner, stranger} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	archivedAt := time.Now().Add(-time.Hour)
+	existingAdvertID := uuid.New()
+	advertRepo.On("Get", mock.Anything, existingAdvertID).Return(advert_domain.Advert{
+		ID: existingAdvertID,
+		Details: domain.AdvertDetails{
+			Title:       MultilingualString{English: "title"},
+			Description: MultilingualString{English: "description"},
+			Type:        domain.AdvertTypeTransport,
+		},
+		User:       owner,
+		ExpiresAt:  archivedAt,
+		ArchivedAt: &archivedAt,
+	}, nil)
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	type testCase struct {
+		name           string
+		user           *user_domain.User
+		advertID       uuid.UUID
+		expectedStatus int
+	}
+
+	testCases := []testCase{
+		{name: "not authorised", advertID: existingAdvertID, expectedStatus: http.StatusForbidden},
+		{name: "not advert owner", user: stranger, advertID: existingAdvertID, expectedStatus: http.StatusForbidden},
+		{name: "advert not found", user: owner, advertID: uuid.New(), expectedStatus: http.StatusNotFound},
+		{name: "success", user: owner, advertID: existingAdvertID, expectedStatus: http.StatusOK},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			response := advertResponse{}
+			resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts/%s/renew", server.URL, tC.advertID), nil, &response, cookies)
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			if tC.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Nil(t, response.ArchivedAt)
+			assert.WithinDuration(t, time.Now().Add(domain.AdvertTypeTransport.Lifetime()), response.ExpiresAt, time.Minute)
+		})
+	}
+	advertRepo.AssertNumberOfCalls(t, "Update", 1)
+}
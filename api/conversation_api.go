@@ -0,0 +1,416 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	application "github.com/ukrainian-brothers/board-backend/app"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ConversationAPI struct {
+	log    *logrus.Entry
+	router *mux.Router
+	app    application.Application
+}
+
+func NewConversationAPI(r *mux.Router, log *logrus.Entry, app application.Application, middleware *MiddlewareProvider) *ConversationAPI {
+	conversationApi := ConversationAPI{router: r, app: app, log: log}
+	r.HandleFunc("/api/adverts/{id}/conversations", middleware.AuthMiddleware(conversationApi.StartConversation, log)).Methods("POST")
+	r.HandleFunc("/api/user/conversations", middleware.AuthMiddleware(conversationApi.ConversationsList, log)).Methods("GET")
+	// registered before the conversation routes, so "unread" isn't taken for the conversation id
+	r.HandleFunc("/api/user/conversations/unread", middleware.AuthMiddleware(conversationApi.UnreadCount, log)).Methods("GET")
+	r.HandleFunc("/api/user/conversations/{id}/messages", middleware.AuthMiddleware(conversationApi.MessagesList, log)).Methods("GET")
+	r.HandleFunc("/api/user/conversations/{id}/messages", middleware.AuthMiddleware(conversationApi.SendMessage, log)).Methods("POST")
+	r.HandleFunc("/api/user/conversations/{id}/read", middleware.AuthMiddleware(conversationApi.MarkRead, log)).Methods("POST")
+	r.HandleFunc("/api/user/conversations/{id}/block", middleware.AuthMiddleware(conversationApi.Block, log)).Methods("PUT")
+	r.HandleFunc("/api/user/conversations/{id}/block", middleware.AuthMiddleware(conversationApi.Unblock, log)).Methods("DELETE")
+	return &conversationApi
+}
+
+const (
+	MaxConversationsInResponse = 50
+	MaxMessagesInResponse      = 100
+)
+
+type messagePayload struct {
+	Message string `json:"message"`
+}
+
+type conversationResponse struct {
+	ID            string    `json:"id"`
+	AdvertID      string    `json:"advert_id"`
+	OwnerID       string    `json:"owner_id"`
+	SeekerID      string    `json:"seeker_id"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	Blocked       bool      `json:"blocked"`
+	Unread        int       `json:"unread"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (resp *conversationResponse) LoadConversation(conv conversation.Conversation, unread int) {
+	resp.ID = conv.ID.String()
+	resp.AdvertID = conv.AdvertID.String()
+	resp.OwnerID = conv.OwnerID.String()
+	resp.SeekerID = conv.SeekerID.String()
+	resp.LastMessageAt = conv.LastMessageAt
+	resp.Blocked = conv.IsBlocked()
+	resp.Unread = unread
+	resp.CreatedAt = conv.CreatedAt
+}
+
+type messageResponse struct {
+	ID        string    `json:"id"`
+	SenderID  string    `json:"sender_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (resp *messageResponse) LoadMessage(msg conversation.Message) {
+	resp.ID = msg.ID.String()
+	resp.SenderID = msg.SenderID.String()
+	resp.Message = msg.Body
+	resp.CreatedAt = msg.CreatedAt
+}
+
+// writeConversationError maps errors of the conversation rules to responses, false is returned for unexpected errors
+func writeConversationError(w http.ResponseWriter, err error) bool {
+	if errors.Is(err, conversation.ConversationNotFound) || errors.Is(err, conversation.NotParticipantErr) {
+		WriteError(w, http.StatusNotFound, "conversation.not_found", "conversation not found")
+		return true
+	}
+	if errors.Is(err, conversation.InvalidMessageErr) {
+		WriteErrorf(w, http.StatusUnprocessableEntity, "conversation.invalid_message", "message is required and can't be longer than %d characters", conversation.MaxMessageLength)
+		return true
+	}
+	if errors.Is(err, conversation.BlockedErr) {
+		WriteError(w, http.StatusForbidden, "conversation.blocked", "you have been blocked by the advert owner")
+		return true
+	}
+	if errors.Is(err, conversation.NotOwnerErr) {
+		WriteError(w, http.StatusForbidden, "conversation.not_owner", "only the advert owner can block the conversation")
+		return true
+	}
+	if errors.Is(err, conversation.OwnAdvertErr) || errors.Is(err, conversation.NoAdvertOwnerErr) {
+		WriteError(w, http.StatusUnprocessableEntity, "conversation.cannot_start", "can't start conversation about this advert")
+		return true
+	}
+	return false
+}
+
+// requireUser returns the logged in user, otherwise the error is written and nil is returned
+func (a ConversationAPI) requireUser(w http.ResponseWriter, r *http.Request, log *logrus.Entry) *user.User {
+	ctx := r.Context()
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to access conversations")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return nil
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to access conversations")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return nil
+		}
+		log.WithError(err).Error("failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return nil
+	}
+	return usr
+}
+
+// requireParticipant returns the logged in user and the conversation from the url if the user takes part in it,
+// otherwise the error is written and nil is returned
+func (a ConversationAPI) requireParticipant(w http.ResponseWriter, r *http.Request, log *logrus.Entry) (*user.User, *conversation.Conversation) {
+	usr := a.requireUser(w, r, log)
+	if usr == nil {
+		return nil, nil
+	}
+
+	conversationID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "conversation.not_found", "conversation not found")
+		return nil, nil
+	}
+
+	conv, err := a.app.Queries.GetConversation.Execute(r.Context(), conversationID, usr)
+	if err != nil {
+		if writeConversationError(w, err) {
+			return nil, nil
+		}
+		log.WithError(err).Error("failed getting conversation")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return nil, nil
+	}
+	return usr, &conv
+}
+
+func decodeMessage(w http.ResponseWriter, r *http.Request, log *logrus.Entry) (string, bool) {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	payload := messagePayload{}
+	err := dec.Decode(&payload)
+	if err != nil {
+		log.WithError(err).Error("failed decoding message payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return "", false
+	}
+	return payload.Message, true
+}
+
+// StartConversation sends the first message to the advert owner, following ones are added to the same conversation
+func (a ConversationAPI) StartConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log.WithField("advert_id", mux.Vars(r)["id"])
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	usr := a.requireUser(w, r, log)
+	if usr == nil {
+		return
+	}
+	log = log.WithField("user_login", usr.Login)
+
+	message, ok := decodeMessage(w, r, log)
+	if !ok {
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("StartConversation failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if !adv.CanBeViewedBy(usr) {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	conv, _, err := a.app.Commands.StartConversation.Execute(ctx, &adv, usr, message)
+	if err != nil {
+		if writeConversationError(w, err) {
+			return
+		}
+		log.WithError(err).Error("StartConversation failed storing conversation")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	// sending the message marks the conversation as read by the sender
+	response := conversationResponse{}
+	response.LoadConversation(*conv, 0)
+	WriteJSON(w, 201, response)
+}
+
+// ConversationsList returns conversations of the user from the most recently active one with the number of unread messages
+func (a ConversationAPI) ConversationsList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	usr := a.requireUser(w, r, log)
+	if usr == nil {
+		return
+	}
+	log = log.WithField("user_login", usr.Login)
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil || limit <= 0 || limit > MaxConversationsInResponse {
+		limit = MaxConversationsInResponse
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	conversations, err := a.app.Queries.GetConversations.Execute(ctx, usr.ID, limit, offset)
+	if err != nil {
+		log.WithError(err).Error("ConversationsList failed while fetching conversations")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	unread, err := a.app.Queries.GetUnreadCounts.Execute(ctx, usr.ID)
+	if err != nil {
+		log.WithError(err).Error("ConversationsList failed counting unread messages")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := []conversationResponse{}
+	for _, conv := range conversations {
+		convResponse := conversationResponse{}
+		convResponse.LoadConversation(conv, unread[conv.ID])
+		response = append(response, convResponse)
+	}
+
+	WriteJSON(w, 200, response)
+}
+
+// UnreadCount returns the number of unread messages in all conversations of the user, e.g. for the badge in the menu
+func (a ConversationAPI) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	usr := a.requireUser(w, r, log)
+	if usr == nil {
+		return
+	}
+
+	unread, err := a.app.Queries.GetUnreadCounts.Execute(ctx, usr.ID)
+	if err != nil {
+		log.WithError(err).WithField("user_login", usr.Login).Error("UnreadCount failed counting unread messages")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	total := 0
+	for _, count := range unread {
+		total += count
+	}
+
+	WriteJSON(w, 200, map[string]int{"unread": total, "conversations": len(unread)})
+}
+
+// MessagesList returns messages of the conversation from the newest one, it doesn't mark them as read
+func (a ConversationAPI) MessagesList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log.WithField("conversation_id", mux.Vars(r)["id"])
+
+	_, conv := a.requireParticipant(w, r, log)
+	if conv == nil {
+		return
+	}
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil || limit <= 0 || limit > MaxMessagesInResponse {
+		limit = MaxMessagesInResponse
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	messages, err := a.app.Queries.GetMessages.Execute(ctx, conv.ID, limit, offset)
+	if err != nil {
+		log.WithError(err).Error("MessagesList failed while fetching messages")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := []messageResponse{}
+	for _, msg := range messages {
+		msgResponse := messageResponse{}
+		msgResponse.LoadMessage(msg)
+		response = append(response, msgResponse)
+	}
+
+	WriteJSON(w, 200, response)
+}
+
+func (a ConversationAPI) SendMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log.WithField("conversation_id", mux.Vars(r)["id"])
+
+	usr, conv := a.requireParticipant(w, r, log)
+	if conv == nil {
+		return
+	}
+
+	message, ok := decodeMessage(w, r, log)
+	if !ok {
+		return
+	}
+
+	msg, err := a.app.Commands.SendMessage.Execute(ctx, conv, usr, message)
+	if err != nil {
+		if writeConversationError(w, err) {
+			return
+		}
+		log.WithError(err).Error("SendMessage failed storing message")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := messageResponse{}
+	response.LoadMessage(*msg)
+	WriteJSON(w, 201, response)
+}
+
+func (a ConversationAPI) MarkRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log.WithField("conversation_id", mux.Vars(r)["id"])
+
+	usr, conv := a.requireParticipant(w, r, log)
+	if conv == nil {
+		return
+	}
+
+	err := a.app.Commands.MarkConversationRead.Execute(ctx, conv, usr)
+	if err != nil {
+		if writeConversationError(w, err) {
+			return
+		}
+		log.WithError(err).Error("MarkRead failed updating conversation")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
+
+func (a ConversationAPI) Block(w http.ResponseWriter, r *http.Request) {
+	a.setBlocked(w, r, true)
+}
+
+func (a ConversationAPI) Unblock(w http.ResponseWriter, r *http.Request) {
+	a.setBlocked(w, r, false)
+}
+
+func (a ConversationAPI) setBlocked(w http.ResponseWriter, r *http.Request, blocked bool) {
+	ctx := r.Context()
+	log := a.log.WithFields(logrus.Fields{
+		"conversation_id": mux.Vars(r)["id"],
+		"blocked":         blocked,
+	})
+
+	usr, conv := a.requireParticipant(w, r, log)
+	if conv == nil {
+		return
+	}
+
+	err := a.app.Commands.BlockConversation.Execute(ctx, conv, usr, blocked)
+	if err != nil {
+		if writeConversationError(w, err) {
+			return
+		}
+		log.WithError(err).Error("failed updating conversation block")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
+	conversation_domain "github.com/ukrainian-brothers/board-backend/domain/conversation"
+	user_domain "github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal/conversation"
+	"github.com/ukrainian-brothers/board-backend/internal/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConversations(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	conversationRepo := conversation.RepositoryMock{}
+
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.StartConversation = board.NewStartConversation(&conversationRepo)
+	app.Commands.SendMessage = board.NewSendMessage(&conversationRepo)
+	app.Commands.MarkConversationRead = board.NewMarkConversationRead(&conversationRepo)
+	app.Commands.BlockConversation = board.NewBlockConversation(&conversationRepo)
+	app.Queries.GetConversation = board.NewGetConversation(&conversationRepo)
+	app.Queries.GetConversations = board.NewGetConversations(&conversationRepo)
+	app.Queries.GetMessages = board.NewGetMessages(&conversationRepo)
+	app.Queries.GetUnreadCounts = board.NewGetUnreadCounts(&conversationRepo)
+	server, client, sessionStore := createTestServer(t, app)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	seeker := &user_domain.User{ID: uuid.New(), Login: "seeker", Role: user_domain.RoleUser}
+	returning := &user_domain.User{ID: uuid.New(), Login: "returning", Role: user_domain.RoleUser}
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{owner, seeker, returning, stranger} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	newAdvert := func(status advert_domain.Status) advert_domain.Advert {
+		return advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{English: "title"},
+				Description: MultilingualString{English: "description"},
+				Type:        domain.AdvertTypePlaceToStay,
+			},
+			User:   owner,
+			Status: status,
+		}
+	}
+	published := newAdvert(advert_domain.StatusPublished)
+	pending := newAdvert(advert_domain.StatusPending)
+	for _, adv := range []advert_domain.Advert{published, pending} {
+		advertRepo.On("Get", mock.Anything, adv.ID).Return(adv, nil)
+	}
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+
+	existing := conversation_domain.Conversation{
+		ID:            uuid.New(),
+		AdvertID:      published.ID,
+		OwnerID:       owner.ID,
+		SeekerID:      returning.ID,
+		LastMessageAt: time.Now().Add(-time.Hour),
+		CreatedAt:     time.Now().Add(-time.Hour),
+	}
+	blocked := existing
+	blocked.ID = uuid.New()
+	blockedAt := time.Now()
+	blocked.BlockedAt = &blockedAt
+	// the owner blocks the seeker after the conversation was loaded
+	blockedMeanwhile := existing
+	blockedMeanwhile.ID = uuid.New()
+	conversationRepo.On("GetByAdvert", mock.Anything, published.ID, returning.ID).Return(existing, nil)
+	conversationRepo.On("GetByAdvert", mock.Anything, mock.Anything, mock.Anything).Return(conversation_domain.Conversation{}, conversation_domain.ConversationNotFound)
+	conversationRepo.On("Get", mock.Anything, existing.ID).Return(existing, nil)
+	conversationRepo.On("Get", mock.Anything, blocked.ID).Return(blocked, nil)
+	conversationRepo.On("Get", mock.Anything, blockedMeanwhile.ID).Return(blockedMeanwhile, nil)
+	conversationRepo.On("Get", mock.Anything, mock.Anything).Return(conversation_domain.Conversation{}, conversation_domain.ConversationNotFound)
+	conversationRepo.On("Add", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	conversationRepo.On("AddMessage", mock.Anything, mock.MatchedBy(func(conv *conversation_domain.Conversation) bool {
+		return conv.ID == blockedMeanwhile.ID
+	}), mock.Anything).Return(conversation_domain.BlockedErr)
+	conversationRepo.On("AddMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	conversationRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	conversationRepo.On("UpdateBlock", mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("start", func(t *testing.T) {
+		type testCase struct {
+			name           string
+			user           *user_domain.User
+			advertID       uuid.UUID
+			message        string
+			expectedStatus int
+		}
+
+		testCases := []testCase{
+			{name: "not authorized", advertID: published.ID, message: "hi", expectedStatus: http.StatusForbidden},
+			{name: "advert not found", user: seeker, advertID: uuid.New(), message: "hi", expectedStatus: http.StatusNotFound},
+			{name: "advert not published", user: seeker, advertID: pending.ID, message: "hi", expectedStatus: http.StatusNotFound},
+			{name: "own advert", user: owner, advertID: published.ID, message: "hi", expectedStatus: http.StatusUnprocessableEntity},
+			{name: "empty message", user: seeker, advertID: published.ID, message: " ", expectedStatus: http.StatusUnprocessableEntity},
+			{name: "too long message", user: seeker, advertID: published.ID, message: strings.Repeat("x", conversation_domain.MaxMessageLength+1), expectedStatus: http.StatusUnprocessableEntity},
+			{name: "new conversation", user: seeker, advertID: published.ID, message: "Is it available?", expectedStatus: http.StatusCreated},
+			{name: "existing conversation", user: returning, advertID: published.ID, message: "Any news?", expectedStatus: http.StatusCreated},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.name, func(t *testing.T) {
+				var cookies []*http.Cookie
+				if tC.user != nil {
+					cookies = user.CreateTestSession(t, tC.user, sessionStore)
+				}
+
+				response := conversationResponse{}
+				resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts/%s/conversations", server.URL, tC.advertID), messagePayload{Message: tC.message}, &response, cookies)
+				assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+				if tC.expectedStatus != http.StatusCreated {
+					return
+				}
+				assert.Equal(t, owner.ID.String(), response.OwnerID)
+				assert.Equal(t, tC.user.ID.String(), response.SeekerID)
+			})
+		}
+		conversationRepo.AssertNumberOfCalls(t, "Add", 1)
+		conversationRepo.AssertNumberOfCalls(t, "AddMessage", 1)
+		conversationRepo.AssertCalled(t, "AddMessage", mock.Anything, mock.MatchedBy(func(conv *conversation_domain.Conversation) bool {
+			return conv.ID == existing.ID
+		}), mock.Anything)
+	})
+
+	t.Run("participants only", func(t *testing.T) {
+		url := fmt.Sprintf("%s/api/user/conversations/%s", server.URL, existing.ID)
+
+		for _, request := range []struct{ method, path string }{
+			{"GET", "/messages"}, {"POST", "/messages"}, {"POST", "/read"}, {"PUT", "/block"},
+		} {
+			resp := doRequest(t, client, request.method, url+request.path, messagePayload{Message: "hi"}, nil, user.CreateTestSession(t, stranger, sessionStore))
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode, request)
+		}
+
+		resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/user/conversations/%s/messages", server.URL, uuid.New()), nil, nil, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		conversationRepo.On("GetMessages", mock.Anything, existing.ID, MaxMessagesInResponse, 0).Return([]conversation_domain.Message{
+			{ID: uuid.New(), ConversationID: existing.ID, SenderID: returning.ID, Body: "Any news?"},
+		}, nil)
+
+		var messages []messageResponse
+		resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/user/conversations/%s/messages", server.URL, existing.ID), nil, &messages, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, messages, 1)
+		assert.Equal(t, "Any news?", messages[0].Message)
+
+		message := messageResponse{}
+		resp = doRequest(t, client, "POST", fmt.Sprintf("%s/api/user/conversations/%s/messages", server.URL, existing.ID), messagePayload{Message: "Still free"}, &message, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, owner.ID.String(), message.SenderID)
+
+		resp = doRequest(t, client, "POST", fmt.Sprintf("%s/api/user/conversations/%s/read", server.URL, existing.ID), nil, nil, user.CreateTestSession(t, returning, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		conversationRepo.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(conv *conversation_domain.Conversation) bool {
+			return conv.ID == existing.ID && conv.SeekerReadAt != nil
+		}))
+	})
+
+	t.Run("list and unread", func(t *testing.T) {
+		conversationRepo.On("GetList", mock.Anything, owner.ID, MaxConversationsInResponse, 0).Return([]conversation_domain.Conversation{existing}, nil)
+		conversationRepo.On("CountUnread", mock.Anything, owner.ID).Return(map[uuid.UUID]int{existing.ID: 2, uuid.New(): 1}, nil)
+
+		var conversations []conversationResponse
+		resp := doRequest(t, client, "GET", server.URL+"/api/user/conversations", nil, &conversations, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, conversations, 1)
+		assert.Equal(t, 2, conversations[0].Unread)
+
+		unread := map[string]int{}
+		resp = doRequest(t, client, "GET", server.URL+"/api/user/conversations/unread", nil, &unread, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, map[string]int{"unread": 3, "conversations": 2}, unread)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		url := fmt.Sprintf("%s/api/user/conversations/%s/block", server.URL, existing.ID)
+
+		resp := doRequest(t, client, "PUT", url, nil, nil, user.CreateTestSession(t, returning, sessionStore))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = doRequest(t, client, "PUT", url, nil, nil, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		conversationRepo.AssertCalled(t, "UpdateBlock", mock.Anything, mock.MatchedBy(func(conv *conversation_domain.Conversation) bool {
+			return conv.ID == existing.ID && conv.IsBlocked()
+		}))
+
+		resp = doRequest(t, client, "DELETE", url, nil, nil, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("blocked seeker", func(t *testing.T) {
+		resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/user/conversations/%s/messages", server.URL, blocked.ID), messagePayload{Message: "hello?"}, nil, user.CreateTestSession(t, returning, sessionStore))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = doRequest(t, client, "POST", fmt.Sprintf("%s/api/user/conversations/%s/messages", server.URL, blockedMeanwhile.ID), messagePayload{Message: "hello?"}, nil, user.CreateTestSession(t, returning, sessionStore))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	application "github.com/ukrainian-brothers/board-backend/app"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"net/http"
+	"strconv"
+)
+
+type FavouriteAPI struct {
+	log    *logrus.Entry
+	router *mux.Router
+	app    application.Application
+}
+
+func NewFavouriteAPI(r *mux.Router, log *logrus.Entry, app application.Application, middleware *MiddlewareProvider) *FavouriteAPI {
+	favouriteApi := FavouriteAPI{router: r, app: app, log: log}
+	r.HandleFunc("/api/adverts/{id}/favourite", middleware.AuthMiddleware(favouriteApi.AddFavourite, log)).Methods("PUT")
+	r.HandleFunc("/api/adverts/{id}/favourite", middleware.AuthMiddleware(favouriteApi.RemoveFavourite, log)).Methods("DELETE")
+	r.HandleFunc("/api/user/favourites", middleware.AuthMiddleware(favouriteApi.FavouritesList, log)).Methods("GET")
+	return &favouriteApi
+}
+
+// favouritesOf returns which of the adverts are favourites of the logged in user,
+// nil is returned for anonymous requests and when the check failed, so the flag is left out of the response
+func favouritesOf(ctx context.Context, app application.Application, log *logrus.Entry, usr *user.User, adverts ...*advert.Advert) map[uuid.UUID]bool {
+	if usr == nil || len(adverts) == 0 {
+		return nil
+	}
+
+	var ids []uuid.UUID
+	for _, adv := range adverts {
+		ids = append(ids, adv.ID)
+	}
+
+	favourites, err := app.Queries.AreFavourite.Execute(ctx, usr.ID, ids)
+	if err != nil {
+		log.WithError(err).Error("failed checking favourite adverts")
+		return nil
+	}
+	return favourites
+}
+
+func (a FavouriteAPI) AddFavourite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to add favourite")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to add favourite")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("AddFavourite failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("AddFavourite failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if !adv.CanBeViewedBy(usr) {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	fav, err := favourite.NewFavourite(usr, adv.ID)
+	if err != nil {
+		log.WithError(err).Error("AddFavourite failed creating favourite")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.AddFavourite.Execute(ctx, fav)
+	if err != nil {
+		if errors.Is(err, favourite.TooManyFavouritesErr) {
+			WriteErrorf(w, http.StatusConflict, "favourite.too_many", "can't have more than %d favourite adverts", favourite.MaxFavourites)
+			return
+		}
+		log.WithError(err).Error("AddFavourite failed adding favourite")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
+
+// RemoveFavourite doesn't check the advert, so destroyed adverts can be removed from the shortlist as well
+func (a FavouriteAPI) RemoveFavourite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to remove favourite")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to remove favourite")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("RemoveFavourite failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.RemoveFavourite.Execute(ctx, usr.ID, advertID)
+	if err != nil {
+		log.WithError(err).Error("RemoveFavourite failed removing favourite")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
+
+// FavouritesList returns favourite adverts of the logged in user from the most recently added one
+func (a FavouriteAPI) FavouritesList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to list favourites")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithField("user_login", userLogin.(string))
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil || limit <= 0 || limit > MaxAdvertsInResponse {
+		limit = MaxAdvertsInResponse
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to list favourites")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("FavouritesList failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adverts, err := a.app.Queries.GetFavourites.Execute(ctx, usr.ID, limit, offset)
+	if err != nil {
+		log.WithError(err).Error("FavouritesList failed while fetching favourite adverts")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := []advertResponse{}
+	for _, adv := range adverts {
+		advResponse := advertResponse{}
+		advResponse.LoadAdvert(adv)
+		advResponse.LoadContact(adv, usr, a.app.Commands.RevealContact.Policy)
+		advResponse.LoadFavourite(true)
+		response = append(response, advResponse)
+	}
+
+	WriteJSON(w, 200, response)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
+	favourite_domain "github.com/ukrainian-brothers/board-backend/domain/favourite"
+	user_domain "github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal/favourite"
+	"github.com/ukrainian-brothers/board-backend/internal/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"net/http"
+	"testing"
+)
+
+func TestFavourites(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	favouriteRepo := favourite.RepositoryMock{}
+
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.AddFavourite = board.NewAddFavourite(&favouriteRepo)
+	app.Commands.RemoveFavourite = board.NewRemoveFavourite(&favouriteRepo)
+	app.Queries.GetFavourites = board.NewGetFavourites(&favouriteRepo, &advertRepo)
+	app.Queries.AreFavourite = board.NewAreFavourite(&favouriteRepo)
+	server, client, sessionStore := createTestServer(t, app)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	collector := &user_domain.User{ID: uuid.New(), Login: "collector", Role: user_domain.RoleUser}
+	hoarder := &user_domain.User{ID: uuid.New(), Login: "hoarder", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{owner, collector, hoarder} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	newAdvert := func(status advert_domain.Status) advert_domain.Advert {
+		return advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{English: "title"},
+				Description: MultilingualString{English: "description"},
+				Type:        domain.AdvertTypePlaceToStay,
+			},
+			User:   owner,
+			Status: status,
+		}
+	}
+	published := newAdvert(advert_domain.StatusPublished)
+	pending := newAdvert(advert_domain.StatusPending)
+	for _, adv := range []advert_domain.Advert{published, pending} {
+		advertRepo.On("Get", mock.Anything, adv.ID).Return(adv, nil)
+	}
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("IncrementViews", mock.Anything, mock.Anything).Return(nil)
+
+	favouriteRepo.On("Count", mock.Anything, hoarder.ID).Return(favourite_domain.MaxFavourites, nil)
+	favouriteRepo.On("Count", mock.Anything, mock.Anything).Return(0, nil)
+	favouriteRepo.On("AreFavourite", mock.Anything, hoarder.ID, mock.Anything).Return(map[uuid.UUID]bool{}, nil)
+	favouriteRepo.On("AreFavourite", mock.Anything, collector.ID, []uuid.UUID{published.ID}).Return(map[uuid.UUID]bool{published.ID: true}, nil)
+	favouriteRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+	favouriteRepo.On("Remove", mock.Anything, collector.ID, published.ID).Return(nil)
+
+	t.Run("add", func(t *testing.T) {
+		type testCase struct {
+			name           string
+			user           *user_domain.User
+			advertID       uuid.UUID
+			expectedStatus int
+		}
+
+		testCases := []testCase{
+			{name: "not authorized", advertID: published.ID, expectedStatus: http.StatusForbidden},
+			{name: "advert not found", user: collector, advertID: uuid.New(), expectedStatus: http.StatusNotFound},
+			{name: "advert not published", user: collector, advertID: pending.ID, expectedStatus: http.StatusNotFound},
+			{name: "too many favourites", user: hoarder, advertID: published.ID, expectedStatus: http.StatusConflict},
+			{name: "success", user: collector, advertID: published.ID, expectedStatus: http.StatusOK},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.name, func(t *testing.T) {
+				var cookies []*http.Cookie
+				if tC.user != nil {
+					cookies = user.CreateTestSession(t, tC.user, sessionStore)
+				}
+
+				resp := doRequest(t, client, "PUT", fmt.Sprintf("%s/api/adverts/%s/favourite", server.URL, tC.advertID), nil, nil, cookies)
+				assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			})
+		}
+		favouriteRepo.AssertNumberOfCalls(t, "Add", 1)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		url := fmt.Sprintf("%s/api/adverts/%s/favourite", server.URL, published.ID)
+
+		resp := doRequest(t, client, "DELETE", url, nil, nil, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = doRequest(t, client, "DELETE", url, nil, nil, user.CreateTestSession(t, collector, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		favouriteRepo.AssertNumberOfCalls(t, "Remove", 1)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		favouriteRepo.On("GetList", mock.Anything, collector.ID, MaxAdvertsInResponse, 0).Return([]favourite_domain.Favourite{
+			{UserID: collector.ID, AdvertID: pending.ID},
+			{UserID: collector.ID, AdvertID: published.ID},
+		}, nil)
+		// pending advert isn't listed anymore, so it's skipped
+		advertRepo.On("GetList", mock.Anything, advert_domain.ListFilter{IDs: []uuid.UUID{pending.ID, published.ID}, Limit: 2}).
+			Return([]*advert_domain.Advert{&published}, nil)
+
+		resp := doRequest(t, client, "GET", server.URL+"/api/user/favourites", nil, nil, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		var response []advertResponse
+		resp = doRequest(t, client, "GET", server.URL+"/api/user/favourites", nil, &response, user.CreateTestSession(t, collector, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, response, 1)
+		assert.Equal(t, published.ID.String(), response[0].ID)
+		require.NotNil(t, response[0].IsFavourite)
+		assert.True(t, *response[0].IsFavourite)
+	})
+
+	t.Run("is_favourite flag", func(t *testing.T) {
+		type testCase struct {
+			name     string
+			user     *user_domain.User
+			expected *bool
+		}
+
+		yes, no := true, false
+		testCases := []testCase{
+			{name: "anonymous", expected: nil},
+			{name: "favourite", user: collector, expected: &yes},
+			{name: "not favourite", user: hoarder, expected: &no},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.name, func(t *testing.T) {
+				var cookies []*http.Cookie
+				if tC.user != nil {
+					cookies = user.CreateTestSession(t, tC.user, sessionStore)
+				}
+
+				response := advertResponse{}
+				resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts/%s", server.URL, published.ID), nil, &response, cookies)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, tC.expected, response.IsFavourite)
+			})
+		}
+	})
+}
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	application "github.com/ukrainian-brothers/board-backend/app"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/internal/common"
+	"github.com/ukrainian-brothers/board-backend/pkg/blob"
+	"github.com/ukrainian-brothers/board-backend/pkg/imaging"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type ImageAPI struct {
+	log    *logrus.Entry
+	router *mux.Router
+	app    application.Application
+	cfg    *common.Config
+}
+
+// uploadImageRoute is the name of the only route accepting payloads above the default body limit
+const uploadImageRoute = "upload_advert_image"
+
+func NewImageAPI(r *mux.Router, log *logrus.Entry, app application.Application, middleware *MiddlewareProvider, cfg *common.Config) *ImageAPI {
+	imageApi := ImageAPI{router: r, app: app, log: log, cfg: cfg}
+	r.HandleFunc("/api/adverts/{id}/images", middleware.AuthMiddleware(imageApi.UploadImage, log)).Methods("POST").Name(uploadImageRoute)
+	r.HandleFunc("/api/adverts/{id}/images/{image_id}", middleware.AuthMiddleware(imageApi.GetImage, log)).Methods("GET")
+	r.HandleFunc("/api/adverts/{id}/images/{image_id}/thumbnail", middleware.AuthMiddleware(imageApi.GetThumbnail, log)).Methods("GET")
+	r.HandleFunc("/api/adverts/{id}/images/{image_id}", middleware.AuthMiddleware(imageApi.DeleteImage, log)).Methods("DELETE")
+	return &imageApi
+}
+
+const (
+	// DefaultMaxImageSize is used when the limit is not configured
+	DefaultMaxImageSize = 10 << 20
+	// multipartOverhead leaves room for the boundaries and headers of the multipart form
+	multipartOverhead = 64 << 10
+)
+
+// maxImageSize is the limit of the uploaded image itself, the whole request may be larger by multipartOverhead
+func maxImageSize(cfg *common.Config) int64 {
+	if cfg.Images.MaxUploadSize > 0 {
+		return cfg.Images.MaxUploadSize
+	}
+	return DefaultMaxImageSize
+}
+
+type imageResponse struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// LoadImage points to the images served by the API, so the response doesn't depend on the blob storage in use
+func (resp *imageResponse) LoadImage(img advert.Image) {
+	resp.ID = img.ID.String()
+	resp.URL = fmt.Sprintf("/api/adverts/%s/images/%s", img.AdvertID, img.ID)
+	resp.ThumbnailURL = resp.URL + "/thumbnail"
+	resp.Width = img.Width
+	resp.Height = img.Height
+}
+
+// UploadImage accepts multipart form with JPEG, PNG or WebP file in the "image" field
+func (a ImageAPI) UploadImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to upload image")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	maxSize := maxImageSize(a.cfg)
+	if r.ContentLength > maxSize+multipartOverhead {
+		WriteError(w, http.StatusRequestEntityTooLarge, "image.too_large", "image too large")
+		return
+	}
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		// MaxBytesReader error is not exported in this Go version
+		if strings.Contains(err.Error(), "request body too large") {
+			WriteError(w, http.StatusRequestEntityTooLarge, "image.too_large", "image too large")
+			return
+		}
+		log.WithError(err).Info("UploadImage failed reading image from the form")
+		WriteError(w, http.StatusUnprocessableEntity, "image.missing", "missing image")
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		log.WithError(err).Error("UploadImage failed reading image")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+	if int64(len(content)) > maxSize {
+		WriteError(w, http.StatusRequestEntityTooLarge, "image.too_large", "image too large")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to upload image")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("UploadImage failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("UploadImage failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	img, err := a.app.Commands.AttachAdvertImage.Execute(ctx, &adv, usr, content)
+	if err != nil {
+		if errors.Is(err, advert.NotAdvertOwnerErr) {
+			log.Info("user tries to upload image to not owned advert")
+			WriteError(w, http.StatusForbidden, "advert.not_owner", "not advert owner")
+			return
+		}
+		if errors.Is(err, advert.TooManyImagesErr) {
+			WriteErrorf(w, http.StatusConflict, "image.too_many", "advert can't have more than %d images", advert.MaxImages)
+			return
+		}
+		if errors.Is(err, imaging.UnsupportedFormatErr) {
+			WriteError(w, http.StatusUnsupportedMediaType, "image.unsupported_type", "only JPEG, PNG and WebP images are supported")
+			return
+		}
+		if errors.Is(err, imaging.TooManyPixelsErr) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "image.dimensions_too_large", "image dimensions are too large")
+			return
+		}
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("UploadImage failed attaching image")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := imageResponse{}
+	response.LoadImage(img)
+	WriteJSON(w, 201, response)
+}
+
+func (a ImageAPI) GetImage(w http.ResponseWriter, r *http.Request) {
+	a.serveImage(w, r, false)
+}
+
+func (a ImageAPI) GetThumbnail(w http.ResponseWriter, r *http.Request) {
+	a.serveImage(w, r, true)
+}
+
+// serveImage streams the image from the storage, images of not published adverts are visible to the same users as the advert
+func (a ImageAPI) serveImage(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	ctx := r.Context()
+	log := a.log.WithFields(logrus.Fields{
+		"advert_id": mux.Vars(r)["id"],
+		"image_id":  mux.Vars(r)["image_id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+		return
+	}
+
+	imageID, err := uuid.Parse(mux.Vars(r)["image_id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+			return
+		}
+		log.WithError(err).Error("serveImage failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if adv.Status != advert.StatusPublished {
+		usr, err := sessionUser(r, a.app)
+		if err != nil {
+			log.WithError(err).Error("serveImage failed getting user by login")
+			WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+			return
+		}
+		if !adv.CanBeViewedBy(usr) {
+			WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+			return
+		}
+	}
+
+	img, err := adv.FindImage(imageID)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+		return
+	}
+
+	content, err := a.app.Queries.OpenAdvertImage.Execute(ctx, img, thumbnail)
+	if err != nil {
+		if errors.Is(err, blob.NotFoundErr) {
+			log.Error("image attached to the advert is missing in the storage")
+			WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+			return
+		}
+		log.WithError(err).Error("serveImage failed opening image")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// images never change, but the advert may be hidden later, so they are not cached for too long
+	if adv.Status == advert.StatusPublished {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	} else {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(w, content)
+	if err != nil {
+		log.WithError(err).Error("serveImage failed writing image")
+	}
+}
+
+func (a ImageAPI) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to delete image")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"advert_id":  mux.Vars(r)["id"],
+		"image_id":   mux.Vars(r)["image_id"],
+	})
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	imageID, err := uuid.Parse(mux.Vars(r)["image_id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to delete image")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("DeleteImage failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("DeleteImage failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.RemoveAdvertImage.Execute(ctx, &adv, usr, imageID)
+	if err != nil {
+		if errors.Is(err, advert.NotAdvertOwnerErr) {
+			log.Info("user tries to delete image of not owned advert")
+			WriteError(w, http.StatusForbidden, "advert.not_owner", "not advert owner")
+			return
+		}
+		if errors.Is(err, advert.ImageNotFound) {
+			WriteError(w, http.StatusNotFound, "image.not_found", "image not found")
+			return
+		}
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("DeleteImage failed removing image")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
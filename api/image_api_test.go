@@ -0,0 +1,236 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
+	user_domain "github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/blob"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	buf := bytes.Buffer{}
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 30, 20))))
+	return buf.Bytes()
+}
+
+func uploadImage(t *testing.T, client http.Client, url string, content []byte, cookies []*http.Cookie) *http.Response {
+	body := bytes.Buffer{}
+	form := multipart.NewWriter(&body)
+	if content != nil {
+		part, err := form.CreateFormFile("image", "photo")
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, form.Close())
+
+	req, err := http.NewRequest("POST", url, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestImages(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	storage, err := blob.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.AttachAdvertImage = board.NewAttachAdvertImage(&advertRepo, storage, advert_domain.ModerationPolicy{})
+	app.Commands.RemoveAdvertImage = board.NewRemoveAdvertImage(&advertRepo, storage)
+	app.Queries.OpenAdvertImage = board.NewOpenAdvertImage(storage)
+	server, client, sessionStore := createTestServer(t, app)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	stranger := &user_domain.User{ID: uuid.New(), Login: "stranger", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{owner, stranger} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	newAdvert := func(status advert_domain.Status, images int) advert_domain.Advert {
+		adv := advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{English: "title"},
+				Description: MultilingualString{English: "description"},
+				Type:        domain.AdvertTypePlaceToStay,
+			},
+			User:   owner,
+			Status: status,
+		}
+		for i := 0; i < images; i++ {
+			img := advert_domain.NewImage(adv.ID, "image/png", 30, 20)
+			require.NoError(t, storage.Put(context.Background(), img.Key(), strings.NewReader("image")))
+			require.NoError(t, storage.Put(context.Background(), img.ThumbnailKey(), strings.NewReader("thumbnail")))
+			adv.Images = append(adv.Images, img)
+		}
+		return adv
+	}
+	empty := newAdvert(advert_domain.StatusPublished, 0)
+	published := newAdvert(advert_domain.StatusPublished, 1)
+	pending := newAdvert(advert_domain.StatusPending, 1)
+	full := newAdvert(advert_domain.StatusPublished, advert_domain.MaxImages)
+	// images uploaded concurrently fill the advert after it was loaded
+	filled := newAdvert(advert_domain.StatusPublished, advert_domain.MaxImages-1)
+	for _, adv := range []advert_domain.Advert{empty, published, pending, full, filled} {
+		advertRepo.On("Get", mock.Anything, adv.ID).Return(adv, nil)
+	}
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("AddImage", mock.Anything, mock.MatchedBy(func(adv *advert_domain.Advert) bool {
+		return adv.ID == filled.ID
+	}), mock.Anything).Return(advert_domain.TooManyImagesErr)
+	advertRepo.On("AddImage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	advertRepo.On("RemoveImage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	advertRepo.On("IncrementViews", mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("upload", func(t *testing.T) {
+		type testCase struct {
+			name           string
+			user           *user_domain.User
+			advertID       uuid.UUID
+			content        []byte
+			expectedStatus int
+		}
+
+		testCases := []testCase{
+			{name: "not authorized", advertID: empty.ID, content: testPNG(t), expectedStatus: http.StatusForbidden},
+			{name: "not advert owner", user: stranger, advertID: empty.ID, content: testPNG(t), expectedStatus: http.StatusForbidden},
+			{name: "advert not found", user: owner, advertID: uuid.New(), content: testPNG(t), expectedStatus: http.StatusNotFound},
+			{name: "missing image", user: owner, advertID: empty.ID, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "unsupported format", user: owner, advertID: empty.ID, content: []byte("GIF89a"), expectedStatus: http.StatusUnsupportedMediaType},
+			{name: "too large", user: owner, advertID: empty.ID, content: make([]byte, DefaultMaxImageSize+1), expectedStatus: http.StatusRequestEntityTooLarge},
+			{name: "too many images", user: owner, advertID: full.ID, content: testPNG(t), expectedStatus: http.StatusConflict},
+			{name: "advert filled in the meantime", user: owner, advertID: filled.ID, content: testPNG(t), expectedStatus: http.StatusConflict},
+			{name: "success", user: owner, advertID: empty.ID, content: testPNG(t), expectedStatus: http.StatusCreated},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.name, func(t *testing.T) {
+				var cookies []*http.Cookie
+				if tC.user != nil {
+					cookies = user.CreateTestSession(t, tC.user, sessionStore)
+				}
+
+				resp := uploadImage(t, client, fmt.Sprintf("%s/api/adverts/%s/images", server.URL, tC.advertID), tC.content, cookies)
+				assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+				if tC.expectedStatus != http.StatusCreated {
+					return
+				}
+
+				response := imageResponse{}
+				responseToStruct(t, resp, &response)
+				assert.Equal(t, 30, response.Width)
+				assert.Equal(t, 20, response.Height)
+				assert.Equal(t, fmt.Sprintf("/api/adverts/%s/images/%s/thumbnail", empty.ID, response.ID), response.ThumbnailURL)
+
+				imageID, err := uuid.Parse(response.ID)
+				require.NoError(t, err)
+				stored, err := storage.Get(context.Background(), advert_domain.Image{ID: imageID, AdvertID: empty.ID}.Key())
+				require.NoError(t, err)
+				assert.NoError(t, stored.Close())
+			})
+		}
+		advertRepo.AssertNumberOfCalls(t, "AddImage", 2)
+		advertRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		// the image added to the published advert waits for the review
+		advertRepo.AssertCalled(t, "AddImage", mock.Anything, mock.MatchedBy(func(adv *advert_domain.Advert) bool {
+			return adv.ID == empty.ID && adv.Status == advert_domain.StatusPending
+		}), mock.Anything)
+	})
+
+	t.Run("advert response", func(t *testing.T) {
+		response := advertResponse{}
+		resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/adverts/%s", server.URL, published.ID), nil, &response, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, response.Images, 1)
+		assert.Equal(t, fmt.Sprintf("/api/adverts/%s/images/%s", published.ID, published.Images[0].ID), response.Images[0].URL)
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		type testCase struct {
+			name            string
+			user            *user_domain.User
+			path            string
+			expectedStatus  int
+			expectedContent string
+		}
+
+		testCases := []testCase{
+			{name: "image", path: firstImageResponse(published).URL, expectedStatus: http.StatusOK, expectedContent: "image"},
+			{name: "thumbnail", path: firstImageResponse(published).ThumbnailURL, expectedStatus: http.StatusOK, expectedContent: "thumbnail"},
+			{name: "image of another advert", path: fmt.Sprintf("/api/adverts/%s/images/%s", empty.ID, published.Images[0].ID), expectedStatus: http.StatusNotFound},
+			{name: "image of pending advert", path: firstImageResponse(pending).URL, expectedStatus: http.StatusNotFound},
+			{name: "image of pending advert seen by owner", user: owner, path: firstImageResponse(pending).URL, expectedStatus: http.StatusOK, expectedContent: "image"},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.name, func(t *testing.T) {
+				var cookies []*http.Cookie
+				if tC.user != nil {
+					cookies = user.CreateTestSession(t, tC.user, sessionStore)
+				}
+
+				resp := doRequest(t, client, "GET", server.URL+tC.path, nil, nil, cookies)
+				assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+				if tC.expectedStatus != http.StatusOK {
+					return
+				}
+
+				content, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tC.expectedContent, string(content))
+				assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+			})
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		url := server.URL + firstImageResponse(published).URL
+
+		resp := doRequest(t, client, "DELETE", url, nil, nil, user.CreateTestSession(t, stranger, sessionStore))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = doRequest(t, client, "DELETE", fmt.Sprintf("%s/api/adverts/%s/images/%s", server.URL, published.ID, uuid.New()), nil, nil, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = doRequest(t, client, "DELETE", url, nil, nil, user.CreateTestSession(t, owner, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err := storage.Get(context.Background(), published.Images[0].Key())
+		assert.ErrorIs(t, err, blob.NotFoundErr)
+		_, err = storage.Get(context.Background(), published.Images[0].ThumbnailKey())
+		assert.ErrorIs(t, err, blob.NotFoundErr)
+		advertRepo.AssertCalled(t, "RemoveImage", mock.Anything, mock.Anything, published.Images[0])
+	})
+}
+
+// firstImageResponse returns the response of the first image of the advert
+func firstImageResponse(adv advert_domain.Advert) imageResponse {
+	resp := imageResponse{}
+	resp.LoadImage(adv.Images[0])
+	return resp
+}
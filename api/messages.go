@@ -0,0 +1,527 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+)
+
+// internalErrorCode is the code of server errors written without details
+const internalErrorCode = "internal_error"
+
+// messageLanguages are the languages every error message is translated to, English is used for the other ones
+var messageLanguages = LanguageTags{English, Polish, Ukrainian}
+
+// errorMessages is the catalogue of error messages by the codes handlers pass to WriteError, English messages are usually
+// the same as the details. Messages with verbs are formatted with the same arguments in every language.
+var errorMessages = map[string]map[LanguageTag]string{
+	internalErrorCode: {
+		English:   "internal server error",
+		Polish:    "wewnętrzny błąd serwera",
+		Ukrainian: "внутрішня помилка сервера",
+	},
+	"validation_failed": {
+		English:   "validation failed",
+		Polish:    "nieprawidłowe dane",
+		Ukrainian: "некоректні дані",
+	},
+	"invalid_payload": {
+		English:   "invalid payload",
+		Polish:    "nieprawidłowe dane żądania",
+		Ukrainian: "некоректні дані запиту",
+	},
+	"auth.required": {
+		English:   "not authorized",
+		Polish:    "wymagane zalogowanie",
+		Ukrainian: "потрібно увійти в систему",
+	},
+	"language.invalid": {
+		English:   "invalid language tag",
+		Polish:    "nieprawidłowy kod języka",
+		Ukrainian: "некоректний код мови",
+	},
+	"language.unsupported": {
+		English:   "unsupported language",
+		Polish:    "nieobsługiwany język",
+		Ukrainian: "непідтримувана мова",
+	},
+
+	"user.already_exists": {
+		English:   "user already exists",
+		Polish:    "użytkownik już istnieje",
+		Ukrainian: "користувач уже існує",
+	},
+	"user.not_found": {
+		English:   "user does not exists",
+		Polish:    "użytkownik nie istnieje",
+		Ukrainian: "користувач не існує",
+	},
+	"user.deleted": {
+		English:   "user does not exists anymore",
+		Polish:    "użytkownik już nie istnieje",
+		Ukrainian: "користувач більше не існує",
+	},
+	"user.not_provided": {
+		English:   "no user provided",
+		Polish:    "nie podano użytkownika",
+		Ukrainian: "не вказано користувача",
+	},
+	"user.wrong_credentials": {
+		English:   "wrong credentials",
+		Polish:    "nieprawidłowy login lub hasło",
+		Ukrainian: "неправильний логін або пароль",
+	},
+	"user.missing_personal_data": {
+		English:   "missing personal data",
+		Polish:    "brak danych osobowych",
+		Ukrainian: "відсутні особисті дані",
+	},
+	"user.invalid_personal_data": {
+		English:   "invalid personal data",
+		Polish:    "nieprawidłowe dane osobowe",
+		Ukrainian: "некоректні особисті дані",
+	},
+	"user.missing_contact_data": {
+		English:   "missing contact data",
+		Polish:    "brak danych kontaktowych",
+		Ukrainian: "відсутні контактні дані",
+	},
+
+	"contact.missing": {
+		English:   "missing contact details",
+		Polish:    "brak danych kontaktowych",
+		Ukrainian: "відсутні контактні дані",
+	},
+	"contact.invalid": {
+		English:   "invalid contact data",
+		Polish:    "nieprawidłowe dane kontaktowe",
+		Ukrainian: "некоректні контактні дані",
+	},
+	"contact.not_found": {
+		English:   "advert has no contact details",
+		Polish:    "ogłoszenie nie ma danych kontaktowych",
+		Ukrainian: "оголошення не має контактних даних",
+	},
+	"contact.too_many_reveals": {
+		English:   "too many contacts revealed, try again later",
+		Polish:    "odkryto zbyt wiele kontaktów, spróbuj ponownie później",
+		Ukrainian: "відкрито забагато контактів, спробуйте пізніше",
+	},
+	"location.invalid": {
+		English:   "invalid location",
+		Polish:    "nieprawidłowa lokalizacja",
+		Ukrainian: "некоректне місцезнаходження",
+	},
+	"location.invalid_coordinates": {
+		English:   "invalid coordinates",
+		Polish:    "nieprawidłowe współrzędne",
+		Ukrainian: "некоректні координати",
+	},
+
+	"advert.not_found": {
+		English:   "advert not found",
+		Polish:    "nie znaleziono ogłoszenia",
+		Ukrainian: "оголошення не знайдено",
+	},
+	"advert.changed": {
+		English:   "advert has been changed in the meantime, load it again",
+		Polish:    "ogłoszenie zostało w międzyczasie zmienione, wczytaj je ponownie",
+		Ukrainian: "оголошення тим часом змінилося, завантажте його знову",
+	},
+	"advert.already_exists": {
+		English:   "advert already exists",
+		Polish:    "ogłoszenie już istnieje",
+		Ukrainian: "оголошення вже існує",
+	},
+	"advert.not_owner": {
+		English:   "not advert owner",
+		Polish:    "nie jesteś właścicielem ogłoszenia",
+		Ukrainian: "ви не власник оголошення",
+	},
+	"advert.contact_empty": {
+		English:   "contact is empty",
+		Polish:    "brak kontaktu",
+		Ukrainian: "контакт порожній",
+	},
+	"advert.missing_basic_info": {
+		English:   "advert is missing basic info",
+		Polish:    "w ogłoszeniu brakuje podstawowych informacji",
+		Ukrainian: "в оголошенні бракує основної інформації",
+	},
+	"advert.invalid_details": {
+		English:   "invalid advert details",
+		Polish:    "nieprawidłowe dane ogłoszenia",
+		Ukrainian: "некоректні дані оголошення",
+	},
+	"advert.invalid_attributes": {
+		English:   "invalid attributes",
+		Polish:    "nieprawidłowe atrybuty",
+		Ukrainian: "некоректні атрибути",
+	},
+	"advert.invalid_text": {
+		English:   "invalid multilingual text",
+		Polish:    "nieprawidłowy tekst wielojęzyczny",
+		Ukrainian: "некоректний багатомовний текст",
+	},
+	"advert.no_common_language": {
+		English:   "title and description have no common language",
+		Polish:    "tytuł i opis nie mają wspólnego języka",
+		Ukrainian: "заголовок і опис не мають спільної мови",
+	},
+	"advert.invalid_type": {
+		English:   "unknown advert type",
+		Polish:    "nieznany typ ogłoszenia",
+		Ukrainian: "невідомий тип оголошення",
+	},
+	"advert.invalid_status": {
+		English:   "unknown advert status",
+		Polish:    "nieznany status ogłoszenia",
+		Ukrainian: "невідомий статус оголошення",
+	},
+	"advert.invalid_status_transition": {
+		English:   "advert can't be moved to this status",
+		Polish:    "ogłoszenia nie można przenieść do tego statusu",
+		Ukrainian: "оголошення не можна перевести в цей статус",
+	},
+	"advert.hidden": {
+		English:   "destroyed, expired and not published adverts are visible only to their owner",
+		Polish:    "usunięte, wygasłe i nieopublikowane ogłoszenia widzi tylko ich właściciel",
+		Ukrainian: "видалені, прострочені та неопубліковані оголошення бачить лише їхній власник",
+	},
+	"moderation.hidden": {
+		English:   "destroyed and expired adverts are visible only to their owner",
+		Polish:    "usunięte i wygasłe ogłoszenia widzi tylko ich właściciel",
+		Ukrainian: "видалені та прострочені оголошення бачить лише їхній власник",
+	},
+	"moderation.not_moderator": {
+		English:   "not a moderator",
+		Polish:    "nie jesteś moderatorem",
+		Ukrainian: "ви не модератор",
+	},
+	"moderation.invalid_reason": {
+		English:   "invalid reason",
+		Polish:    "nieprawidłowy powód",
+		Ukrainian: "некоректна причина",
+	},
+	"translation.outdated": {
+		English:   "advert has been changed since it was loaded for translation",
+		Polish:    "ogłoszenie zmieniło się od czasu pobrania go do tłumaczenia",
+		Ukrainian: "оголошення змінилося після того, як його завантажили для перекладу",
+	},
+	"translation.invalid": {
+		English:   "translation must be given in supported language not available yet",
+		Polish:    "tłumaczenie musi być w obsługiwanym języku, którego jeszcze nie ma",
+		Ukrainian: "переклад має бути підтримуваною мовою, якої ще немає",
+	},
+
+	"image.not_found": {
+		English:   "image not found",
+		Polish:    "nie znaleziono zdjęcia",
+		Ukrainian: "зображення не знайдено",
+	},
+	"image.missing": {
+		English:   "missing image",
+		Polish:    "brak zdjęcia",
+		Ukrainian: "відсутнє зображення",
+	},
+	"image.too_large": {
+		English:   "image too large",
+		Polish:    "zdjęcie jest za duże",
+		Ukrainian: "зображення завелике",
+	},
+	"image.dimensions_too_large": {
+		English:   "image dimensions are too large",
+		Polish:    "wymiary zdjęcia są za duże",
+		Ukrainian: "розміри зображення завеликі",
+	},
+	"image.unsupported_type": {
+		English:   "only JPEG, PNG and WebP images are supported",
+		Polish:    "obsługiwane są tylko zdjęcia JPEG, PNG i WebP",
+		Ukrainian: "підтримуються лише зображення JPEG, PNG і WebP",
+	},
+	"image.too_many": {
+		English:   "advert can't have more than %d images",
+		Polish:    "ogłoszenie nie może mieć więcej niż %d zdjęć",
+		Ukrainian: "оголошення не може мати більше ніж %d зображень",
+	},
+
+	"list.invalid_user_id": {
+		English:   "invalid user_id",
+		Polish:    "nieprawidłowe user_id",
+		Ukrainian: "некоректний user_id",
+	},
+	"list.invalid_created_after": {
+		English:   "invalid created_after, RFC 3339 date expected",
+		Polish:    "nieprawidłowe created_after, oczekiwano daty RFC 3339",
+		Ukrainian: "некоректний created_after, очікується дата RFC 3339",
+	},
+	"list.invalid_created_before": {
+		English:   "invalid created_before, RFC 3339 date expected",
+		Polish:    "nieprawidłowe created_before, oczekiwano daty RFC 3339",
+		Ukrainian: "некоректний created_before, очікується дата RFC 3339",
+	},
+	"list.invalid_date_range": {
+		English:   "created after must be before created before",
+		Polish:    "created_after musi być wcześniejsze niż created_before",
+		Ukrainian: "created_after має бути раніше за created_before",
+	},
+	"list.invalid_include_destroyed": {
+		English:   "invalid include_destroyed",
+		Polish:    "nieprawidłowe include_destroyed",
+		Ukrainian: "некоректний include_destroyed",
+	},
+	"list.invalid_include_expired": {
+		English:   "invalid include_expired",
+		Polish:    "nieprawidłowe include_expired",
+		Ukrainian: "некоректний include_expired",
+	},
+	"list.radius_search_params": {
+		English:   "lat, lon and radius are required for radius search",
+		Polish:    "wyszukiwanie w promieniu wymaga lat, lon i radius",
+		Ukrainian: "для пошуку в радіусі потрібні lat, lon і radius",
+	},
+	"list.invalid_radius": {
+		English:   "radius must be positive and not greater than max radius",
+		Polish:    "promień musi być dodatni i nie większy niż maksymalny",
+		Ukrainian: "радіус має бути додатним і не більшим за максимальний",
+	},
+	"list.invalid_cursor": {
+		English:   "invalid cursor",
+		Polish:    "nieprawidłowy kursor",
+		Ukrainian: "некоректний курсор",
+	},
+	"list.cursor_with_query": {
+		English:   "cursor can't be used with search query",
+		Polish:    "kursora nie można używać z wyszukiwaniem",
+		Ukrainian: "курсор не можна використовувати з пошуковим запитом",
+	},
+	"list.cursor_with_offset": {
+		English:   "cursor can't be used with offset",
+		Polish:    "kursora nie można używać z offset",
+		Ukrainian: "курсор не можна використовувати з offset",
+	},
+
+	"search.query_too_long": {
+		English:   "search query too long",
+		Polish:    "zapytanie wyszukiwania jest za długie",
+		Ukrainian: "пошуковий запит задовгий",
+	},
+	"search.empty_criteria": {
+		English:   "saved search needs at least one criterion",
+		Polish:    "zapisane wyszukiwanie wymaga co najmniej jednego kryterium",
+		Ukrainian: "збережений пошук потребує принаймні одного критерію",
+	},
+	"search.too_many": {
+		English:   "can't have more than %d saved searches",
+		Polish:    "nie można mieć więcej niż %d zapisanych wyszukiwań",
+		Ukrainian: "не можна мати більше ніж %d збережених пошуків",
+	},
+	"search.not_found": {
+		English:   "saved search not found",
+		Polish:    "nie znaleziono zapisanego wyszukiwania",
+		Ukrainian: "збережений пошук не знайдено",
+	},
+	"search.matching_failed": {
+		English:   "matching saved searches failed",
+		Polish:    "nie udało się dopasować zapisanych wyszukiwań",
+		Ukrainian: "не вдалося зіставити збережені пошуки",
+	},
+	"favourite.too_many": {
+		English:   "can't have more than %d favourite adverts",
+		Polish:    "nie można mieć więcej niż %d ulubionych ogłoszeń",
+		Ukrainian: "не можна мати більше ніж %d улюблених оголошень",
+	},
+
+	"report.not_found": {
+		English:   "report not found",
+		Polish:    "nie znaleziono zgłoszenia",
+		Ukrainian: "скаргу не знайдено",
+	},
+	"report.invalid_reason": {
+		English:   "invalid reason",
+		Polish:    "nieprawidłowy powód",
+		Ukrainian: "некоректна причина",
+	},
+	"report.invalid_details": {
+		English:   "invalid details",
+		Polish:    "nieprawidłowe szczegóły",
+		Ukrainian: "некоректні подробиці",
+	},
+	"report.invalid_resolution": {
+		English:   "invalid resolution",
+		Polish:    "nieprawidłowe rozstrzygnięcie",
+		Ukrainian: "некоректне рішення",
+	},
+	"report.invalid_all": {
+		English:   "invalid all",
+		Polish:    "nieprawidłowe all",
+		Ukrainian: "некоректний all",
+	},
+	"report.invalid_advert_id": {
+		English:   "invalid advert_id",
+		Polish:    "nieprawidłowe advert_id",
+		Ukrainian: "некоректний advert_id",
+	},
+	"report.already_resolved": {
+		English:   "report already resolved",
+		Polish:    "zgłoszenie zostało już rozpatrzone",
+		Ukrainian: "скаргу вже розглянуто",
+	},
+	"report.already_reported": {
+		English:   "advert already reported",
+		Polish:    "ogłoszenie zostało już zgłoszone",
+		Ukrainian: "на це оголошення вже надіслано скаргу",
+	},
+	"report.too_many": {
+		English:   "too many reports, try again later",
+		Polish:    "zbyt wiele zgłoszeń, spróbuj ponownie później",
+		Ukrainian: "забагато скарг, спробуйте пізніше",
+	},
+
+	"conversation.not_found": {
+		English:   "conversation not found",
+		Polish:    "nie znaleziono rozmowy",
+		Ukrainian: "розмову не знайдено",
+	},
+	"conversation.cannot_start": {
+		English:   "can't start conversation about this advert",
+		Polish:    "nie można rozpocząć rozmowy o tym ogłoszeniu",
+		Ukrainian: "не можна почати розмову про це оголошення",
+	},
+	"conversation.not_owner": {
+		English:   "only the advert owner can block the conversation",
+		Polish:    "tylko właściciel ogłoszenia może zablokować rozmowę",
+		Ukrainian: "лише власник оголошення може заблокувати розмову",
+	},
+	"conversation.blocked": {
+		English:   "you have been blocked by the advert owner",
+		Polish:    "właściciel ogłoszenia zablokował cię",
+		Ukrainian: "власник оголошення заблокував вас",
+	},
+	"conversation.invalid_message": {
+		English:   "message is required and can't be longer than %d characters",
+		Polish:    "wiadomość jest wymagana i nie może być dłuższa niż %d znaków",
+		Ukrainian: "повідомлення обов'язкове і не може бути довшим за %d символів",
+	},
+}
+
+// domainErrorCodes gives the codes of the domain errors, they are the codes of the details the handlers write for them.
+// Errors are matched in order, validation errors match the errors of all their fields, so the first field decides.
+var domainErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{user.MissingPersonalDataErr, "user.missing_personal_data"},
+	{user.InvalidPersonalDataErr, "user.invalid_personal_data"},
+	{user.MissingContactDataErr, "user.missing_contact_data"},
+	{user.InvalidLanguageErr, "language.unsupported"},
+	{domain.InvalidDataErr, "contact.invalid"},
+	{domain.InvalidLocationErr, "location.invalid"},
+	{domain.InvalidCoordinatesErr, "location.invalid_coordinates"},
+	{domain.InvalidAttributesErr, "advert.invalid_attributes"},
+	{InvalidTextErr, "advert.invalid_text"},
+	{InvalidLanguageTagErr, "language.invalid"},
+
+	{advert.NoUserProvidedErr, "user.not_provided"},
+	{advert.ContactEmptyErr, "advert.contact_empty"},
+	{advert.MissingBasicInfoErr, "advert.missing_basic_info"},
+	{advert.InvalidLanguages, "advert.no_common_language"},
+	{advert.NotAdvertOwnerErr, "advert.not_owner"},
+	{advert.AdvertAlreadyExists, "advert.already_exists"},
+	{advert.AdvertNotFound, "advert.not_found"},
+	{advert.AdvertChangedErr, "advert.changed"},
+	{advert.InvalidAdvertTypeErr, "advert.invalid_type"},
+	{advert.InvalidStatusErr, "advert.invalid_status"},
+	{advert.InvalidStatusTransitionErr, "advert.invalid_status_transition"},
+	{advert.NotModeratorErr, "moderation.not_moderator"},
+	{advert.InvalidModerationReasonErr, "moderation.invalid_reason"},
+	{advert.TranslationOutdatedErr, "translation.outdated"},
+	{advert.InvalidTranslationErr, "translation.invalid"},
+	{advert.TooManyImagesErr, "image.too_many"},
+	{advert.ImageNotFound, "image.not_found"},
+	{advert.TooManyContactRevealsErr, "contact.too_many_reveals"},
+	{advert.InvalidDateRangeErr, "list.invalid_date_range"},
+	{advert.InvalidRadiusErr, "list.invalid_radius"},
+	{advert.InvalidCursorErr, "list.invalid_cursor"},
+	{advert.CursorWithQueryErr, "list.cursor_with_query"},
+	{advert.CursorWithOffsetErr, "list.cursor_with_offset"},
+
+	{search.NoUserProvidedErr, "user.not_provided"},
+	{search.EmptyCriteriaErr, "search.empty_criteria"},
+	{search.QueryTooLongErr, "search.query_too_long"},
+	{search.InvalidLanguageErr, "language.unsupported"},
+	{search.TooManySearchesErr, "search.too_many"},
+	{search.SearchNotFound, "search.not_found"},
+	{search.MatchingFailedErr, "search.matching_failed"},
+	{favourite.NoUserProvidedErr, "user.not_provided"},
+	{favourite.TooManyFavouritesErr, "favourite.too_many"},
+
+	{report.InvalidReasonErr, "report.invalid_reason"},
+	{report.InvalidDetailsErr, "report.invalid_details"},
+	{report.InvalidResolutionErr, "report.invalid_resolution"},
+	{report.NotModeratorErr, "moderation.not_moderator"},
+	{report.AlreadyResolvedErr, "report.already_resolved"},
+	{report.AlreadyReportedErr, "report.already_reported"},
+	{report.TooManyReportsErr, "report.too_many"},
+	{report.ReportNotFound, "report.not_found"},
+
+	{conversation.NoUserProvidedErr, "user.not_provided"},
+	{conversation.NoAdvertOwnerErr, "conversation.cannot_start"},
+	{conversation.OwnAdvertErr, "conversation.cannot_start"},
+	{conversation.NotParticipantErr, "conversation.not_found"},
+	{conversation.NotOwnerErr, "conversation.not_owner"},
+	{conversation.BlockedErr, "conversation.blocked"},
+	{conversation.InvalidMessageErr, "conversation.invalid_message"},
+	{conversation.ConversationNotFound, "conversation.not_found"},
+
+	{validation.InvalidErr, "validation_failed"},
+}
+
+// errorCode returns the code of the first domain error matched by err, empty code is returned for not listed errors
+func errorCode(err error) string {
+	var filterErr invalidFilterErr
+	if errors.As(err, &filterErr) {
+		return filterErr.code
+	}
+	for _, domainErr := range domainErrorCodes {
+		if errors.Is(err, domainErr.err) {
+			return domainErr.code
+		}
+	}
+	return ""
+}
+
+// localizedMessage returns the message of the code in the language, English message is returned if it's not translated.
+// ok is false when the code is not in the catalogue.
+func localizedMessage(code string, language LanguageTag, args ...interface{}) (message string, ok bool) {
+	messages, ok := errorMessages[code]
+	if !ok {
+		return "", false
+	}
+	message, ok = messages[language]
+	if !ok {
+		message = messages[English]
+	}
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	return message, true
+}
+
+// messageLanguage picks the first of the preferred languages the messages are translated to, English is used when there is none
+func messageLanguage(preferred LanguageTags) LanguageTag {
+	for _, language := range preferred {
+		if messageLanguages.Contains(language) {
+			return language
+		}
+	}
+	return English
+}
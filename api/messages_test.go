@@ -0,0 +1,77 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestErrorMessages(t *testing.T) {
+	for code, messages := range errorMessages {
+		for _, language := range messageLanguages {
+			message := messages[language]
+			assert.NotEmpty(t, message, "%s has no %s message", code, language)
+			// the same arguments are put into every translation
+			assert.Equal(t, strings.Count(messages[English], "%"), strings.Count(message, "%"), "%s %s", code, language)
+		}
+	}
+
+	for _, domainErr := range domainErrorCodes {
+		assert.Contains(t, errorMessages, domainErr.code, domainErr.err.Error())
+	}
+}
+
+// TestErrorCodesInCatalogue checks the codes handlers pass to the error writers, a typo would leave the error without a message
+func TestErrorCodesInCatalogue(t *testing.T) {
+	packages, err := parser.ParseDir(token.NewFileSet(), ".", nil, 0)
+	require.NoError(t, err)
+
+	checked := 0
+	for _, file := range packages["api"].Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			function, ok := call.Fun.(*ast.Ident)
+			if !ok {
+				return true
+			}
+
+			var code ast.Expr
+			switch function.Name {
+			case "WriteError", "WriteErrorf":
+				code = call.Args[2]
+			case "WriteValidationError":
+				code = call.Args[1]
+			default:
+				return true
+			}
+			literal, ok := code.(*ast.BasicLit)
+			if !ok {
+				return true
+			}
+			value, err := strconv.Unquote(literal.Value)
+			require.NoError(t, err)
+			if value != "" {
+				assert.Contains(t, errorMessages, value)
+				checked++
+			}
+			return true
+		})
+	}
+	assert.NotZero(t, checked)
+}
+
+func TestMessageLanguage(t *testing.T) {
+	assert.Equal(t, English, messageLanguage(nil))
+	assert.Equal(t, Ukrainian, messageLanguage(LanguageTags{Ukrainian, Polish}))
+	assert.Equal(t, Polish, messageLanguage(LanguageTags{"de", Polish}))
+	assert.Equal(t, English, messageLanguage(LanguageTags{"de"}))
+}
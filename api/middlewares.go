@@ -2,10 +2,13 @@ package api
 
 import (
 	"context"
+	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	log "github.com/sirupsen/logrus"
 	application "github.com/ukrainian-brothers/board-backend/app"
 	"github.com/ukrainian-brothers/board-backend/internal/common"
+	"github.com/ukrainian-brothers/board-backend/pkg/clientip"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
 	"net/http"
 	"runtime/debug"
 	"time"
@@ -35,7 +38,7 @@ func (p MiddlewareProvider) AuthMiddleware(next http.HandlerFunc, logger *log.En
 			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "user_login", session.Values["user_login"].(string))))
 			return
 		}
-		
+
 		next.ServeHTTP(w, r)
 	}
 }
@@ -68,11 +71,71 @@ func (p MiddlewareProvider) LoggingMiddleware(logger *log.Entry) func(http.Handl
 	}
 }
 
+// BodyLimitMiddleware limits JSON payloads to 1 MiB, only image uploads are allowed to be larger
 func (p MiddlewareProvider) BodyLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "POST" || r.Method == "PUT" {
-			r.Body = http.MaxBytesReader(w, r.Body, 1048576)
+		if r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH" {
+			limit := int64(1048576)
+			if route := mux.CurrentRoute(r); route != nil && route.GetName() == uploadImageRoute {
+				limit = maxImageSize(p.cfg) + multipartOverhead
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
 		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+// ClientIPMiddleware puts the address of the client into the context, requests forwarded by trusted proxies get the address the proxy reported
+func (p MiddlewareProvider) ClientIPMiddleware(resolver clientip.Resolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "client_ip", resolver.ClientIP(r))))
+		})
+	}
+}
+
+// languageWriter is implemented by the response writer of LanguageMiddleware
+type languageWriter interface {
+	Language() LanguageTag
+}
+
+// languageResponseWriter resolves the language of the user only when it's asked for, so responses without errors don't pay for it
+type languageResponseWriter struct {
+	http.ResponseWriter
+	resolve  func() LanguageTag
+	language LanguageTag
+}
+
+func (w *languageResponseWriter) Language() LanguageTag {
+	if w.language == "" {
+		w.language = w.resolve()
+	}
+	return w.language
+}
+
+// LanguageMiddleware lets the error messages be written in the language of the user, it has to be used last, so the handlers get its writer.
+// The language chosen by the signed in user goes first, then the ones from Accept-Language header, English is used when none of them is translated.
+func (p MiddlewareProvider) LanguageMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resolve := func() LanguageTag {
+			preferred := append(p.userLanguage(r), ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+			return messageLanguage(preferred)
+		}
+		next.ServeHTTP(&languageResponseWriter{ResponseWriter: w, resolve: resolve}, r)
+	})
+}
+
+// userLanguage returns the language chosen by the signed in user if there is any, it's stored in the session by Login and SetLanguage.
+// The session is read on its own as AuthMiddleware wraps only the handlers.
+func (p MiddlewareProvider) userLanguage(r *http.Request) LanguageTags {
+	session, err := p.sessionStore.Get(r, p.cfg.Session.SessionKey)
+	if err != nil {
+		return nil
+	}
+
+	language, ok := session.Values["user_language"].(string)
+	if !ok || language == "" {
+		return nil
+	}
+	return LanguageTags{LanguageTag(language)}
+}
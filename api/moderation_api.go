@@ -0,0 +1,218 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	application "github.com/ukrainian-brothers/board-backend/app"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"net/http"
+)
+
+type ModerationAPI struct {
+	log    *logrus.Entry
+	router *mux.Router
+	app    application.Application
+}
+
+func NewModerationAPI(r *mux.Router, log *logrus.Entry, app application.Application, middleware *MiddlewareProvider) *ModerationAPI {
+	moderationApi := ModerationAPI{router: r, app: app, log: log}
+	r.HandleFunc("/api/moderation/adverts", middleware.AuthMiddleware(moderationApi.Queue, log)).Methods("GET")
+	r.HandleFunc("/api/moderation/adverts/{id}/approve", middleware.AuthMiddleware(moderationApi.ApproveAdvert, log)).Methods("POST")
+	r.HandleFunc("/api/moderation/adverts/{id}/reject", middleware.AuthMiddleware(moderationApi.RejectAdvert, log)).Methods("POST")
+	r.HandleFunc("/api/moderation/adverts/{id}/suspend", middleware.AuthMiddleware(moderationApi.SuspendAdvert, log)).Methods("POST")
+	return &moderationApi
+}
+
+type moderationPayload struct {
+	Reason string `json:"reason"`
+}
+
+// requireModerator returns the logged in moderator, the error response is already written when nil is returned
+func requireModerator(w http.ResponseWriter, r *http.Request, app application.Application, log *logrus.Entry) *user.User {
+	ctx := r.Context()
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to moderate adverts")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return nil
+	}
+
+	usr, err := app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to moderate adverts")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return nil
+		}
+		log.WithError(err).Error("failed getting moderator by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return nil
+	}
+
+	if !usr.IsModerator() {
+		log.Info("user without moderator role tries to moderate adverts")
+		WriteError(w, http.StatusForbidden, "moderation.not_moderator", "not a moderator")
+		return nil
+	}
+	return usr
+}
+
+// Queue lists adverts waiting for the review, other statuses can be requested with the same params as the adverts list
+func (m ModerationAPI) Queue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := m.log
+
+	usr := requireModerator(w, r, m.app, log)
+	if usr == nil {
+		return
+	}
+	log = log.WithField("user_login", usr.Login)
+
+	filter, err := parseListFilter(r)
+	if err != nil {
+		WriteDomainError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if len(filter.Statuses) == 0 {
+		filter.Statuses = []advert.Status{advert.StatusPending}
+	}
+
+	if !filter.CanBeUsedBy(usr) {
+		log.Info("moderator tries to list destroyed or expired adverts")
+		WriteError(w, http.StatusForbidden, "moderation.hidden", "destroyed and expired adverts are visible only to their owner")
+		return
+	}
+
+	adverts, err := m.app.Queries.GetAdvertsList.Execute(ctx, filter)
+	if err != nil {
+		if isInvalidFilter(err) {
+			WriteDomainError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		log.WithError(err).Error("Queue failed while fetching list of adverts")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if filter.Query == "" && len(adverts) > 0 && len(adverts) == filter.Limit {
+		w.Header().Set(NextCursorHeader, advert.CursorOf(adverts[len(adverts)-1]).String())
+	}
+
+	response := []advertResponse{}
+	for _, adv := range adverts {
+		advResponse := advertResponse{}
+		advResponse.LoadAdvert(adv)
+		response = append(response, advResponse)
+	}
+
+	WriteJSON(w, 200, response)
+}
+
+func (m ModerationAPI) ApproveAdvert(w http.ResponseWriter, r *http.Request) {
+	m.moderate(w, r, "approve", func(adv *advert.Advert, moderator *user.User, _ string) error {
+		return adv.Approve(moderator)
+	})
+}
+
+func (m ModerationAPI) RejectAdvert(w http.ResponseWriter, r *http.Request) {
+	m.moderate(w, r, "reject", func(adv *advert.Advert, moderator *user.User, reason string) error {
+		return adv.Reject(moderator, reason)
+	})
+}
+
+func (m ModerationAPI) SuspendAdvert(w http.ResponseWriter, r *http.Request) {
+	m.moderate(w, r, "suspend", func(adv *advert.Advert, moderator *user.User, reason string) error {
+		return adv.Suspend(moderator, reason)
+	})
+}
+
+// moderate loads the advert, applies the decision of the moderator and persists it
+func (m ModerationAPI) moderate(w http.ResponseWriter, r *http.Request, action string, decide func(adv *advert.Advert, moderator *user.User, reason string) error) {
+	ctx := r.Context()
+	log := m.log.WithFields(logrus.Fields{
+		"advert_id": mux.Vars(r)["id"],
+		"action":    action,
+	})
+
+	usr := requireModerator(w, r, m.app, log)
+	if usr == nil {
+		return
+	}
+	log = log.WithField("user_login", usr.Login)
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	// approval needs no reason, so the body may be empty
+	payload := moderationPayload{}
+	if r.ContentLength != 0 {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&payload)
+		if err != nil {
+			log.WithError(err).Error("failed decoding moderation payload")
+			WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+			return
+		}
+	}
+
+	adv, err := m.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("moderate failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = decide(&adv, usr, payload.Reason)
+	if err != nil {
+		if errors.Is(err, advert.InvalidStatusTransitionErr) {
+			WriteError(w, http.StatusConflict, "advert.invalid_status_transition", "advert can't be moved to this status")
+			return
+		}
+		if errors.Is(err, advert.InvalidModerationReasonErr) {
+			WriteError(w, http.StatusUnprocessableEntity, "moderation.invalid_reason", "invalid reason")
+			return
+		}
+		log.WithError(err).Error("moderate failed changing advert status")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = m.app.Commands.ModerateAdvert.Execute(ctx, &adv)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		if errors.Is(err, advert.AdvertChangedErr) {
+			WriteError(w, http.StatusConflict, "advert.changed", "advert has been changed in the meantime, load it again")
+			return
+		}
+		if !errors.Is(err, search.MatchingFailedErr) {
+			log.WithError(err).Error("moderate failed updating advert in repository")
+			WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+			return
+		}
+		// the decision is stored, only the notifications about the approved advert are missed
+		log.WithError(err).Error("moderate failed matching saved searches")
+	}
+
+	response := advertResponse{}
+	response.LoadAdvert(&adv)
+	WriteJSON(w, 200, response)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
+	user_domain "github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"net/http"
+	"testing"
+)
+
+func TestModerationQueue(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	moderator := &user_domain.User{ID: uuid.New(), Login: "moderator", Role: user_domain.RoleModerator}
+	for _, usr := range []*user_domain.User{owner, moderator} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+	advertRepo.On("GetList", mock.Anything, mock.Anything).Return([]*advert_domain.Advert{}, nil)
+
+	type testCase struct {
+		name           string
+		user           *user_domain.User
+		query          string
+		expectedStatus int
+		expectedFilter *advert_domain.ListFilter
+	}
+
+	testCases := []testCase{
+		{name: "not authorised", expectedStatus: http.StatusForbidden},
+		{name: "not a moderator", user: owner, expectedStatus: http.StatusForbidden},
+		{
+			name:           "pending by default",
+			user:           moderator,
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				Statuses: []advert_domain.Status{advert_domain.StatusPending},
+				Limit:    MaxAdvertsInResponse,
+			},
+		},
+		{
+			name:           "suspended adverts",
+			user:           moderator,
+			query:          "?status=suspended&limit=10",
+			expectedStatus: http.StatusOK,
+			expectedFilter: &advert_domain.ListFilter{
+				Statuses: []advert_domain.Status{advert_domain.StatusSuspended},
+				Limit:    10,
+			},
+		},
+		{name: "destroyed adverts", user: moderator, query: "?include_destroyed=true", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			advertRepo.Calls = nil
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/moderation/adverts%s", server.URL, tC.query), nil, nil, cookies)
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			if tC.expectedFilter == nil {
+				advertRepo.AssertNotCalled(t, "GetList", mock.Anything, mock.Anything)
+				return
+			}
+			advertRepo.AssertCalled(t, "GetList", mock.Anything, *tC.expectedFilter)
+		})
+	}
+}
+
+func TestModerateAdvert(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	owner := &user_domain.User{ID: uuid.New(), Login: "owner", Role: user_domain.RoleUser}
+	moderator := &user_domain.User{ID: uuid.New(), Login: "moderator", Role: user_domain.RoleModerator}
+	for _, usr := range []*user_domain.User{owner, moderator} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	advertWithStatus := func(status advert_domain.Status) advert_domain.Advert {
+		return advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{English: "title"},
+				Description: MultilingualString{English: "description"},
+				Type:        domain.AdvertTypeTransport,
+			},
+			User:   owner,
+			Status: status,
+		}
+	}
+	pending := advertWithStatus(advert_domain.StatusPending)
+	published := advertWithStatus(advert_domain.StatusPublished)
+	for _, adv := range []advert_domain.Advert{pending, published} {
+		advertRepo.On("Get", mock.Anything, adv.ID).Return(adv, nil)
+	}
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	advertRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	type testCase struct {
+		name           string
+		user           *user_domain.User
+		advertID       uuid.UUID
+		action         string
+		payload        interface{}
+		expectedStatus int
+		expectedAdvert advert_domain.Status
+	}
+
+	testCases := []testCase{
+		{name: "not authorised", advertID: pending.ID, action: "approve", expectedStatus: http.StatusForbidden},
+		{name: "not a moderator", user: owner, advertID: pending.ID, action: "approve", expectedStatus: http.StatusForbidden},
+		{name: "advert not found", user: moderator, advertID: uuid.New(), action: "approve", expectedStatus: http.StatusNotFound},
+		{
+			name:           "approve",
+			user:           moderator,
+			advertID:       pending.ID,
+			action:         "approve",
+			expectedStatus: http.StatusOK,
+			expectedAdvert: advert_domain.StatusPublished,
+		},
+		{
+			name:           "reject without reason",
+			user:           moderator,
+			advertID:       pending.ID,
+			action:         "reject",
+			payload:        moderationPayload{},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "reject",
+			user:           moderator,
+			advertID:       pending.ID,
+			action:         "reject",
+			payload:        moderationPayload{Reason: "missing route"},
+			expectedStatus: http.StatusOK,
+			expectedAdvert: advert_domain.StatusRejected,
+		},
+		{
+			name:           "suspend pending",
+			user:           moderator,
+			advertID:       pending.ID,
+			action:         "suspend",
+			payload:        moderationPayload{Reason: "scam"},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "suspend published",
+			user:           moderator,
+			advertID:       published.ID,
+			action:         "suspend",
+			payload:        moderationPayload{Reason: "scam"},
+			expectedStatus: http.StatusOK,
+			expectedAdvert: advert_domain.StatusSuspended,
+		},
+		{
+			name:           "unknown field",
+			user:           moderator,
+			advertID:       pending.ID,
+			action:         "reject",
+			payload:        map[string]string{"comment": "missing route"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			response := advertResponse{}
+			resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/moderation/adverts/%s/%s", server.URL, tC.advertID, tC.action), tC.payload, &response, cookies)
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			if tC.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tC.expectedAdvert, response.Status)
+		})
+	}
+	advertRepo.AssertNumberOfCalls(t, "Update", 3)
+}
+
+func TestAddAdvertAutoPublish(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.AddAdvert = board.NewAddAdvert(&advertRepo, app.Commands.AddAdvert.SearchRepo, advert_domain.ModerationPolicy{AutoPublishTrusted: true})
+	server, client, sessionStore := createTestServer(t, app)
+
+	contactDetails := user.GetValidContactDetails()
+	newbie := &user_domain.User{ID: uuid.New(), Login: "newbie", Role: user_domain.RoleUser, ContactDetails: contactDetails}
+	trusted := &user_domain.User{ID: uuid.New(), Login: "trusted", Role: user_domain.RoleUser, ContactDetails: contactDetails, Trusted: true}
+	for _, usr := range []*user_domain.User{newbie, trusted} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+	advertRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+	payload := newAdvertPayload{
+		Title:          MultilingualString{English: "title"},
+		Description:    MultilingualString{English: "description"},
+		Type:           domain.AdvertTypeTransport,
+		ContactDetails: contactPayload{Mail: *contactDetails.Mail},
+	}
+
+	for usr, expected := range map[*user_domain.User]advert_domain.Status{
+		newbie:  advert_domain.StatusPending,
+		trusted: advert_domain.StatusPublished,
+	} {
+		response := advertResponse{}
+		resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts", server.URL), payload, &response, user.CreateTestSession(t, usr, sessionStore))
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, expected, response.Status, usr.Login)
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	application "github.com/ukrainian-brothers/board-backend/app"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ReportAPI struct {
+	log    *logrus.Entry
+	router *mux.Router
+	app    application.Application
+}
+
+func NewReportAPI(r *mux.Router, log *logrus.Entry, app application.Application, middleware *MiddlewareProvider) *ReportAPI {
+	reportApi := ReportAPI{router: r, app: app, log: log}
+	r.HandleFunc("/api/adverts/{id}/reports", middleware.AuthMiddleware(reportApi.ReportAdvert, log)).Methods("POST")
+	r.HandleFunc("/api/moderation/reports", middleware.AuthMiddleware(reportApi.ReportsList, log)).Methods("GET")
+	r.HandleFunc("/api/moderation/reports/{id}/resolve", middleware.AuthMiddleware(reportApi.ResolveReport, log)).Methods("POST")
+	return &reportApi
+}
+
+const MaxReportsInResponse = 50
+
+type reportPayload struct {
+	Reason  report.Reason `json:"reason"`
+	Details string        `json:"details"`
+}
+
+type resolveReportPayload struct {
+	Resolution report.Resolution `json:"resolution"`
+}
+
+type reportResponse struct {
+	ID         string            `json:"id"`
+	AdvertID   string            `json:"advert_id"`
+	ReporterID *uuid.UUID        `json:"reporter_id,omitempty"`
+	Reason     report.Reason     `json:"reason"`
+	Details    string            `json:"details,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+	ResolvedBy *uuid.UUID        `json:"resolved_by,omitempty"`
+	Resolution report.Resolution `json:"resolution,omitempty"`
+}
+
+func (resp *reportResponse) LoadReport(rep report.Report) {
+	resp.ID = rep.ID.String()
+	resp.AdvertID = rep.AdvertID.String()
+	resp.ReporterID = rep.ReporterID
+	resp.Reason = rep.Reason
+	resp.Details = rep.Details
+	resp.CreatedAt = rep.CreatedAt
+	resp.ResolvedAt = rep.ResolvedAt
+	resp.ResolvedBy = rep.ResolvedBy
+	resp.Resolution = rep.Resolution
+}
+
+// ReportAdvert accepts reports from anyone, anonymous reporters are identified by the IP address to limit and deduplicate them
+func (a ReportAPI) ReportAdvert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log.WithField("advert_id", mux.Vars(r)["id"])
+
+	advertID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	payload := reportPayload{}
+	err = dec.Decode(&payload)
+	if err != nil {
+		log.WithError(err).Error("failed decoding report payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+
+	usr, err := sessionUser(r, a.app)
+	if err != nil {
+		log.WithError(err).Error("ReportAdvert failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+	if usr != nil {
+		log = log.WithField("user_login", usr.Login)
+	}
+
+	adv, err := a.app.Queries.GetAdvert.Execute(ctx, advertID)
+	if err != nil {
+		if isAdvertNotFound(err) {
+			WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+			return
+		}
+		log.WithError(err).Error("ReportAdvert failed getting advert")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	if !adv.CanBeViewedBy(usr) {
+		WriteError(w, http.StatusNotFound, "advert.not_found", "advert not found")
+		return
+	}
+
+	rep, err := report.NewReport(adv.ID, usr, clientIP(r), payload.Reason, payload.Details)
+	if err != nil {
+		if errors.Is(err, report.InvalidReasonErr) {
+			WriteError(w, http.StatusUnprocessableEntity, "report.invalid_reason", "invalid reason")
+			return
+		}
+		WriteError(w, http.StatusUnprocessableEntity, "report.invalid_details", "invalid details")
+		return
+	}
+
+	err = a.app.Commands.ReportAdvert.Execute(ctx, rep, viewerKey(r))
+	if err != nil {
+		if errors.Is(err, report.TooManyReportsErr) {
+			log.Info("reporter exceeded the reports limit")
+			WriteError(w, http.StatusTooManyRequests, "report.too_many", "too many reports, try again later")
+			return
+		}
+		if errors.Is(err, report.AlreadyReportedErr) {
+			WriteError(w, http.StatusConflict, "report.already_reported", "advert already reported")
+			return
+		}
+		log.WithError(err).Error("ReportAdvert failed adding report")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := reportResponse{}
+	response.LoadReport(*rep)
+	WriteJSON(w, 201, response)
+}
+
+// ReportsList returns open reports from the oldest one, ?all=true lists resolved ones as well
+func (a ReportAPI) ReportsList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	usr := requireModerator(w, r, a.app, log)
+	if usr == nil {
+		return
+	}
+	log = log.WithField("user_login", usr.Login)
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil || limit <= 0 || limit > MaxReportsInResponse {
+		limit = MaxReportsInResponse
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	filter := report.ListFilter{OnlyOpen: true, Limit: limit, Offset: offset}
+	if all := r.FormValue("all"); all != "" {
+		includeResolved, err := strconv.ParseBool(all)
+		if err != nil {
+			WriteError(w, http.StatusUnprocessableEntity, "report.invalid_all", "invalid all")
+			return
+		}
+		filter.OnlyOpen = !includeResolved
+	}
+
+	if advertID := r.FormValue("advert_id"); advertID != "" {
+		id, err := uuid.Parse(advertID)
+		if err != nil {
+			WriteError(w, http.StatusUnprocessableEntity, "report.invalid_advert_id", "invalid advert_id")
+			return
+		}
+		filter.AdvertID = &id
+	}
+
+	reports, err := a.app.Queries.GetReports.Execute(ctx, filter)
+	if err != nil {
+		log.WithError(err).Error("ReportsList failed while fetching list of reports")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := []reportResponse{}
+	for _, rep := range reports {
+		repResponse := reportResponse{}
+		repResponse.LoadReport(rep)
+		response = append(response, repResponse)
+	}
+
+	WriteJSON(w, 200, response)
+}
+
+// ResolveReport closes the report, the reported advert itself is moderated with the moderation endpoints
+func (a ReportAPI) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log.WithField("report_id", mux.Vars(r)["id"])
+
+	usr := requireModerator(w, r, a.app, log)
+	if usr == nil {
+		return
+	}
+	log = log.WithField("user_login", usr.Login)
+
+	reportID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "report.not_found", "report not found")
+		return
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	payload := resolveReportPayload{}
+	err = dec.Decode(&payload)
+	if err != nil {
+		log.WithError(err).Error("failed decoding resolveReport payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+
+	rep, err := a.app.Queries.GetReport.Execute(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, report.ReportNotFound) {
+			WriteError(w, http.StatusNotFound, "report.not_found", "report not found")
+			return
+		}
+		log.WithError(err).Error("ResolveReport failed getting report")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = rep.Resolve(usr, payload.Resolution)
+	if err != nil {
+		if errors.Is(err, report.InvalidResolutionErr) {
+			WriteError(w, http.StatusUnprocessableEntity, "report.invalid_resolution", "invalid resolution")
+			return
+		}
+		if errors.Is(err, report.AlreadyResolvedErr) {
+			WriteError(w, http.StatusConflict, "report.already_resolved", "report already resolved")
+			return
+		}
+		log.WithError(err).Error("ResolveReport failed resolving report")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.ResolveReport.Execute(ctx, &rep)
+	if err != nil {
+		if errors.Is(err, report.AlreadyResolvedErr) {
+			WriteError(w, http.StatusConflict, "report.already_resolved", "report already resolved")
+			return
+		}
+		log.WithError(err).Error("ResolveReport failed updating report in repository")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := reportResponse{}
+	response.LoadReport(rep)
+	WriteJSON(w, 200, response)
+}
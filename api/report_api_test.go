@@ -0,0 +1,260 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
+	report_domain "github.com/ukrainian-brothers/board-backend/domain/report"
+	user_domain "github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal/report"
+	"github.com/ukrainian-brothers/board-backend/internal/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestReportAdvert(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	reportRepo := report.RepositoryMock{}
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.ReportAdvert = board.NewReportAdvert(&reportRepo, &advertRepo, 2, 2, time.Hour)
+	server, client, sessionStore := createTestServer(t, app)
+
+	reporter := &user_domain.User{ID: uuid.New(), Login: "reporter", Role: user_domain.RoleUser}
+	userRepo.On("GetByLogin", mock.Anything, reporter.Login).Return(reporter, nil)
+
+	newAdvert := func(status advert_domain.Status) advert_domain.Advert {
+		return advert_domain.Advert{
+			ID: uuid.New(),
+			Details: domain.AdvertDetails{
+				Title:       MultilingualString{English: "title"},
+				Description: MultilingualString{English: "description"},
+				Type:        domain.AdvertTypeTransport,
+			},
+			User:   &user_domain.User{ID: uuid.New()},
+			Status: status,
+		}
+	}
+	published := newAdvert(advert_domain.StatusPublished)
+	reported := newAdvert(advert_domain.StatusPublished)
+	pending := newAdvert(advert_domain.StatusPending)
+	for _, adv := range []advert_domain.Advert{published, reported, pending} {
+		advertRepo.On("Get", mock.Anything, adv.ID).Return(adv, nil)
+	}
+	advertRepo.On("Get", mock.Anything, mock.Anything).Return(advert_domain.Advert{}, fmt.Errorf("x: %w", sql.ErrNoRows))
+	isHidden := mock.MatchedBy(func(adv *advert_domain.Advert) bool {
+		return adv.ID == reported.ID && adv.Status == advert_domain.StatusSuspended
+	})
+	// the owner changes the advert while it's being hidden, so it's loaded and hidden again
+	advertRepo.On("Update", mock.Anything, isHidden).Return(advert_domain.AdvertChangedErr).Once()
+	advertRepo.On("Update", mock.Anything, isHidden).Return(nil)
+
+	reportRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+	hasOpen := func(advertID uuid.UUID, anonymous bool) interface{} {
+		return mock.MatchedBy(func(rep report_domain.Report) bool {
+			if anonymous {
+				return rep.AdvertID == advertID && rep.ReporterID == nil && rep.ReporterIP != ""
+			}
+			return rep.AdvertID == advertID && rep.ReporterID != nil && *rep.ReporterID == reporter.ID
+		})
+	}
+	reportRepo.On("HasOpen", mock.Anything, hasOpen(published.ID, false)).Return(true, nil)
+	// the first anonymous report of the address is accepted, the following ones are duplicates
+	reportRepo.On("HasOpen", mock.Anything, hasOpen(published.ID, true)).Return(false, nil).Once()
+	reportRepo.On("HasOpen", mock.Anything, hasOpen(published.ID, true)).Return(true, nil)
+	reportRepo.On("HasOpen", mock.Anything, mock.Anything).Return(false, nil)
+	reportRepo.On("CountOpenReporters", mock.Anything, reported.ID).Return(2, nil)
+	reportRepo.On("CountOpenReporters", mock.Anything, mock.Anything).Return(1, nil)
+
+	type testCase struct {
+		name           string
+		user           *user_domain.User
+		advertID       uuid.UUID
+		payload        interface{}
+		expectedStatus int
+	}
+
+	testCases := []testCase{
+		{
+			name:           "anonymous report",
+			advertID:       published.ID,
+			payload:        reportPayload{Reason: report_domain.ReasonScam},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unknown reason",
+			advertID:       published.ID,
+			payload:        reportPayload{Reason: "boring"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "other without details",
+			advertID:       published.ID,
+			payload:        reportPayload{Reason: report_domain.ReasonOther},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "advert not found",
+			advertID:       uuid.New(),
+			payload:        reportPayload{Reason: report_domain.ReasonScam},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "not published advert",
+			advertID:       pending.ID,
+			payload:        reportPayload{Reason: report_domain.ReasonScam},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "already reported by the user",
+			user:           reporter,
+			advertID:       published.ID,
+			payload:        reportPayload{Reason: report_domain.ReasonScam},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "advert hidden after threshold",
+			user:           reporter,
+			advertID:       reported.ID,
+			payload:        reportPayload{Reason: report_domain.ReasonTrafficking, Details: "asks for passport"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "already reported from the same address",
+			advertID:       published.ID,
+			payload:        reportPayload{Reason: report_domain.ReasonSpam},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "anonymous reporter over the limit",
+			advertID:       published.ID,
+			payload:        reportPayload{Reason: report_domain.ReasonSpam},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tC.user != nil {
+				cookies = user.CreateTestSession(t, tC.user, sessionStore)
+			}
+
+			resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/adverts/%s/reports", server.URL, tC.advertID), tC.payload, nil, cookies)
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+		})
+	}
+	reportRepo.AssertNumberOfCalls(t, "Add", 2)
+	advertRepo.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestReportsModeration(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	reportRepo := report.RepositoryMock{}
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.ResolveReport = board.NewResolveReport(&reportRepo)
+	app.Queries.GetReport = board.NewGetReport(&reportRepo)
+	app.Queries.GetReports = board.NewGetReports(&reportRepo)
+	server, client, sessionStore := createTestServer(t, app)
+
+	usr := &user_domain.User{ID: uuid.New(), Login: "user", Role: user_domain.RoleUser}
+	moderator := &user_domain.User{ID: uuid.New(), Login: "moderator", Role: user_domain.RoleModerator}
+	for _, u := range []*user_domain.User{usr, moderator} {
+		userRepo.On("GetByLogin", mock.Anything, u.Login).Return(u, nil)
+	}
+
+	open, err := report_domain.NewReport(uuid.New(), nil, "203.0.113.7", report_domain.ReasonScam, "")
+	assert.NoError(t, err)
+	resolvedAt := time.Now()
+	resolved := *open
+	resolved.ID = uuid.New()
+	resolved.ResolvedAt = &resolvedAt
+
+	reportRepo.On("GetList", mock.Anything, report_domain.ListFilter{OnlyOpen: true, Limit: MaxReportsInResponse}).Return([]report_domain.Report{*open}, nil)
+	reportRepo.On("GetList", mock.Anything, mock.Anything).Return([]report_domain.Report{*open, resolved}, nil)
+	reportRepo.On("Get", mock.Anything, open.ID).Return(*open, nil)
+	reportRepo.On("Get", mock.Anything, resolved.ID).Return(resolved, nil)
+	reportRepo.On("Get", mock.Anything, mock.Anything).Return(report_domain.Report{}, report_domain.ReportNotFound)
+	reportRepo.On("Resolve", mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("list", func(t *testing.T) {
+		var response []reportResponse
+		resp := doRequest(t, client, "GET", fmt.Sprintf("%s/api/moderation/reports", server.URL), nil, &response, user.CreateTestSession(t, moderator, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, response, 1)
+
+		response = nil
+		resp = doRequest(t, client, "GET", fmt.Sprintf("%s/api/moderation/reports?all=true", server.URL), nil, &response, user.CreateTestSession(t, moderator, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, response, 2)
+
+		resp = doRequest(t, client, "GET", fmt.Sprintf("%s/api/moderation/reports", server.URL), nil, nil, user.CreateTestSession(t, usr, sessionStore))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	type testCase struct {
+		name           string
+		user           *user_domain.User
+		reportID       uuid.UUID
+		payload        interface{}
+		expectedStatus int
+	}
+
+	testCases := []testCase{
+		{
+			name:           "not a moderator",
+			user:           usr,
+			reportID:       open.ID,
+			payload:        resolveReportPayload{Resolution: report_domain.ResolutionUpheld},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "report not found",
+			user:           moderator,
+			reportID:       uuid.New(),
+			payload:        resolveReportPayload{Resolution: report_domain.ResolutionUpheld},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown resolution",
+			user:           moderator,
+			reportID:       open.ID,
+			payload:        resolveReportPayload{Resolution: "ignored"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "already resolved",
+			user:           moderator,
+			reportID:       resolved.ID,
+			payload:        resolveReportPayload{Resolution: report_domain.ResolutionUpheld},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "success",
+			user:           moderator,
+			reportID:       open.ID,
+			payload:        resolveReportPayload{Resolution: report_domain.ResolutionDismissed},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			response := reportResponse{}
+			resp := doRequest(t, client, "POST", fmt.Sprintf("%s/api/moderation/reports/%s/resolve", server.URL, tC.reportID), tC.payload, &response, user.CreateTestSession(t, tC.user, sessionStore))
+			assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+			if tC.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, report_domain.ResolutionDismissed, response.Resolution)
+			assert.Equal(t, &moderator.ID, response.ResolvedBy)
+		})
+	}
+	reportRepo.AssertNumberOfCalls(t, "Resolve", 1)
+}
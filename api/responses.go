@@ -2,13 +2,22 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	log "github.com/sirupsen/logrus"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
 	"net/http"
 )
 
 type errorStruct struct {
 	Error   string `json:"error,omitempty"`
 	Details string `json:"errorDetails,omitempty"`
+	// Code identifies the error, clients can rely on it, unlike on the details
+	Code string `json:"code,omitempty"`
+	// Message describes the error in the language of the user, see LanguageMiddleware
+	Message string `json:"message,omitempty"`
+	// Fields lists the rejected fields of the payload with machine-readable codes, it's present only for validation errors
+	Fields validation.Errors `json:"fields,omitempty"`
 }
 
 func WriteJSON(w http.ResponseWriter, statusCode int, dataStruct interface{}) {
@@ -29,7 +38,52 @@ func WriteJSON(w http.ResponseWriter, statusCode int, dataStruct interface{}) {
 	}
 }
 
-func WriteError(w http.ResponseWriter, statusCode int, errorDetails string) {
-	errStruct := errorStruct{Error: http.StatusText(statusCode), Details: errorDetails}
+// WriteError responds with the code from the catalogue in errorMessages and the details, server errors use internalErrorCode
+func WriteError(w http.ResponseWriter, statusCode int, code string, errorDetails string) {
+	errStruct := errorStruct{Error: http.StatusText(statusCode), Details: errorDetails, Code: code}
+	writeLocalizedError(w, statusCode, errStruct)
+}
+
+// WriteErrorf formats the details, the message of the code is formatted with the same arguments
+func WriteErrorf(w http.ResponseWriter, statusCode int, code string, format string, args ...interface{}) {
+	errStruct := errorStruct{Error: http.StatusText(statusCode), Details: fmt.Sprintf(format, args...), Code: code}
+	writeLocalizedError(w, statusCode, errStruct, args...)
+}
+
+// WriteDomainError responds with the domain error as the details and its code
+func WriteDomainError(w http.ResponseWriter, statusCode int, err error) {
+	errStruct := errorStruct{Error: http.StatusText(statusCode), Details: err.Error(), Code: errorCode(err)}
+	writeLocalizedError(w, statusCode, errStruct)
+}
+
+// WriteValidationError responds with the fields rejected by the validation, other errors get only the details.
+// Without the code it's the code of the domain error.
+func WriteValidationError(w http.ResponseWriter, code string, errorDetails string, err error) {
+	statusCode := http.StatusUnprocessableEntity
+	errStruct := errorStruct{Error: http.StatusText(statusCode), Details: errorDetails, Code: code}
+	if code == "" {
+		errStruct.Code = errorCode(err)
+	}
+	if fields, ok := validation.As(err); ok {
+		errStruct.Fields = fields
+	}
+	writeLocalizedError(w, statusCode, errStruct)
+}
+
+// writeLocalizedError adds the message of the error code in the language of the user, details are used as the message of errors out of the catalogue
+func writeLocalizedError(w http.ResponseWriter, statusCode int, errStruct errorStruct, args ...interface{}) {
+	language := English
+	if lw, ok := w.(languageWriter); ok {
+		language = lw.Language()
+	}
+
+	message, ok := localizedMessage(errStruct.Code, language, args...)
+	if !ok {
+		message, language = errStruct.Details, English
+	}
+	errStruct.Message = message
+	if message != "" {
+		w.Header().Set("Content-Language", string(language))
+	}
 	WriteJSON(w, statusCode, errStruct)
 }
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	application "github.com/ukrainian-brothers/board-backend/app"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"net/http"
+	"time"
+)
+
+type SearchAPI struct {
+	log    *logrus.Entry
+	router *mux.Router
+	app    application.Application
+}
+
+func NewSearchAPI(r *mux.Router, log *logrus.Entry, app application.Application, middleware *MiddlewareProvider) *SearchAPI {
+	searchApi := SearchAPI{router: r, app: app, log: log}
+	r.HandleFunc("/api/user/searches", middleware.AuthMiddleware(searchApi.SaveSearch, log)).Methods("POST")
+	r.HandleFunc("/api/user/searches", middleware.AuthMiddleware(searchApi.SavedSearchesList, log)).Methods("GET")
+	r.HandleFunc("/api/user/searches/{id}", middleware.AuthMiddleware(searchApi.DeleteSavedSearch, log)).Methods("DELETE")
+	return &searchApi
+}
+
+type savedSearchPayload struct {
+	Types     []domain.AdvertType `json:"types,omitempty"`
+	Languages LanguageTags        `json:"languages,omitempty"`
+	City      string              `json:"city,omitempty"`
+	Region    string              `json:"region,omitempty"`
+	Latitude  *float64            `json:"latitude,omitempty"`
+	Longitude *float64            `json:"longitude,omitempty"`
+	RadiusKm  float64             `json:"radius_km,omitempty"`
+	Query     string              `json:"query,omitempty"`
+}
+
+func (p savedSearchPayload) Criteria() (search.Criteria, error) {
+	criteria := search.Criteria{
+		Types:     p.Types,
+		Languages: p.Languages,
+		City:      p.City,
+		Region:    p.Region,
+		RadiusKm:  p.RadiusKm,
+		Query:     p.Query,
+	}
+	if p.Latitude != nil || p.Longitude != nil {
+		if p.Latitude == nil || p.Longitude == nil {
+			return search.Criteria{}, domain.InvalidCoordinatesErr
+		}
+		criteria.Near = &domain.Coordinates{Latitude: *p.Latitude, Longitude: *p.Longitude}
+	}
+	return criteria, nil
+}
+
+type savedSearchResponse struct {
+	ID string `json:"id"`
+	savedSearchPayload
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (resp *savedSearchResponse) LoadSavedSearch(savedSearch search.SavedSearch) {
+	criteria := savedSearch.Criteria
+	resp.ID = savedSearch.ID.String()
+	resp.savedSearchPayload = savedSearchPayload{
+		Types:     criteria.Types,
+		Languages: criteria.Languages,
+		City:      criteria.City,
+		Region:    criteria.Region,
+		RadiusKm:  criteria.RadiusKm,
+		Query:     criteria.Query,
+	}
+	if criteria.Near != nil {
+		resp.Latitude = &criteria.Near.Latitude
+		resp.Longitude = &criteria.Near.Longitude
+	}
+	resp.CreatedAt = savedSearch.CreatedAt
+}
+
+func isInvalidCriteria(err error) bool {
+	return errors.Is(err, search.EmptyCriteriaErr) ||
+		errors.Is(err, search.InvalidLanguageErr) ||
+		errors.Is(err, search.QueryTooLongErr) ||
+		isInvalidFilter(err)
+}
+
+// SaveSearch stores the search, the user is notified about new adverts matching it with the periodic digest
+func (a SearchAPI) SaveSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to save search")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithField("user_login", userLogin.(string))
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	payload := savedSearchPayload{}
+	err := dec.Decode(&payload)
+	if err != nil {
+		log.WithError(err).Error("failed decoding saved search payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to save search")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("SaveSearch failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	criteria, err := payload.Criteria()
+	if err != nil {
+		WriteDomainError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	savedSearch, err := search.NewSavedSearch(usr, criteria)
+	if err != nil {
+		if isInvalidCriteria(err) {
+			WriteDomainError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		log.WithError(err).Error("SaveSearch failed creating saved search")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.SaveSearch.Execute(ctx, savedSearch)
+	if err != nil {
+		if errors.Is(err, search.TooManySearchesErr) {
+			WriteErrorf(w, http.StatusConflict, "search.too_many", "can't have more than %d saved searches", search.MaxSavedSearches)
+			return
+		}
+		log.WithError(err).Error("SaveSearch failed storing saved search")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := savedSearchResponse{}
+	response.LoadSavedSearch(*savedSearch)
+	WriteJSON(w, 201, response)
+}
+
+func (a SearchAPI) SavedSearchesList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to list saved searches")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithField("user_login", userLogin.(string))
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to list saved searches")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("SavedSearchesList failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	searches, err := a.app.Queries.GetSavedSearches.Execute(ctx, usr.ID)
+	if err != nil {
+		log.WithError(err).Error("SavedSearchesList failed while fetching saved searches")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	response := []savedSearchResponse{}
+	for _, savedSearch := range searches {
+		searchResponse := savedSearchResponse{}
+		searchResponse.LoadSavedSearch(savedSearch)
+		response = append(response, searchResponse)
+	}
+
+	WriteJSON(w, 200, response)
+}
+
+func (a SearchAPI) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := a.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to delete saved search")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+
+	log = log.WithFields(logrus.Fields{
+		"user_login": userLogin.(string),
+		"search_id":  mux.Vars(r)["id"],
+	})
+
+	searchID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "search.not_found", "saved search not found")
+		return
+	}
+
+	usr, err := a.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to delete saved search")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("DeleteSavedSearch failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = a.app.Commands.DeleteSavedSearch.Execute(ctx, usr, searchID)
+	if err != nil {
+		if errors.Is(err, search.SearchNotFound) {
+			WriteError(w, http.StatusNotFound, "search.not_found", "saved search not found")
+			return
+		}
+		log.WithError(err).Error("DeleteSavedSearch failed deleting saved search")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
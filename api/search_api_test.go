@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
+	search_domain "github.com/ukrainian-brothers/board-backend/domain/search"
+	user_domain "github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal/search"
+	"github.com/ukrainian-brothers/board-backend/internal/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"net/http"
+	"testing"
+)
+
+func TestSavedSearches(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	searchRepo := search.RepositoryMock{}
+
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.SaveSearch = board.NewSaveSearch(&searchRepo)
+	app.Commands.DeleteSavedSearch = board.NewDeleteSavedSearch(&searchRepo)
+	app.Queries.GetSavedSearches = board.NewGetSavedSearches(&searchRepo)
+	server, client, sessionStore := createTestServer(t, app)
+
+	seeker := &user_domain.User{ID: uuid.New(), Login: "seeker", Role: user_domain.RoleUser}
+	hoarder := &user_domain.User{ID: uuid.New(), Login: "hoarder", Role: user_domain.RoleUser}
+	for _, usr := range []*user_domain.User{seeker, hoarder} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+
+	searchRepo.On("Count", mock.Anything, hoarder.ID).Return(search_domain.MaxSavedSearches, nil)
+	searchRepo.On("Count", mock.Anything, mock.Anything).Return(0, nil)
+	searchRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("save", func(t *testing.T) {
+		latitude, longitude := 52.2297, 21.0122
+
+		type testCase struct {
+			name           string
+			user           *user_domain.User
+			payload        interface{}
+			expectedStatus int
+		}
+
+		testCases := []testCase{
+			{name: "not authorized", payload: savedSearchPayload{Query: "flat"}, expectedStatus: http.StatusForbidden},
+			{name: "unknown field", user: seeker, payload: map[string]string{"name": "flat"}, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "no criteria", user: seeker, payload: savedSearchPayload{}, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "unknown type", user: seeker, payload: savedSearchPayload{Types: []domain.AdvertType{"boat"}}, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "latitude without longitude", user: seeker, payload: savedSearchPayload{Latitude: &latitude, RadiusKm: 10}, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "radius without point", user: seeker, payload: savedSearchPayload{City: "Warszawa", RadiusKm: 10}, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "too many searches", user: hoarder, payload: savedSearchPayload{Query: "flat"}, expectedStatus: http.StatusConflict},
+			{name: "success", user: seeker, payload: savedSearchPayload{
+				Types:     []domain.AdvertType{domain.AdvertTypePlaceToStay},
+				Languages: LanguageTags{Polish},
+				Latitude:  &latitude,
+				Longitude: &longitude,
+				RadiusKm:  30,
+				Query:     " flat ",
+			}, expectedStatus: http.StatusCreated},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.name, func(t *testing.T) {
+				var cookies []*http.Cookie
+				if tC.user != nil {
+					cookies = user.CreateTestSession(t, tC.user, sessionStore)
+				}
+
+				response := savedSearchResponse{}
+				resp := doRequest(t, client, "POST", server.URL+"/api/user/searches", tC.payload, &response, cookies)
+				assert.Equal(t, tC.expectedStatus, resp.StatusCode)
+				if tC.expectedStatus != http.StatusCreated {
+					return
+				}
+				assert.Equal(t, "flat", response.Query)
+				assert.Equal(t, &latitude, response.Latitude)
+				assert.Equal(t, LanguageTags{Polish}, response.Languages)
+			})
+		}
+		searchRepo.AssertNumberOfCalls(t, "Add", 1)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		searchRepo.On("GetList", mock.Anything, seeker.ID).Return([]search_domain.SavedSearch{
+			{ID: uuid.New(), UserID: seeker.ID, Criteria: search_domain.Criteria{City: "Kraków"}},
+		}, nil)
+
+		var response []savedSearchResponse
+		resp := doRequest(t, client, "GET", server.URL+"/api/user/searches", nil, &response, user.CreateTestSession(t, seeker, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, response, 1)
+		assert.Equal(t, "Kraków", response[0].City)
+		assert.Nil(t, response[0].Latitude)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		owned := search_domain.SavedSearch{ID: uuid.New(), UserID: seeker.ID}
+		searchRepo.On("Get", mock.Anything, owned.ID).Return(owned, nil)
+		searchRepo.On("Get", mock.Anything, mock.Anything).Return(search_domain.SavedSearch{}, search_domain.SearchNotFound)
+		searchRepo.On("Delete", mock.Anything, owned.ID).Return(nil)
+		url := fmt.Sprintf("%s/api/user/searches/%s", server.URL, owned.ID)
+
+		resp := doRequest(t, client, "DELETE", url, nil, nil, user.CreateTestSession(t, hoarder, sessionStore))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = doRequest(t, client, "DELETE", fmt.Sprintf("%s/api/user/searches/%s", server.URL, uuid.New()), nil, nil, user.CreateTestSession(t, seeker, sessionStore))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp = doRequest(t, client, "DELETE", url, nil, nil, user.CreateTestSession(t, seeker, sessionStore))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		searchRepo.AssertNumberOfCalls(t, "Delete", 1)
+	})
+}
+
+func TestSavedSearchesNotifications(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	searchRepo := search.RepositoryMock{}
+	notifier := search.NewMemoryNotifier()
+
+	app := newTestApplication(&advertRepo, &userRepo)
+	app.Commands.AddAdvert = board.NewAddAdvert(&advertRepo, &searchRepo, advert_domain.ModerationPolicy{AutoPublishTrusted: true})
+	app.Commands.SendSearchDigests = board.NewSendSearchDigests(&searchRepo, notifier, 100)
+	server, client, sessionStore := createTestServer(t, app)
+
+	contactDetails := user.GetValidContactDetails()
+	author := &user_domain.User{ID: uuid.New(), Login: "author", Role: user_domain.RoleUser, ContactDetails: contactDetails, Trusted: true}
+	newbie := &user_domain.User{ID: uuid.New(), Login: "newbie", Role: user_domain.RoleUser, ContactDetails: contactDetails}
+	for _, usr := range []*user_domain.User{author, newbie} {
+		userRepo.On("GetByLogin", mock.Anything, usr.Login).Return(usr, nil)
+	}
+	advertRepo.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+	seekerID := uuid.New()
+	matching := search_domain.SavedSearch{ID: uuid.New(), UserID: seekerID, Criteria: search_domain.Criteria{Query: "van"}}
+	other := search_domain.SavedSearch{ID: uuid.New(), UserID: seekerID, Criteria: search_domain.Criteria{City: "Lviv"}}
+	own := search_domain.SavedSearch{ID: uuid.New(), UserID: author.ID, Criteria: search_domain.Criteria{Query: "van"}}
+	searchRepo.On("GetCandidates", mock.Anything, domain.AdvertTypeTransport, LanguageTags{English}).Return([]search_domain.SavedSearch{matching, other, own}, nil).Once()
+
+	var stored []search_domain.Match
+	searchRepo.On("AddMatches", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		stored = append(stored, args.Get(1).([]search_domain.Match)...)
+	})
+
+	payload := newAdvertPayload{
+		Title:          MultilingualString{English: "Van to Warsaw"},
+		Description:    MultilingualString{English: "Free seats tomorrow"},
+		Type:           domain.AdvertTypeTransport,
+		ContactDetails: contactPayload{Mail: *contactDetails.Mail},
+	}
+
+	// pending advert isn't matched until approved
+	resp := doRequest(t, client, "POST", server.URL+"/api/adverts", payload, nil, user.CreateTestSession(t, newbie, sessionStore))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	searchRepo.AssertNotCalled(t, "GetCandidates", mock.Anything, mock.Anything, mock.Anything)
+
+	response := advertResponse{}
+	resp = doRequest(t, client, "POST", server.URL+"/api/adverts", payload, &response, user.CreateTestSession(t, author, sessionStore))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Len(t, stored, 1)
+	assert.Equal(t, matching.ID, stored[0].SearchID)
+	assert.Equal(t, response.ID, stored[0].AdvertID.String())
+
+	// failed matching doesn't fail adding the advert
+	searchRepo.On("GetCandidates", mock.Anything, domain.AdvertTypeTransport, LanguageTags{English}).Return(nil, errors.New("connection lost"))
+	resp = doRequest(t, client, "POST", server.URL+"/api/adverts", payload, nil, user.CreateTestSession(t, author, sessionStore))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	t.Run("digest", func(t *testing.T) {
+		second := search_domain.Match{SearchID: other.ID, UserID: seekerID, AdvertID: uuid.New()}
+		failing := search_domain.Match{SearchID: uuid.New(), UserID: uuid.New(), AdvertID: uuid.New()}
+		pending := append(stored, second, failing)
+		searchRepo.On("GetPendingMatches", mock.Anything, 100).Return(pending, nil)
+		searchRepo.On("MarkNotified", mock.Anything, []search_domain.Match{failing}, mock.Anything).Return(sql.ErrConnDone)
+		searchRepo.On("MarkNotified", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		sent, err := app.Commands.SendSearchDigests.Execute(context.Background())
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Equal(t, 1, sent)
+
+		digests := notifier.Digests()
+		require.Len(t, digests, 2)
+		assert.Equal(t, seekerID, digests[0].UserID)
+		assert.Equal(t, []uuid.UUID{stored[0].AdvertID, second.AdvertID}, digests[0].AdvertIDs())
+		searchRepo.AssertCalled(t, "MarkNotified", mock.Anything, []search_domain.Match{stored[0], second}, mock.Anything)
+	})
+}
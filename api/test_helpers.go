@@ -4,28 +4,39 @@ import (
 	"bytes"
 	"encoding/json"
 	"github.com/go-gorp/gorp"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	application "github.com/ukrainian-brothers/board-backend/app"
 	"github.com/ukrainian-brothers/board-backend/app/board"
 	"github.com/ukrainian-brothers/board-backend/domain/advert"
 	"github.com/ukrainian-brothers/board-backend/domain/user"
 	internal_advert "github.com/ukrainian-brothers/board-backend/internal/advert"
 	"github.com/ukrainian-brothers/board-backend/internal/common"
+	internal_favourite "github.com/ukrainian-brothers/board-backend/internal/favourite"
+	internal_search "github.com/ukrainian-brothers/board-backend/internal/search"
 	internal_user "github.com/ukrainian-brothers/board-backend/internal/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/clientip"
 	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func newStringPtr(s string) *string {
 	return &s
 }
 
+func newFloatPtr(f float64) *float64 {
+	return &f
+}
+
 func getMockedRepo() (internal_user.RepositoryMock, internal_advert.RepositoryMock) {
 	return internal_user.RepositoryMock{}, internal_advert.RepositoryMock{}
 }
@@ -41,38 +52,81 @@ func getPostgresRepos(t *testing.T) (user.Repository, advert.Repository, *gorp.D
 	return internal_user.NewPostgresUserRepository(db), internal_advert.NewPostgresAdvertRepository(db), db
 }
 
-func createTestAPIs(t *testing.T, advertRepo advert.Repository, userRepo user.Repository) (*httptest.Server, http.Client, *sessions.CookieStore) {
-	logger := log.NewEntry(log.New())
+// newTestApplication wires all commands and queries with given repositories, the rest of them can be replaced by the test.
+// Users have no favourite adverts and no saved searches unless the test replaces the favourite and search commands.
+func newTestApplication(advertRepo advert.Repository, userRepo user.Repository) application.Application {
+	favouriteRepo := &internal_favourite.RepositoryMock{}
+	favouriteRepo.On("AreFavourite", mock.Anything, mock.Anything, mock.Anything).Return(map[uuid.UUID]bool{}, nil)
+	searchRepo := &internal_search.RepositoryMock{}
+	searchRepo.On("GetCandidates", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	searchRepo.On("AddMatches", mock.Anything, mock.Anything).Return(nil)
 
-	app := application.Application{
+	return application.Application{
 		Commands: application.Commands{
-			AddUser:   board.NewAddUser(userRepo),
-			AddAdvert: board.NewAddAdvert(advertRepo),
+			AddUser:               board.NewAddUser(userRepo),
+			SetUserLanguage:       board.NewSetUserLanguage(userRepo),
+			AddAdvert:             board.NewAddAdvert(advertRepo, searchRepo, advert.ModerationPolicy{}),
+			UpdateAdvert:          board.NewUpdateAdvert(advertRepo, advert.ModerationPolicy{}),
+			DeleteAdvert:          board.NewDeleteAdvert(advertRepo),
+			RenewAdvert:           board.NewRenewAdvert(advertRepo),
+			ModerateAdvert:        board.NewModerateAdvert(advertRepo, searchRepo),
+			ArchiveExpiredAdverts: board.NewArchiveExpiredAdverts(advertRepo),
+			CountAdvertView:       board.NewCountAdvertView(advertRepo, time.Hour),
+			RevealContact:         board.NewRevealContact(advertRepo, advert.ContactPolicy{}, 10, time.Hour),
 		},
 		Queries: application.Queries{
 			UserExists:         board.NewUserExists(userRepo),
 			GetUserByLogin:     board.NewGetUserByLogin(userRepo),
 			VerifyUserPassword: board.NewVerifyUserPassword(userRepo),
+			GetAdvert:          board.NewGetAdvert(advertRepo),
 			GetAdvertsList:     board.NewGetAdvertsList(advertRepo),
+			AreFavourite:       board.NewAreFavourite(favouriteRepo),
 		},
 	}
+}
+
+func createTestAPIs(t *testing.T, advertRepo advert.Repository, userRepo user.Repository) (*httptest.Server, http.Client, *sessions.CookieStore) {
+	return createTestServer(t, newTestApplication(advertRepo, userRepo))
+}
+
+func createTestServer(t *testing.T, app application.Application) (*httptest.Server, http.Client, *sessions.CookieStore) {
+	logger := log.NewEntry(log.New())
 
 	cfg := test_helpers.GetTestConfig(t)
 
 	sessionStore := sessions.NewCookieStore([]byte(cfg.Session.Secret))
 	middleware := NewMiddlewareProvider(sessionStore, &app, cfg)
+	clientIPResolver, err := clientip.NewResolver(cfg.Proxy.TrustedProxies, cfg.Proxy.ClientIPHeader)
+	assert.NoError(t, err)
 
 	router := mux.NewRouter()
 	router.Use(middleware.BodyLimitMiddleware)
+	router.Use(middleware.ClientIPMiddleware(clientIPResolver))
 	router.Use(middleware.LoggingMiddleware(logger))
+	router.Use(middleware.LanguageMiddleware)
 	NewUserAPI(router, logger, app, middleware, sessionStore, cfg)
 	NewAdvertAPI(router, logger, app, middleware, sessionStore, cfg)
+	NewModerationAPI(router, logger, app, middleware)
+	NewReportAPI(router, logger, app, middleware)
+	NewImageAPI(router, logger, app, middleware, cfg)
+	NewFavouriteAPI(router, logger, app, middleware)
+	NewSearchAPI(router, logger, app, middleware)
+	NewConversationAPI(router, logger, app, middleware)
 
 	server := httptest.NewServer(router)
 
 	return server, http.Client{}, sessionStore
 }
 
+// fieldCodes flattens the rejected fields of the error response to "path code" pairs, nil for responses without fields
+func fieldCodes(fields validation.Errors) []string {
+	var codes []string
+	for _, field := range fields {
+		codes = append(codes, field.Field+" "+field.Code)
+	}
+	return codes
+}
+
 func responseToStruct(t *testing.T, resp *http.Response, response interface{}) {
 	by, err := io.ReadAll(resp.Body)
 	assert.NoError(t, err)
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	logrus "github.com/sirupsen/logrus"
@@ -24,6 +26,7 @@ func NewUserAPI(r *mux.Router, log *logrus.Entry, app application.Application, m
 	usrApi := UserAPI{router: r, app: app, log: log, sessionStore: sessionStore, cfg: cfg}
 	r.HandleFunc("/api/user/register", usrApi.Register).Methods("POST")
 	r.HandleFunc("/api/user/login", usrApi.Login).Methods("POST")
+	r.HandleFunc("/api/user/language", middleware.AuthMiddleware(usrApi.SetLanguage, log)).Methods("PUT")
 	return &usrApi
 }
 
@@ -34,6 +37,8 @@ type registerPayload struct {
 	Surname   string `json:"surname"`
 	Mail      string `json:"mail"`
 	Phone     string `json:"phone"`
+	// Language is the BCP 47 tag of the language the user wants to get messages in, it's optional
+	Language string `json:"language"`
 }
 
 func (u UserAPI) Register(w http.ResponseWriter, r *http.Request) {
@@ -47,14 +52,14 @@ func (u UserAPI) Register(w http.ResponseWriter, r *http.Request) {
 	err := dec.Decode(&payload)
 	if err != nil {
 		log.WithError(err).Error("failed decoding register payload")
-		WriteError(w, http.StatusUnprocessableEntity, "invalid payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
 		return
 	}
 
 	contactDetails, err := domain.NewContactDetails(payload.Mail, payload.Phone)
 	if err != nil {
 		log.WithError(err).Error("failed creating contact details")
-		WriteError(w, http.StatusUnprocessableEntity, "missing contact details")
+		WriteValidationError(w, "contact.missing", "missing contact details", err)
 		return
 	}
 
@@ -66,27 +71,34 @@ func (u UserAPI) Register(w http.ResponseWriter, r *http.Request) {
 	usr, err := user.NewUser(payload.Firstname, payload.Surname, payload.Login, payload.Password, contactDetails)
 	if err != nil {
 		log.WithError(err).Error("failed creating User struct")
-		WriteError(w, http.StatusUnprocessableEntity, "")
+		WriteValidationError(w, "", "", err)
+		return
+	}
+
+	err = usr.SetLanguage(payload.Language)
+	if err != nil {
+		log.WithError(err).Error("failed setting user language")
+		WriteValidationError(w, "", "", err)
 		return
 	}
 
 	userExists, err := u.app.Queries.UserExists.Execute(ctx, usr.Login)
 	if err != nil {
 		log.WithError(err).Error("failed to execute UserExists query")
-		WriteError(w, http.StatusInternalServerError, "")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
 	if userExists {
 		log.Info("user already exists")
-		WriteError(w, http.StatusUnprocessableEntity, "user already exists")
+		WriteError(w, http.StatusUnprocessableEntity, "user.already_exists", "user already exists")
 		return
 	}
 
 	err = u.app.Commands.AddUser.Execute(ctx, *usr)
 	if err != nil {
 		log.WithError(err).Error("failed to execute AddUser command")
-		WriteError(w, http.StatusInternalServerError, "")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
@@ -109,44 +121,119 @@ func (u UserAPI) Login(w http.ResponseWriter, r *http.Request) {
 	err := dec.Decode(&payload)
 	if err != nil {
 		log.WithError(err).Error("failed decoding login payload")
-		WriteError(w, http.StatusUnprocessableEntity, "invalid payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
 		return
 	}
 
 	exists, err := u.app.Queries.UserExists.Execute(ctx, payload.Login)
 	if err != nil {
 		log.WithError(err).Error("failed verifying user existence while logging in")
-		WriteError(w, http.StatusInternalServerError, "")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
 	if !exists {
 		log.Info("failed login, user does not exists")
-		WriteError(w, http.StatusUnprocessableEntity, "user does not exists")
+		WriteError(w, http.StatusUnprocessableEntity, "user.not_found", "user does not exists")
 		return
 	}
 
 	valid, err := u.app.Queries.VerifyUserPassword.Execute(ctx, payload.Login, payload.Password)
 	if err != nil {
 		log.WithError(err).Error("failed verifying user password")
-		WriteError(w, http.StatusInternalServerError, "")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
 	if !valid {
 		log.Info("wrong credentials")
-		WriteError(w, http.StatusForbidden, "wrong credentials")
+		WriteError(w, http.StatusForbidden, "user.wrong_credentials", "wrong credentials")
+		return
+	}
+
+	usr, err := u.app.Queries.GetUserByLogin.Execute(ctx, payload.Login)
+	if err != nil {
+		log.WithError(err).Error("Login failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
 	session, err := u.sessionStore.Get(r, u.cfg.Session.SessionKey)
 	if err != nil {
 		log.WithError(err).Error("Login failed getting session")
-		WriteError(w, http.StatusInternalServerError, "")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
 		return
 	}
 
 	session.Values["user_login"] = payload.Login
+	// LanguageMiddleware reads the language from the session, so the user isn't loaded for every error message
+	session.Values["user_language"] = string(usr.Language)
+	err = session.Save(r, w)
+	if err != nil {
+		log.WithError(err).Error("failed saving session")
+	}
+	WriteJSON(w, 200, map[string]string{"status": "ok"})
+}
+
+type languagePayload struct {
+	Language string `json:"language"`
+}
+
+// SetLanguage changes the language the user wants to get messages in, empty language clears the preference
+func (u UserAPI) SetLanguage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := u.log
+
+	userLogin := ctx.Value("user_login")
+	if userLogin == nil {
+		log.Info("not authorized user tries to set language")
+		WriteError(w, http.StatusForbidden, "auth.required", "not authorized")
+		return
+	}
+	log = log.WithField("user_login", userLogin.(string))
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	payload := languagePayload{}
+	err := dec.Decode(&payload)
+	if err != nil {
+		log.WithError(err).Error("failed decoding language payload")
+		WriteError(w, http.StatusUnprocessableEntity, "invalid_payload", "invalid payload")
+		return
+	}
+
+	usr, err := u.app.Queries.GetUserByLogin.Execute(ctx, userLogin.(string))
+	if err != nil {
+		if errors.Unwrap(err) == sql.ErrNoRows {
+			log.Info("not authorized user tries to set language")
+			WriteError(w, http.StatusForbidden, "user.deleted", "user does not exists anymore")
+			return
+		}
+		log.WithError(err).Error("SetLanguage failed getting user by login")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	err = u.app.Commands.SetUserLanguage.Execute(ctx, usr, payload.Language)
+	if err != nil {
+		if errors.Is(err, user.InvalidLanguageErr) {
+			WriteValidationError(w, "", "", err)
+			return
+		}
+		log.WithError(err).Error("failed to execute SetUserLanguage command")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	session, err := u.sessionStore.Get(r, u.cfg.Session.SessionKey)
+	if err != nil {
+		log.WithError(err).Error("SetLanguage failed getting session")
+		WriteError(w, http.StatusInternalServerError, internalErrorCode, "")
+		return
+	}
+
+	session.Values["user_language"] = string(usr.Language)
 	err = session.Save(r, w)
 	if err != nil {
 		log.WithError(err).Error("failed saving session")
@@ -1,14 +1,18 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/ukrainian-brothers/board-backend/domain/user"
 	internal_user "github.com/ukrainian-brothers/board-backend/internal/user"
 	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
 	"net/http"
 	"testing"
 )
@@ -200,6 +204,41 @@ func TestRegistration(t *testing.T) {
 	}
 }
 
+func TestRegistrationValidation(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, _ := createTestAPIs(t, &advertRepo, &userRepo)
+
+	testCases := []struct {
+		name            string
+		payload         registerPayload
+		expectedDetails string
+		expectedFields  []string
+	}{
+		{
+			name:            "invalid contact",
+			payload:         registerPayload{Firstname: "Mac", Surname: "Smith", Mail: "the_mail"},
+			expectedDetails: "missing contact details",
+			expectedFields:  []string{"mail invalid_format", "phone required"},
+		},
+		{
+			name:           "personal data",
+			payload:        registerPayload{Surname: "Smith-Johnson-Williams", Phone: "+48 111 222 333"},
+			expectedFields: []string{"firstname required", "surname too_long"},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			responseStruct := errorStruct{}
+			resp := doRequest(t, client, "POST", server.URL+"/api/user/register", tC.payload, &responseStruct, nil)
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+			assert.Equal(t, tC.expectedDetails, responseStruct.Details)
+			assert.Equal(t, tC.expectedFields, fieldCodes(responseStruct.Fields))
+		})
+	}
+	userRepo.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+}
+
 func TestDecodingError(t *testing.T) {
 	userRepo, advertRepo := getMockedRepo()
 	server, client, _ := createTestAPIs(t, &advertRepo, &userRepo)
@@ -401,3 +440,120 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalizedErrors(t *testing.T) {
+	userRepo, advertRepo := getMockedRepo()
+	server, client, sessionStore := createTestAPIs(t, &advertRepo, &userRepo)
+
+	polish := &user.User{ID: uuid.New(), Login: "polish", Language: Polish}
+	undecided := &user.User{ID: uuid.New(), Login: "undecided"}
+	userRepo.On("GetByLogin", mock.Anything, polish.Login).Return(polish, nil)
+	userRepo.On("GetByLogin", mock.Anything, undecided.Login).Return(undecided, nil)
+	userRepo.On("Exists", mock.Anything, "taken").Return(true, nil)
+	userRepo.On("UpdateLanguage", mock.Anything, undecided.ID, Ukrainian).Return(nil)
+
+	request := func(t *testing.T, method string, path string, payload interface{}, acceptLanguage string, usr *user.User) (*http.Response, errorStruct) {
+		by, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(by))
+		require.NoError(t, err)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		if usr != nil {
+			for _, cookie := range internal_user.CreateTestSession(t, usr, sessionStore) {
+				req.AddCookie(cookie)
+			}
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		response := errorStruct{}
+		responseToStruct(t, resp, &response)
+		return resp, response
+	}
+
+	t.Run("Accept-Language", func(t *testing.T) {
+		testCases := []struct {
+			acceptLanguage  string
+			expectedMessage string
+			expectedHeader  string
+		}{
+			{acceptLanguage: "", expectedMessage: "user already exists", expectedHeader: "en"},
+			{acceptLanguage: "uk-UA,uk;q=0.9,en;q=0.8", expectedMessage: "користувач уже існує", expectedHeader: "uk"},
+			{acceptLanguage: "ua", expectedMessage: "користувач уже існує", expectedHeader: "uk"},
+			{acceptLanguage: "de, pl;q=0.5", expectedMessage: "użytkownik już istnieje", expectedHeader: "pl"},
+			{acceptLanguage: "de", expectedMessage: "user already exists", expectedHeader: "en"},
+		}
+
+		for _, tC := range testCases {
+			t.Run(tC.acceptLanguage, func(t *testing.T) {
+				payload := registerPayload{Login: "taken", Firstname: "Mac", Surname: "Smith", Phone: "+48 111 222 333"}
+				resp, response := request(t, "POST", "/api/user/register", payload, tC.acceptLanguage, nil)
+				assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+				assert.Equal(t, "user already exists", response.Details)
+				assert.Equal(t, "user.already_exists", response.Code)
+				assert.Equal(t, tC.expectedMessage, response.Message)
+				assert.Equal(t, tC.expectedHeader, resp.Header.Get("Content-Language"))
+			})
+		}
+	})
+
+	t.Run("codes", func(t *testing.T) {
+		resp, response := request(t, "POST", "/api/user/register", registerPayload{Surname: "Smith", Phone: "+48 111 222 333"}, "uk", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "user.missing_personal_data", response.Code)
+		assert.Equal(t, "відсутні особисті дані", response.Message)
+
+		resp, response = request(t, "POST", "/api/user/register", registerPayload{Firstname: "Mac", Surname: "Smith", Phone: "+48 111 222 333", Language: "xx"}, "", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "language.unsupported", response.Code)
+		assert.Equal(t, []string{"language unsupported_language"}, fieldCodes(response.Fields))
+
+		resp, response = request(t, "PUT", "/api/user/language", languagePayload{Language: "uk"}, "pl", nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, "auth.required", response.Code)
+		assert.Equal(t, "wymagane zalogowanie", response.Message)
+	})
+
+	t.Run("user preference", func(t *testing.T) {
+		// the language chosen by the user wins over the header
+		resp, response := request(t, "PUT", "/api/user/language", languagePayload{Language: "de"}, "uk", polish)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "language.unsupported", response.Code)
+		assert.Equal(t, "nieobsługiwany język", response.Message)
+
+		resp, response = request(t, "PUT", "/api/user/language", languagePayload{Language: "de"}, "uk", undecided)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "непідтримувана мова", response.Message)
+
+		resp, _ = request(t, "PUT", "/api/user/language", languagePayload{Language: "uk-UA"}, "", undecided)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		userRepo.AssertCalled(t, "UpdateLanguage", mock.Anything, undecided.ID, Ukrainian)
+
+		// the chosen language is kept in the session returned with the response
+		payload := registerPayload{Login: "taken", Firstname: "Mac", Surname: "Smith", Phone: "+48 111 222 333"}
+		by, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req, err := http.NewRequest("POST", server.URL+"/api/user/register", bytes.NewReader(by))
+		require.NoError(t, err)
+		for _, cookie := range resp.Cookies() {
+			req.AddCookie(cookie)
+		}
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		response = errorStruct{}
+		responseToStruct(t, resp, &response)
+		assert.Equal(t, "користувач уже існує", response.Message)
+	})
+
+	t.Run("language read from the session only", func(t *testing.T) {
+		// the user isn't loaded by the middleware, GetByLogin isn't mocked for the ghost
+		ghost := &user.User{ID: uuid.New(), Login: "ghost", Language: Polish}
+		payload := registerPayload{Login: "taken", Firstname: "Mac", Surname: "Smith", Phone: "+48 111 222 333"}
+		resp, response := request(t, "POST", "/api/user/register", payload, "uk", ghost)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "użytkownik już istnieje", response.Message)
+		userRepo.AssertNotCalled(t, "GetByLogin", mock.Anything, ghost.Login)
+	})
+}
@@ -3,16 +3,49 @@ package app
 import "github.com/ukrainian-brothers/board-backend/app/board"
 
 type Commands struct {
-	AddAdvert board.AddAdvert
-	AddUser   board.AddUser
+	AddAdvert             board.AddAdvert
+	UpdateAdvert          board.UpdateAdvert
+	DeleteAdvert          board.DeleteAdvert
+	RenewAdvert           board.RenewAdvert
+	ModerateAdvert        board.ModerateAdvert
+	AttachAdvertImage     board.AttachAdvertImage
+	RemoveAdvertImage     board.RemoveAdvertImage
+	ReportAdvert          board.ReportAdvert
+	ResolveReport         board.ResolveReport
+	AddFavourite          board.AddFavourite
+	RemoveFavourite       board.RemoveFavourite
+	SaveSearch            board.SaveSearch
+	DeleteSavedSearch     board.DeleteSavedSearch
+	SendSearchDigests     board.SendSearchDigests
+	StartConversation     board.StartConversation
+	SendMessage           board.SendMessage
+	MarkConversationRead  board.MarkConversationRead
+	BlockConversation     board.BlockConversation
+	TranslateAdverts      board.TranslateAdverts
+	ArchiveExpiredAdverts board.ArchiveExpiredAdverts
+	CountAdvertView       board.CountAdvertView
+	RevealContact         board.RevealContact
+	AddUser               board.AddUser
+	SetUserLanguage       board.SetUserLanguage
 }
 
 type Queries struct {
 	GetAdvert          board.GetAdvert
+	GetAdvertHistory   board.GetAdvertHistory
+	OpenAdvertImage    board.OpenAdvertImage
 	GetUserByLogin     board.GetUserByLogin
 	UserExists         board.UserExists
 	VerifyUserPassword board.VerifyUserPassword
 	GetAdvertsList     board.GetAdvertsList
+	GetReport          board.GetReport
+	GetReports         board.GetReports
+	GetFavourites      board.GetFavourites
+	AreFavourite       board.AreFavourite
+	GetSavedSearches   board.GetSavedSearches
+	GetConversation    board.GetConversation
+	GetConversations   board.GetConversations
+	GetMessages        board.GetMessages
+	GetUnreadCounts    board.GetUnreadCounts
 }
 
 type Application struct {
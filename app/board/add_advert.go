@@ -3,20 +3,32 @@ package board
 import (
 	"context"
 	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
 )
 
 type AddAdvert struct {
 	AdvertRepo advert.Repository
+	SearchRepo search.Repository
+	Moderation advert.ModerationPolicy
 }
 
-func NewAddAdvert(advertRepo advert.Repository) AddAdvert {
-	return AddAdvert{AdvertRepo: advertRepo}
+func NewAddAdvert(advertRepo advert.Repository, searchRepo search.Repository, moderation advert.ModerationPolicy) AddAdvert {
+	return AddAdvert{AdvertRepo: advertRepo, SearchRepo: searchRepo, Moderation: moderation}
 }
 
-func (a AddAdvert) Execute(ctx context.Context, advert *advert.Advert) error {
-	err := a.AdvertRepo.Add(ctx, advert)
+// Execute stores the new advert, it's published right away only if its author doesn't require the review.
+// Published advert is matched against saved searches, search.MatchingFailedErr means the advert has been stored anyway.
+func (a AddAdvert) Execute(ctx context.Context, adv *advert.Advert) error {
+	if !a.Moderation.RequiresReview(adv.User) {
+		err := adv.Publish(a.Moderation)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := a.AdvertRepo.Add(ctx, adv)
 	if err != nil {
 		return err
 	}
-	return nil
+	return matchSavedSearches(ctx, a.SearchRepo, adv)
 }
@@ -0,0 +1,35 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+)
+
+type AddFavourite struct {
+	FavouriteRepo favourite.Repository
+}
+
+func NewAddFavourite(favouriteRepo favourite.Repository) AddFavourite {
+	return AddFavourite{FavouriteRepo: favouriteRepo}
+}
+
+// Execute puts the advert on the shortlist, the advert which is already there doesn't count towards the limit
+func (a AddFavourite) Execute(ctx context.Context, fav *favourite.Favourite) error {
+	count, err := a.FavouriteRepo.Count(ctx, fav.UserID)
+	if err != nil {
+		return err
+	}
+
+	if count >= favourite.MaxFavourites {
+		favourites, err := a.FavouriteRepo.AreFavourite(ctx, fav.UserID, []uuid.UUID{fav.AdvertID})
+		if err != nil {
+			return err
+		}
+		if !favourites[fav.AdvertID] {
+			return favourite.TooManyFavouritesErr
+		}
+	}
+
+	return a.FavouriteRepo.Add(ctx, fav)
+}
@@ -0,0 +1,20 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"time"
+)
+
+type ArchiveExpiredAdverts struct {
+	AdvertRepo advert.Repository
+}
+
+func NewArchiveExpiredAdverts(advertRepo advert.Repository) ArchiveExpiredAdverts {
+	return ArchiveExpiredAdverts{AdvertRepo: advertRepo}
+}
+
+func (a ArchiveExpiredAdverts) Execute(ctx context.Context) ([]uuid.UUID, error) {
+	return a.AdvertRepo.ArchiveExpired(ctx, time.Now())
+}
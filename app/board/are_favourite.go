@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+)
+
+type AreFavourite struct {
+	FavouriteRepo favourite.Repository
+}
+
+func NewAreFavourite(favouriteRepo favourite.Repository) AreFavourite {
+	return AreFavourite{FavouriteRepo: favouriteRepo}
+}
+
+func (a AreFavourite) Execute(ctx context.Context, userID uuid.UUID, advertIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return a.FavouriteRepo.AreFavourite(ctx, userID, advertIDs)
+}
@@ -0,0 +1,70 @@
+package board
+
+import (
+	"bytes"
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/blob"
+	"github.com/ukrainian-brothers/board-backend/pkg/imaging"
+)
+
+type AttachAdvertImage struct {
+	AdvertRepo advert.Repository
+	Storage    blob.Storage
+	Moderation advert.ModerationPolicy
+}
+
+func NewAttachAdvertImage(advertRepo advert.Repository, storage blob.Storage, moderation advert.ModerationPolicy) AttachAdvertImage {
+	return AttachAdvertImage{AdvertRepo: advertRepo, Storage: storage, Moderation: moderation}
+}
+
+// Execute processes the uploaded image, stores it together with its thumbnail and attaches it to the advert.
+// The original upload is never stored, so its metadata doesn't leak. The published advert waits for the review
+// of the image if its owner requires it.
+func (a AttachAdvertImage) Execute(ctx context.Context, adv *advert.Advert, editor *user.User, content []byte) (advert.Image, error) {
+	err := adv.CanAttachImage(editor)
+	if err != nil {
+		return advert.Image{}, err
+	}
+
+	processed, err := imaging.Process(content)
+	if err != nil {
+		return advert.Image{}, err
+	}
+
+	img := advert.NewImage(adv.ID, processed.ContentType, processed.Width, processed.Height)
+	err = adv.AttachImage(editor, img)
+	if err != nil {
+		return advert.Image{}, err
+	}
+
+	err = adv.ReviewChanges(a.Moderation)
+	if err != nil {
+		return advert.Image{}, err
+	}
+
+	err = a.Storage.Put(ctx, img.Key(), bytes.NewReader(processed.Image))
+	if err != nil {
+		return advert.Image{}, err
+	}
+
+	err = a.Storage.Put(ctx, img.ThumbnailKey(), bytes.NewReader(processed.Thumbnail))
+	if err != nil {
+		a.removeFiles(ctx, img)
+		return advert.Image{}, err
+	}
+
+	err = a.AdvertRepo.AddImage(ctx, adv, img)
+	if err != nil {
+		a.removeFiles(ctx, img)
+		return advert.Image{}, err
+	}
+	return img, nil
+}
+
+// removeFiles cleans up after failed upload, leftover files aren't referenced by any advert, so errors are ignored
+func (a AttachAdvertImage) removeFiles(ctx context.Context, img advert.Image) {
+	_ = a.Storage.Delete(ctx, img.Key())
+	_ = a.Storage.Delete(ctx, img.ThumbnailKey())
+}
@@ -0,0 +1,29 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+type BlockConversation struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewBlockConversation(conversationRepo conversation.Repository) BlockConversation {
+	return BlockConversation{ConversationRepo: conversationRepo}
+}
+
+// Execute blocks or unblocks the seeker of the conversation, only the advert owner is allowed to do it
+func (a BlockConversation) Execute(ctx context.Context, conv *conversation.Conversation, owner *user.User, blocked bool) error {
+	var err error
+	if blocked {
+		err = conv.Block(owner)
+	} else {
+		err = conv.Unblock(owner)
+	}
+	if err != nil {
+		return err
+	}
+	return a.ConversationRepo.UpdateBlock(ctx, conv)
+}
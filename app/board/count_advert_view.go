@@ -0,0 +1,33 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/pkg/ratelimit"
+	"time"
+)
+
+type CountAdvertView struct {
+	repo    advert.Repository
+	limiter *ratelimit.Limiter
+}
+
+// NewCountAdvertView creates command which counts at most one view of an advert per viewer within the window
+func NewCountAdvertView(advertRepo advert.Repository, window time.Duration) CountAdvertView {
+	return CountAdvertView{repo: advertRepo, limiter: ratelimit.NewLimiter(1, window)}
+}
+
+// Execute returns true when the view has been counted, viewerKey identifies the viewer e.g. by session or IP address
+func (a CountAdvertView) Execute(ctx context.Context, advertID uuid.UUID, viewerKey string) (bool, error) {
+	if !a.limiter.Allow(fmt.Sprintf("%s:%s", advertID, viewerKey)) {
+		return false, nil
+	}
+
+	err := a.repo.IncrementViews(ctx, advertID)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,18 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+)
+
+type DeleteAdvert struct {
+	AdvertRepo advert.Repository
+}
+
+func NewDeleteAdvert(advertRepo advert.Repository) DeleteAdvert {
+	return DeleteAdvert{AdvertRepo: advertRepo}
+}
+
+func (a DeleteAdvert) Execute(ctx context.Context, advert *advert.Advert) error {
+	return a.AdvertRepo.Delete(ctx, advert)
+}
@@ -0,0 +1,29 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+type DeleteSavedSearch struct {
+	SearchRepo search.Repository
+}
+
+func NewDeleteSavedSearch(searchRepo search.Repository) DeleteSavedSearch {
+	return DeleteSavedSearch{SearchRepo: searchRepo}
+}
+
+// Execute removes the search of the user, searches of someone else are reported as search.SearchNotFound
+func (a DeleteSavedSearch) Execute(ctx context.Context, usr *user.User, id uuid.UUID) error {
+	savedSearch, err := a.SearchRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !savedSearch.BelongsTo(usr) {
+		return search.SearchNotFound
+	}
+
+	return a.SearchRepo.Delete(ctx, id)
+}
@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+)
+
+type GetAdvertHistory struct {
+	repo advert.LogRepository
+}
+
+func NewGetAdvertHistory(logRepo advert.LogRepository) GetAdvertHistory {
+	return GetAdvertHistory{repo: logRepo}
+}
+
+func (a GetAdvertHistory) Execute(ctx context.Context, advertID uuid.UUID) (advert.AdvertHistory, error) {
+	return a.repo.GetHistory(ctx, advertID)
+}
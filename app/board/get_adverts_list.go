@@ -3,7 +3,6 @@ package board
 import (
 	"context"
 	"github.com/ukrainian-brothers/board-backend/domain/advert"
-	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
 )
 
 type GetAdvertsList struct {
@@ -14,6 +13,10 @@ func NewGetAdvertsList(advertRepo advert.Repository) GetAdvertsList {
 	return GetAdvertsList{repo: advertRepo}
 }
 
-func (a GetAdvertsList) Execute(ctx context.Context, languages LanguageTags, limit int, offset int) ([]*advert.Advert, error) {
-	return a.repo.GetList(ctx, languages, limit, offset)
+func (a GetAdvertsList) Execute(ctx context.Context, filter advert.ListFilter) ([]*advert.Advert, error) {
+	err := filter.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return a.repo.GetList(ctx, filter)
 }
@@ -0,0 +1,28 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+type GetConversation struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewGetConversation(conversationRepo conversation.Repository) GetConversation {
+	return GetConversation{ConversationRepo: conversationRepo}
+}
+
+// Execute returns the conversation of the participant, conversations of someone else are reported as conversation.ConversationNotFound
+func (a GetConversation) Execute(ctx context.Context, id uuid.UUID, usr *user.User) (conversation.Conversation, error) {
+	conv, err := a.ConversationRepo.Get(ctx, id)
+	if err != nil {
+		return conversation.Conversation{}, err
+	}
+	if !conv.IsParticipant(usr) {
+		return conversation.Conversation{}, conversation.ConversationNotFound
+	}
+	return conv, nil
+}
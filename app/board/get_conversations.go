@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+)
+
+type GetConversations struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewGetConversations(conversationRepo conversation.Repository) GetConversations {
+	return GetConversations{ConversationRepo: conversationRepo}
+}
+
+func (a GetConversations) Execute(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]conversation.Conversation, error) {
+	return a.ConversationRepo.GetList(ctx, userID, limit, offset)
+}
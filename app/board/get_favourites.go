@@ -0,0 +1,52 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+)
+
+type GetFavourites struct {
+	FavouriteRepo favourite.Repository
+	AdvertRepo    advert.Repository
+}
+
+func NewGetFavourites(favouriteRepo favourite.Repository, advertRepo advert.Repository) GetFavourites {
+	return GetFavourites{FavouriteRepo: favouriteRepo, AdvertRepo: advertRepo}
+}
+
+// Execute returns favourite adverts from the most recently added one. Adverts which aren't listed anymore,
+// e.g. expired or suspended ones, are skipped, so the page may be shorter than the limit.
+func (a GetFavourites) Execute(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*advert.Advert, error) {
+	favourites, err := a.FavouriteRepo.GetList(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(favourites) == 0 {
+		return []*advert.Advert{}, nil
+	}
+
+	var ids []uuid.UUID
+	for _, fav := range favourites {
+		ids = append(ids, fav.AdvertID)
+	}
+
+	adverts, err := a.AdvertRepo.GetList(ctx, advert.ListFilter{IDs: ids, Limit: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*advert.Advert, len(adverts))
+	for _, adv := range adverts {
+		byID[adv.ID] = adv
+	}
+
+	ordered := []*advert.Advert{}
+	for _, id := range ids {
+		if adv, ok := byID[id]; ok {
+			ordered = append(ordered, adv)
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+)
+
+type GetMessages struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewGetMessages(conversationRepo conversation.Repository) GetMessages {
+	return GetMessages{ConversationRepo: conversationRepo}
+}
+
+func (a GetMessages) Execute(ctx context.Context, conversationID uuid.UUID, limit int, offset int) ([]conversation.Message, error) {
+	return a.ConversationRepo.GetMessages(ctx, conversationID, limit, offset)
+}
@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+)
+
+type GetReport struct {
+	ReportRepo report.Repository
+}
+
+func NewGetReport(reportRepo report.Repository) GetReport {
+	return GetReport{ReportRepo: reportRepo}
+}
+
+func (a GetReport) Execute(ctx context.Context, id uuid.UUID) (report.Report, error) {
+	return a.ReportRepo.Get(ctx, id)
+}
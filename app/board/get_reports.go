@@ -0,0 +1,18 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+)
+
+type GetReports struct {
+	ReportRepo report.Repository
+}
+
+func NewGetReports(reportRepo report.Repository) GetReports {
+	return GetReports{ReportRepo: reportRepo}
+}
+
+func (a GetReports) Execute(ctx context.Context, filter report.ListFilter) ([]report.Report, error) {
+	return a.ReportRepo.GetList(ctx, filter)
+}
@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+)
+
+type GetSavedSearches struct {
+	SearchRepo search.Repository
+}
+
+func NewGetSavedSearches(searchRepo search.Repository) GetSavedSearches {
+	return GetSavedSearches{SearchRepo: searchRepo}
+}
+
+func (a GetSavedSearches) Execute(ctx context.Context, userID uuid.UUID) ([]search.SavedSearch, error) {
+	return a.SearchRepo.GetList(ctx, userID)
+}
@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+)
+
+type GetUnreadCounts struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewGetUnreadCounts(conversationRepo conversation.Repository) GetUnreadCounts {
+	return GetUnreadCounts{ConversationRepo: conversationRepo}
+}
+
+func (a GetUnreadCounts) Execute(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error) {
+	return a.ConversationRepo.CountUnread(ctx, userID)
+}
@@ -0,0 +1,23 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+type MarkConversationRead struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewMarkConversationRead(conversationRepo conversation.Repository) MarkConversationRead {
+	return MarkConversationRead{ConversationRepo: conversationRepo}
+}
+
+func (a MarkConversationRead) Execute(ctx context.Context, conv *conversation.Conversation, usr *user.User) error {
+	err := conv.MarkRead(usr)
+	if err != nil {
+		return err
+	}
+	return a.ConversationRepo.Update(ctx, conv)
+}
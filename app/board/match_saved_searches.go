@@ -0,0 +1,38 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+)
+
+// matchSavedSearches stores matches of the published advert, they are sent to the users with the next digest.
+// The advert is stored already, so the failure is reported as search.MatchingFailedErr and may be only logged.
+func matchSavedSearches(ctx context.Context, searchRepo search.Repository, adv *advert.Advert) error {
+	if adv.Status != advert.StatusPublished {
+		return nil
+	}
+
+	candidates, err := searchRepo.GetCandidates(ctx, adv.Details.Type, adv.Details.Title.Languages())
+	if err != nil {
+		return fmt.Errorf("%w: %v", search.MatchingFailedErr, err)
+	}
+
+	var matches []search.Match
+	for _, candidate := range candidates {
+		// authors aren't notified about their own adverts
+		if adv.User != nil && candidate.UserID == adv.User.ID {
+			continue
+		}
+		if candidate.Matches(adv) {
+			matches = append(matches, search.NewMatch(candidate, adv))
+		}
+	}
+
+	err = searchRepo.AddMatches(ctx, matches)
+	if err != nil {
+		return fmt.Errorf("%w: %v", search.MatchingFailedErr, err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+)
+
+type ModerateAdvert struct {
+	AdvertRepo advert.Repository
+	SearchRepo search.Repository
+}
+
+func NewModerateAdvert(advertRepo advert.Repository, searchRepo search.Repository) ModerateAdvert {
+	return ModerateAdvert{AdvertRepo: advertRepo, SearchRepo: searchRepo}
+}
+
+// Execute persists the status changed by advert.Advert.Approve, Reject or Suspend.
+// Approved advert is matched against saved searches like the one published when added.
+func (a ModerateAdvert) Execute(ctx context.Context, advert *advert.Advert) error {
+	err := a.AdvertRepo.Update(ctx, advert)
+	if err != nil {
+		return err
+	}
+	return matchSavedSearches(ctx, a.SearchRepo, advert)
+}
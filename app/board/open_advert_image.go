@@ -0,0 +1,24 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/pkg/blob"
+	"io"
+)
+
+type OpenAdvertImage struct {
+	Storage blob.Storage
+}
+
+func NewOpenAdvertImage(storage blob.Storage) OpenAdvertImage {
+	return OpenAdvertImage{Storage: storage}
+}
+
+// Execute returns the content of the image or its thumbnail, the reader has to be closed by the caller
+func (a OpenAdvertImage) Execute(ctx context.Context, img advert.Image, thumbnail bool) (io.ReadCloser, error) {
+	if thumbnail {
+		return a.Storage.Get(ctx, img.ThumbnailKey())
+	}
+	return a.Storage.Get(ctx, img.Key())
+}
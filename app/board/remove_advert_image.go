@@ -0,0 +1,36 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/blob"
+)
+
+type RemoveAdvertImage struct {
+	AdvertRepo advert.Repository
+	Storage    blob.Storage
+}
+
+func NewRemoveAdvertImage(advertRepo advert.Repository, storage blob.Storage) RemoveAdvertImage {
+	return RemoveAdvertImage{AdvertRepo: advertRepo, Storage: storage}
+}
+
+// Execute detaches the image from the advert and removes its files afterwards,
+// files left after a storage failure aren't referenced anymore, so they are harmless
+func (a RemoveAdvertImage) Execute(ctx context.Context, adv *advert.Advert, editor *user.User, imageID uuid.UUID) error {
+	img, err := adv.RemoveImage(editor, imageID)
+	if err != nil {
+		return err
+	}
+
+	err = a.AdvertRepo.RemoveImage(ctx, adv, img)
+	if err != nil {
+		return err
+	}
+
+	_ = a.Storage.Delete(ctx, img.Key())
+	_ = a.Storage.Delete(ctx, img.ThumbnailKey())
+	return nil
+}
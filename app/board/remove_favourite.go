@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+)
+
+type RemoveFavourite struct {
+	FavouriteRepo favourite.Repository
+}
+
+func NewRemoveFavourite(favouriteRepo favourite.Repository) RemoveFavourite {
+	return RemoveFavourite{FavouriteRepo: favouriteRepo}
+}
+
+func (a RemoveFavourite) Execute(ctx context.Context, userID uuid.UUID, advertID uuid.UUID) error {
+	return a.FavouriteRepo.Remove(ctx, userID, advertID)
+}
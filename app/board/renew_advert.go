@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+)
+
+type RenewAdvert struct {
+	AdvertRepo advert.Repository
+}
+
+func NewRenewAdvert(advertRepo advert.Repository) RenewAdvert {
+	return RenewAdvert{AdvertRepo: advertRepo}
+}
+
+// Execute persists the advert renewed by advert.Advert.Renew
+func (a RenewAdvert) Execute(ctx context.Context, advert *advert.Advert) error {
+	return a.AdvertRepo.Update(ctx, advert)
+}
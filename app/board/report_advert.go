@@ -0,0 +1,94 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+	"github.com/ukrainian-brothers/board-backend/pkg/ratelimit"
+	"time"
+)
+
+// hideAttempts limits how many times hiding of the reported advert is retried when the advert is changed concurrently
+const hideAttempts = 3
+
+type ReportAdvert struct {
+	reportRepo    report.Repository
+	advertRepo    advert.Repository
+	limiter       *ratelimit.Limiter
+	hideThreshold int
+}
+
+// NewReportAdvert creates command which accepts at most reporterLimit reports per reporter within the window.
+// The advert is hidden once hideThreshold distinct reporters have open reports of it, zero threshold never hides adverts.
+func NewReportAdvert(reportRepo report.Repository, advertRepo advert.Repository, hideThreshold int, reporterLimit int, window time.Duration) ReportAdvert {
+	return ReportAdvert{
+		reportRepo:    reportRepo,
+		advertRepo:    advertRepo,
+		limiter:       ratelimit.NewLimiter(reporterLimit, window),
+		hideThreshold: hideThreshold,
+	}
+}
+
+// Execute stores the report, reporterKey identifies the reporter e.g. by session or IP address, so anonymous reports are limited as well
+func (a ReportAdvert) Execute(ctx context.Context, rep *report.Report, reporterKey string) error {
+	if !a.limiter.Allow(reporterKey) {
+		return report.TooManyReportsErr
+	}
+
+	// anonymous reporters are told apart by the address, so a single client can't reach the threshold on its own
+	reported, err := a.reportRepo.HasOpen(ctx, *rep)
+	if err != nil {
+		return err
+	}
+	if reported {
+		return report.AlreadyReportedErr
+	}
+
+	err = a.reportRepo.Add(ctx, rep)
+	if err != nil {
+		return err
+	}
+
+	return a.hideIfReported(ctx, rep)
+}
+
+// hideIfReported suspends the advert once it crossed the threshold, moderators see it among the suspended ones
+func (a ReportAdvert) hideIfReported(ctx context.Context, rep *report.Report) error {
+	if a.hideThreshold <= 0 {
+		return nil
+	}
+
+	reports, err := a.reportRepo.CountOpenReporters(ctx, rep.AdvertID)
+	if err != nil {
+		return err
+	}
+	if reports < a.hideThreshold {
+		return nil
+	}
+
+	// the advert changed by its owner in the meantime is loaded again, so the owner can't revert the hiding
+	for attempt := 1; ; attempt++ {
+		err = a.hide(ctx, rep.AdvertID, reports)
+		if !errors.Is(err, advert.AdvertChangedErr) || attempt == hideAttempts {
+			return err
+		}
+	}
+}
+
+func (a ReportAdvert) hide(ctx context.Context, advertID uuid.UUID, reports int) error {
+	adv, err := a.advertRepo.Get(ctx, advertID)
+	if err != nil {
+		return err
+	}
+	if adv.Status != advert.StatusPublished {
+		return nil
+	}
+
+	err = adv.HideReported(reports)
+	if err != nil {
+		return err
+	}
+	return a.advertRepo.Update(ctx, &adv)
+}
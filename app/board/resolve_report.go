@@ -0,0 +1,19 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+)
+
+type ResolveReport struct {
+	ReportRepo report.Repository
+}
+
+func NewResolveReport(reportRepo report.Repository) ResolveReport {
+	return ResolveReport{ReportRepo: reportRepo}
+}
+
+// Execute persists the resolution made by report.Report.Resolve
+func (a ResolveReport) Execute(ctx context.Context, report *report.Report) error {
+	return a.ReportRepo.Resolve(ctx, report)
+}
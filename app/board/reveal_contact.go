@@ -0,0 +1,53 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/ratelimit"
+	"time"
+)
+
+type RevealContact struct {
+	Repo    advert.Repository
+	Policy  advert.ContactPolicy
+	limiter *ratelimit.Limiter
+	counted *ratelimit.Limiter
+}
+
+// NewRevealContact creates command which reveals at most userLimit contacts per user within the window.
+// Repeated reveals of the same advert by the user within the window are counted once.
+func NewRevealContact(advertRepo advert.Repository, policy advert.ContactPolicy, userLimit int, window time.Duration) RevealContact {
+	return RevealContact{
+		Repo:    advertRepo,
+		Policy:  policy,
+		limiter: ratelimit.NewLimiter(userLimit, window),
+		counted: ratelimit.NewLimiter(1, window),
+	}
+}
+
+// Execute returns the contact of the advert, owners see their own contact without limits and without being counted
+func (a RevealContact) Execute(ctx context.Context, adv *advert.Advert, usr *user.User) (domain.ContactDetails, error) {
+	contact, err := adv.RevealContact(usr)
+	if err != nil {
+		return domain.ContactDetails{}, err
+	}
+	if adv.IsOwnedBy(usr) {
+		return contact, nil
+	}
+
+	if !a.limiter.Allow(usr.ID.String()) {
+		return domain.ContactDetails{}, advert.TooManyContactRevealsErr
+	}
+
+	if a.counted.Allow(fmt.Sprintf("%s:%s", adv.ID, usr.ID)) {
+		err = a.Repo.IncrementContactReveals(ctx, adv.ID)
+		if err != nil {
+			return domain.ContactDetails{}, err
+		}
+		adv.Details.ContactReveals++
+	}
+	return contact, nil
+}
@@ -0,0 +1,26 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+)
+
+type SaveSearch struct {
+	SearchRepo search.Repository
+}
+
+func NewSaveSearch(searchRepo search.Repository) SaveSearch {
+	return SaveSearch{SearchRepo: searchRepo}
+}
+
+func (a SaveSearch) Execute(ctx context.Context, savedSearch *search.SavedSearch) error {
+	count, err := a.SearchRepo.Count(ctx, savedSearch.UserID)
+	if err != nil {
+		return err
+	}
+	if count >= search.MaxSavedSearches {
+		return search.TooManySearchesErr
+	}
+
+	return a.SearchRepo.Add(ctx, savedSearch)
+}
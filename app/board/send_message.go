@@ -0,0 +1,28 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+type SendMessage struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewSendMessage(conversationRepo conversation.Repository) SendMessage {
+	return SendMessage{ConversationRepo: conversationRepo}
+}
+
+func (a SendMessage) Execute(ctx context.Context, conv *conversation.Conversation, sender *user.User, body string) (*conversation.Message, error) {
+	msg, err := conv.AddMessage(sender, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.ConversationRepo.AddMessage(ctx, conv, msg)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
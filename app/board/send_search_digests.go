@@ -0,0 +1,45 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	"time"
+)
+
+type SendSearchDigests struct {
+	SearchRepo search.Repository
+	Notifier   search.Notifier
+	// MaxMatches limits matches handled by a single run, the rest of them are sent with the next one
+	MaxMatches int
+}
+
+func NewSendSearchDigests(searchRepo search.Repository, notifier search.Notifier, maxMatches int) SendSearchDigests {
+	return SendSearchDigests{SearchRepo: searchRepo, Notifier: notifier, MaxMatches: maxMatches}
+}
+
+// Execute sends one digest per user with all matches collected since the previous run and returns the number of sent digests.
+// Failed digests are retried by the next run, the first failure is returned after all users have been handled.
+func (a SendSearchDigests) Execute(ctx context.Context) (int, error) {
+	matches, err := a.SearchRepo.GetPendingMatches(ctx, a.MaxMatches)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var firstErr error
+	for _, digest := range search.NewDigests(matches) {
+		err := a.Notifier.Notify(ctx, digest)
+		if err == nil {
+			err = a.SearchRepo.MarkNotified(ctx, digest.Matches, time.Now())
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed sending digest to user %s: %w", digest.UserID, err)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, firstErr
+}
@@ -0,0 +1,22 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+type SetUserLanguage struct {
+	repo user.Repository
+}
+
+func NewSetUserLanguage(userRepo user.Repository) SetUserLanguage {
+	return SetUserLanguage{repo: userRepo}
+}
+
+func (s SetUserLanguage) Execute(ctx context.Context, usr *user.User, tag string) error {
+	err := usr.SetLanguage(tag)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateLanguage(ctx, usr.ID, usr.Language)
+}
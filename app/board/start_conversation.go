@@ -0,0 +1,56 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+type StartConversation struct {
+	ConversationRepo conversation.Repository
+}
+
+func NewStartConversation(conversationRepo conversation.Repository) StartConversation {
+	return StartConversation{ConversationRepo: conversationRepo}
+}
+
+// Execute sends the first message of the seeker about the advert, the message is added to the existing conversation
+// if they have already talked, so the owner gets one thread per seeker and the block can't be bypassed
+func (a StartConversation) Execute(ctx context.Context, adv *advert.Advert, seeker *user.User, body string) (*conversation.Conversation, *conversation.Message, error) {
+	if seeker == nil {
+		return nil, nil, conversation.NoUserProvidedErr
+	}
+
+	existing, err := a.ConversationRepo.GetByAdvert(ctx, adv.ID, seeker.ID)
+	if err == nil {
+		msg, err := existing.AddMessage(seeker, body)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = a.ConversationRepo.AddMessage(ctx, &existing, msg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &existing, msg, nil
+	}
+	if !errors.Is(err, conversation.ConversationNotFound) {
+		return nil, nil, err
+	}
+
+	conv, err := conversation.NewConversation(adv, seeker)
+	if err != nil {
+		return nil, nil, err
+	}
+	msg, err := conv.AddMessage(seeker, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = a.ConversationRepo.Add(ctx, conv, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conv, msg, nil
+}
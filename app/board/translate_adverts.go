@@ -0,0 +1,87 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/pkg/translation"
+)
+
+type TranslateAdverts struct {
+	AdvertRepo advert.Repository
+	Translator translation.Translator
+	// MaxAdverts limits adverts translated by a single run, the rest of them are translated by the next one
+	MaxAdverts int
+}
+
+func NewTranslateAdverts(advertRepo advert.Repository, translator translation.Translator, maxAdverts int) TranslateAdverts {
+	return TranslateAdverts{AdvertRepo: advertRepo, Translator: translator, MaxAdverts: maxAdverts}
+}
+
+// Execute fills missing supported languages of the adverts waiting for translation and returns the number of translated adverts.
+// Failed adverts are retried by the next runs until advert.MaxTranslationAttempts is reached, the first failure is returned
+// after all adverts have been handled.
+func (a TranslateAdverts) Execute(ctx context.Context) (int, error) {
+	adverts, err := a.AdvertRepo.GetUntranslated(ctx, a.MaxAdverts)
+	if err != nil {
+		return 0, err
+	}
+
+	translated := 0
+	var firstErr error
+	for _, adv := range adverts {
+		err := a.translate(ctx, adv)
+		if err == nil {
+			err = a.AdvertRepo.SaveTranslations(ctx, adv)
+		}
+		// the advert changed in the meantime is translated again by the next run
+		if errors.Is(err, advert.TranslationOutdatedErr) {
+			continue
+		}
+		if err != nil {
+			failErr := a.AdvertRepo.FailTranslation(ctx, adv.ID, advert.MaxTranslationAttempts)
+			if failErr != nil {
+				err = fmt.Errorf("%v, recording the failure failed as well: %w", err, failErr)
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed translating advert %s: %w", adv.ID, err)
+			}
+			continue
+		}
+		translated++
+	}
+	return translated, firstErr
+}
+
+// translate translates the text written by the author, languages the translator doesn't support are left missing
+func (a TranslateAdverts) translate(ctx context.Context, adv *advert.Advert) error {
+	source, ok := adv.SourceLanguage()
+	if ok {
+		for _, lang := range adv.MissingLanguages() {
+			title, err := a.Translator.Translate(ctx, adv.Details.Title[source], source, lang)
+			if errors.Is(err, translation.UnsupportedLanguageErr) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			description, err := a.Translator.Translate(ctx, adv.Details.Description[source], source, lang)
+			if errors.Is(err, translation.UnsupportedLanguageErr) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			err = adv.AddMachineTranslation(lang, title, description)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	adv.MarkTranslated()
+	return nil
+}
@@ -0,0 +1,24 @@
+package board
+
+import (
+	"context"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+)
+
+type UpdateAdvert struct {
+	AdvertRepo advert.Repository
+	Moderation advert.ModerationPolicy
+}
+
+func NewUpdateAdvert(advertRepo advert.Repository, moderation advert.ModerationPolicy) UpdateAdvert {
+	return UpdateAdvert{AdvertRepo: advertRepo, Moderation: moderation}
+}
+
+// Execute stores the advert changed by its owner, the published one waits for the review again if the owner requires it
+func (a UpdateAdvert) Execute(ctx context.Context, adv *advert.Advert) error {
+	err := adv.ReviewChanges(a.Moderation)
+	if err != nil {
+		return err
+	}
+	return a.AdvertRepo.Update(ctx, adv)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"time"
+)
+
+// runDigestWorker sends digests of saved searches matches every interval until the context is cancelled
+func runDigestWorker(ctx context.Context, logger *log.Entry, send board.SendSearchDigests, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sendSearchDigests(ctx, logger, send)
+	}
+}
+
+func sendSearchDigests(ctx context.Context, logger *log.Entry, send board.SendSearchDigests) {
+	sent, err := send.Execute(ctx)
+	if err != nil {
+		logger.WithError(err).Error("failed sending saved searches digests")
+	}
+
+	if sent > 0 {
+		logger.WithField("digests", sent).Info("sent saved searches digests")
+	}
+}
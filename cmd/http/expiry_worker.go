@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"time"
+)
+
+// runExpiryWorker archives expired adverts every interval until the context is cancelled
+func runExpiryWorker(ctx context.Context, logger *log.Entry, archive board.ArchiveExpiredAdverts, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		archiveExpiredAdverts(ctx, logger, archive)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func archiveExpiredAdverts(ctx context.Context, logger *log.Entry, archive board.ArchiveExpiredAdverts) {
+	ids, err := archive.Execute(ctx)
+	if err != nil {
+		logger.WithError(err).Error("failed archiving expired adverts")
+		return
+	}
+
+	if len(ids) > 0 {
+		logger.WithField("adverts", len(ids)).Info("archived expired adverts")
+	}
+}
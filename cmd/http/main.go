@@ -1,19 +1,54 @@
 package main
 
 import (
+	"context"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	log "github.com/sirupsen/logrus"
 	"github.com/ukrainian-brothers/board-backend/api"
 	application "github.com/ukrainian-brothers/board-backend/app"
 	"github.com/ukrainian-brothers/board-backend/app/board"
+	advert_domain "github.com/ukrainian-brothers/board-backend/domain/advert"
 	"github.com/ukrainian-brothers/board-backend/internal/advert"
 	"github.com/ukrainian-brothers/board-backend/internal/common"
+	"github.com/ukrainian-brothers/board-backend/internal/conversation"
+	"github.com/ukrainian-brothers/board-backend/internal/favourite"
+	"github.com/ukrainian-brothers/board-backend/internal/report"
+	"github.com/ukrainian-brothers/board-backend/internal/search"
 	"github.com/ukrainian-brothers/board-backend/internal/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/blob"
+	"github.com/ukrainian-brothers/board-backend/pkg/clientip"
+	"github.com/ukrainian-brothers/board-backend/pkg/translation"
 	"net/http"
 	"time"
 )
 
+const (
+	// advertViewsWindow is the time within which repeated views of the same advert by one viewer are counted once
+	advertViewsWindow = 30 * time.Minute
+	// advertsExpiryInterval is how often expired adverts are archived
+	advertsExpiryInterval = 10 * time.Minute
+	// reportsPerReporter limits reports sent by one reporter within reportsWindow, so anonymous users can't flood moderators
+	reportsPerReporter = 5
+	reportsWindow      = time.Hour
+	// contactRevealsPerUser limits contacts revealed by one user within contactRevealsWindow, so the board can't be scraped
+	contactRevealsPerUser = 20
+	contactRevealsWindow  = time.Hour
+	// defaultReportsHideThreshold is used when the threshold is not configured
+	defaultReportsHideThreshold = 5
+	// defaultImagesDir is used when the images storage directory is not configured
+	defaultImagesDir = "storage/images"
+	// searchDigestInterval is how often users get the digest of new adverts matching their saved searches
+	searchDigestInterval = time.Hour
+	// maxDigestMatches limits matches sent by a single run of the digest worker
+	maxDigestMatches = 5000
+	// translationInterval is how often missing languages of new adverts are machine translated
+	translationInterval = time.Minute
+	// maxTranslatedAdverts limits adverts translated by a single run of the translation worker
+	maxTranslatedAdverts = 50
+	translationTimeout   = 30 * time.Second
+)
+
 func main() {
 	logger := log.NewEntry(log.New())
 
@@ -22,6 +57,12 @@ func main() {
 		log.WithError(err).Fatal("failed initializing config")
 	}
 
+	languages, err := translation.NewRegistry(cfg.Languages.Supported, cfg.Languages.Aliases)
+	if err != nil {
+		log.WithError(err).Fatal("failed initializing languages registry")
+	}
+	translation.Configure(languages)
+
 	db, err := common.InitPostgres(&cfg.Postgres)
 	if err != nil {
 		log.WithError(err).Fatal("failed initializing postgres")
@@ -29,28 +70,101 @@ func main() {
 
 	userRepo := user.NewPostgresUserRepository(db)
 	advertRepo := advert.NewPostgresAdvertRepository(db)
+	advertLogRepo := advert.NewPostgresAdvertLogRepository(db)
+	reportRepo := report.NewPostgresReportRepository(db)
+	favouriteRepo := favourite.NewPostgresFavouriteRepository(db)
+	searchRepo := search.NewPostgresSearchRepository(db)
+	conversationRepo := conversation.NewPostgresConversationRepository(db)
+	imagesDir := cfg.Images.StorageDir
+	if imagesDir == "" {
+		imagesDir = defaultImagesDir
+	}
+	imageStorage, err := blob.NewLocalStorage(imagesDir)
+	if err != nil {
+		log.WithError(err).Fatal("failed initializing images storage")
+	}
+	translator := translation.NewLibreTranslate(cfg.Translation.URL, cfg.Translation.APIKey, &http.Client{Timeout: translationTimeout})
+	moderationPolicy := advert_domain.ModerationPolicy{AutoPublishTrusted: cfg.Moderation.AutoPublishTrusted}
+	contactPolicy := advert_domain.ContactPolicy{Protected: cfg.Contacts.Protected}
+	reportsHideThreshold := defaultReportsHideThreshold
+	if cfg.Moderation.ReportsHideThreshold != nil {
+		reportsHideThreshold = *cfg.Moderation.ReportsHideThreshold
+	}
 
 	app := application.Application{
 		Commands: application.Commands{
-			AddUser:   board.NewAddUser(userRepo),
-			AddAdvert: board.NewAddAdvert(advertRepo),
+			AddUser:               board.NewAddUser(userRepo),
+			AddAdvert:             board.NewAddAdvert(advertRepo, searchRepo, moderationPolicy),
+			UpdateAdvert:          board.NewUpdateAdvert(advertRepo, moderationPolicy),
+			DeleteAdvert:          board.NewDeleteAdvert(advertRepo),
+			RenewAdvert:           board.NewRenewAdvert(advertRepo),
+			ModerateAdvert:        board.NewModerateAdvert(advertRepo, searchRepo),
+			AttachAdvertImage:     board.NewAttachAdvertImage(advertRepo, imageStorage, moderationPolicy),
+			RemoveAdvertImage:     board.NewRemoveAdvertImage(advertRepo, imageStorage),
+			ReportAdvert:          board.NewReportAdvert(reportRepo, advertRepo, reportsHideThreshold, reportsPerReporter, reportsWindow),
+			ResolveReport:         board.NewResolveReport(reportRepo),
+			AddFavourite:          board.NewAddFavourite(favouriteRepo),
+			RemoveFavourite:       board.NewRemoveFavourite(favouriteRepo),
+			SaveSearch:            board.NewSaveSearch(searchRepo),
+			DeleteSavedSearch:     board.NewDeleteSavedSearch(searchRepo),
+			SendSearchDigests:     board.NewSendSearchDigests(searchRepo, search.NewLogNotifier(logger.WithField("notifier", "log")), maxDigestMatches),
+			StartConversation:     board.NewStartConversation(conversationRepo),
+			SendMessage:           board.NewSendMessage(conversationRepo),
+			MarkConversationRead:  board.NewMarkConversationRead(conversationRepo),
+			BlockConversation:     board.NewBlockConversation(conversationRepo),
+			TranslateAdverts:      board.NewTranslateAdverts(advertRepo, translator, maxTranslatedAdverts),
+			ArchiveExpiredAdverts: board.NewArchiveExpiredAdverts(advertRepo),
+			CountAdvertView:       board.NewCountAdvertView(advertRepo, advertViewsWindow),
+			RevealContact:         board.NewRevealContact(advertRepo, contactPolicy, contactRevealsPerUser, contactRevealsWindow),
 		},
 		Queries: application.Queries{
 			UserExists:         board.NewUserExists(userRepo),
 			GetUserByLogin:     board.NewGetUserByLogin(userRepo),
 			VerifyUserPassword: board.NewVerifyUserPassword(userRepo),
+			GetAdvert:          board.NewGetAdvert(advertRepo),
+			GetAdvertHistory:   board.NewGetAdvertHistory(advertLogRepo),
+			OpenAdvertImage:    board.NewOpenAdvertImage(imageStorage),
 			GetAdvertsList:     board.NewGetAdvertsList(advertRepo),
+			GetReport:          board.NewGetReport(reportRepo),
+			GetReports:         board.NewGetReports(reportRepo),
+			GetFavourites:      board.NewGetFavourites(favouriteRepo, advertRepo),
+			AreFavourite:       board.NewAreFavourite(favouriteRepo),
+			GetSavedSearches:   board.NewGetSavedSearches(searchRepo),
+			GetConversation:    board.NewGetConversation(conversationRepo),
+			GetConversations:   board.NewGetConversations(conversationRepo),
+			GetMessages:        board.NewGetMessages(conversationRepo),
+			GetUnreadCounts:    board.NewGetUnreadCounts(conversationRepo),
 		},
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runExpiryWorker(ctx, logger.WithField("worker", "expiry"), app.Commands.ArchiveExpiredAdverts, advertsExpiryInterval)
+	go runDigestWorker(ctx, logger.WithField("worker", "digest"), app.Commands.SendSearchDigests, searchDigestInterval)
+	if cfg.Translation.URL != "" {
+		go runTranslationWorker(ctx, logger.WithField("worker", "translation"), app.Commands.TranslateAdverts, translationInterval)
+	}
+
 	sessionStore := sessions.NewCookieStore([]byte(cfg.Session.Secret))
 	middleware := api.NewMiddlewareProvider(sessionStore, &app, cfg)
+	clientIPResolver, err := clientip.NewResolver(cfg.Proxy.TrustedProxies, cfg.Proxy.ClientIPHeader)
+	if err != nil {
+		log.WithError(err).Fatal("failed initializing client address resolver")
+	}
 
 	router := mux.NewRouter()
 	router.Use(middleware.BodyLimitMiddleware)
+	router.Use(middleware.ClientIPMiddleware(clientIPResolver))
 	router.Use(middleware.LoggingMiddleware(logger))
+	router.Use(middleware.LanguageMiddleware)
 	api.NewUserAPI(router, logger, app, middleware, sessionStore, cfg)
 	api.NewAdvertAPI(router, logger, app, middleware, sessionStore, cfg)
+	api.NewModerationAPI(router, logger, app, middleware)
+	api.NewReportAPI(router, logger, app, middleware)
+	api.NewImageAPI(router, logger, app, middleware, cfg)
+	api.NewFavouriteAPI(router, logger, app, middleware)
+	api.NewSearchAPI(router, logger, app, middleware)
+	api.NewConversationAPI(router, logger, app, middleware)
 
 	srv := &http.Server{
 		Handler:      router,
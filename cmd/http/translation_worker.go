@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/ukrainian-brothers/board-backend/app/board"
+	"time"
+)
+
+// runTranslationWorker fills missing languages of new adverts every interval until the context is cancelled
+func runTranslationWorker(ctx context.Context, logger *log.Entry, translate board.TranslateAdverts, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		translateAdverts(ctx, logger, translate)
+	}
+}
+
+func translateAdverts(ctx context.Context, logger *log.Entry, translate board.TranslateAdverts) {
+	translated, err := translate.Execute(ctx)
+	if err != nil {
+		logger.WithError(err).Error("failed translating adverts")
+	}
+
+	if translated > 0 {
+		logger.WithField("adverts", translated).Info("translated adverts")
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
-import . "github.com/ukrainian-brothers/board-backend/pkg/translation"
+import (
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"time"
+)
 
 type AdvertType string
 
@@ -11,10 +14,49 @@ const (
 	AdvertTypeJob         AdvertType = "job"
 )
 
+var advertTypes = []AdvertType{
+	AdvertTypeTransport, AdvertTypeLawyer, AdvertTypePlaceToStay, AdvertTypeJob,
+}
+
+const day = 24 * time.Hour
+
+// advertLifetimes tells how long the advert of given type is listed before it expires, offers like transport get stale quickly
+var advertLifetimes = map[AdvertType]time.Duration{
+	AdvertTypeTransport:   7 * day,
+	AdvertTypePlaceToStay: 14 * day,
+	AdvertTypeLawyer:      30 * day,
+	AdvertTypeJob:         30 * day,
+}
+
+const defaultAdvertLifetime = 14 * day
+
+func (t AdvertType) Lifetime() time.Duration {
+	lifetime, ok := advertLifetimes[t]
+	if !ok {
+		return defaultAdvertLifetime
+	}
+	return lifetime
+}
+
+func (t AdvertType) IsValid() bool {
+	for _, advertType := range advertTypes {
+		if t == advertType {
+			return true
+		}
+	}
+	return false
+}
+
 type AdvertDetails struct {
 	Title          MultilingualString
 	Description    MultilingualString
 	Type           AdvertType
 	Views          int
 	ContactDetails ContactDetails
+	Location       *Location
+	Attributes     AdvertAttributes
+	// ContactReveals counts users who revealed the protected contact, it's shown only to the owner
+	ContactReveals int
+	// MachineTranslated lists languages of the title and description filled by machine translation
+	MachineTranslated LanguageTags
 }
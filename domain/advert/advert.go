@@ -1,21 +1,15 @@
 package advert
 
 import (
-	"encoding/json"
 	"errors"
 	"github.com/google/uuid"
 	"github.com/ukrainian-brothers/board-backend/domain"
 	"github.com/ukrainian-brothers/board-backend/domain/user"
 	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+	"reflect"
 	"time"
-)
-
-type AdvertLogTrigger string
-
-const (
-	AdvertCreatedEvent AdvertLogTrigger = "created"
-	AdvertUpdatedEvent AdvertLogTrigger = "updated"
-	AdvertDeletedEvent AdvertLogTrigger = "deleted"
+	"unicode/utf8"
 )
 
 var (
@@ -23,13 +17,14 @@ var (
 	NoUserProvidedErr   = errors.New("no user provided")
 	MissingBasicInfoErr = errors.New("advert is missing basic info")
 	InvalidLanguages    = errors.New("not enough valid languages provided")
+	NotAdvertOwnerErr   = errors.New("user is not the owner of the advert")
 )
 
-type AdvertLog struct {
-	AdvertID uuid.UUID
-	Trigger  AdvertLogTrigger
-	Meta     json.RawMessage
-}
+// MaxTitleLength and MaxDescriptionLength limit the text in a single language, they match adverts_details columns
+const (
+	MaxTitleLength       = 100
+	MaxDescriptionLength = 1000
+)
 
 type Advert struct {
 	ID          uuid.UUID
@@ -38,6 +33,22 @@ type Advert struct {
 	CreatedAt   time.Time
 	UpdatedAt   *time.Time
 	DestroyedAt *time.Time
+	// ExpiresAt is the moment after which the advert is no longer listed, it can be moved forward by Renew
+	ExpiresAt time.Time
+	// ArchivedAt is set when the expired advert is archived by the background worker
+	ArchivedAt *time.Time
+	// Status is changed by moderators, new adverts are pending until approved unless the policy publishes them right away
+	Status Status
+	// ModerationReason explains to the owner why the advert was rejected or suspended
+	ModerationReason string
+	// Images are shown in the order they were attached
+	Images []Image
+	// TranslatedAt is set once missing languages are filled by machine translation, nil means the advert waits for it
+	TranslatedAt *time.Time
+	// Version is the version of the stored advert it was loaded at, the repository increments it on every change
+	Version int
+	// Logs are changes recorded since the advert was created or loaded, they are persisted and cleared by the repository
+	Logs []AdvertLog
 }
 
 type AdvertOption func(advert *Advert) error
@@ -52,12 +63,26 @@ func WithContactDetails(contactDetails domain.ContactDetails) AdvertOption {
 	}
 }
 
+func WithLocation(location domain.Location) AdvertOption {
+	return func(advert *Advert) error {
+		advert.Details.Location = &location
+		return nil
+	}
+}
+
+func WithAttributes(attributes domain.AdvertAttributes) AdvertOption {
+	return func(advert *Advert) error {
+		advert.Details.Attributes = attributes
+		return nil
+	}
+}
+
 func NewAdvert(user *user.User, title MultilingualString, description MultilingualString, advertType domain.AdvertType, opts ...AdvertOption) (*Advert, error) {
 	if user == nil {
 		return nil, NoUserProvidedErr
 	}
 
-	advert := &Advert{ID: uuid.New()}
+	advert := &Advert{ID: uuid.New(), Status: StatusPending}
 
 	for _, option := range opts {
 		err := option(advert)
@@ -66,8 +91,12 @@ func NewAdvert(user *user.User, title MultilingualString, description Multilingu
 		}
 	}
 
-	if title.Empty() || description.Empty() {
-		return nil, MissingBasicInfoErr
+	var errs validation.Errors
+	if title.Empty() {
+		errs.Add(validation.NewFieldError("title", validation.RequiredCode, MissingBasicInfoErr))
+	}
+	if description.Empty() {
+		errs.Add(validation.NewFieldError("description", validation.RequiredCode, MissingBasicInfoErr))
 	}
 	advert.User = user
 
@@ -77,22 +106,205 @@ func NewAdvert(user *user.User, title MultilingualString, description Multilingu
 	advert.Details.Description = description
 	advert.CreatedAt = time.Now()
 
-	validLanguages := 0
+	if len(errs) == 0 {
+		errs = append(errs, textsErrors(title, description)...)
+	}
+
+	advert.Details.Type = advertType
+	advert.ExpiresAt = advert.CreatedAt.Add(advertType.Lifetime())
+
+	if advertType == "" {
+		errs.Add(validation.NewFieldError("type", validation.RequiredCode, InvalidAdvertTypeErr))
+	} else if !advertType.IsValid() {
+		errs.Add(validation.NewFieldError("type", validation.InvalidCode, InvalidAdvertTypeErr))
+	} else if err := advert.Details.Attributes.Validate(advertType); err != nil {
+		// attributes can be checked only against a known type
+		errs.Add(validation.NewFieldError("attributes", validation.InvalidCode, err))
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	if advert.Details.ContactDetails.IsEmpty() {
+		advert.Details.ContactDetails = user.ContactDetails
+	}
+
+	err := advert.recordLog(user, AdvertCreatedEvent, detailsSnapshot(advert.Details))
+	if err != nil {
+		return nil, err
+	}
+
+	return advert, nil
+}
+
+// textsErrors reports texts longer than adverts_details columns and the title and description without common language
+func textsErrors(title MultilingualString, description MultilingualString) validation.Errors {
+	var errs validation.Errors
+	for _, text := range []struct {
+		field     string
+		value     MultilingualString
+		maxLength int
+	}{{"title", title, MaxTitleLength}, {"description", description, MaxDescriptionLength}} {
+		for _, lang := range SupportedLanguages() {
+			if utf8.RuneCountInString(text.value[lang]) > text.maxLength {
+				fieldErr := validation.NewFieldError(text.field+"."+string(lang), validation.TooLongCode, InvalidTextErr)
+				errs.Add(fieldErr.With("language", lang).With("max_length", text.maxLength))
+			}
+		}
+	}
+
+	if !hasCommonLanguage(title, description) {
+		errs.Add(validation.NewFieldError("description", validation.NoCommonLanguageCode, InvalidLanguages))
+	}
+	return errs
+}
+
+// hasCommonLanguage reports whether there is at least one language in which both title and description are present.
+func hasCommonLanguage(title MultilingualString, description MultilingualString) bool {
 	for lang := range title {
 		_, ok := description[lang]
 		if ok {
-			validLanguages = validLanguages + 1
+			return true
 		}
 	}
-	if validLanguages == 0 {
-		return nil, InvalidLanguages
+	return false
+}
+
+func (a Advert) IsOwnedBy(usr *user.User) bool {
+	if usr == nil || a.User == nil {
+		return false
 	}
+	return a.User.ID == usr.ID
+}
 
-	advert.Details.Type = advertType
+// Update applies changes made by the editor. Empty title, description or type keep their current values,
+// so it can be used for both full and partial updates.
+func (a *Advert) Update(editor *user.User, title MultilingualString, description MultilingualString, advertType domain.AdvertType, opts ...AdvertOption) error {
+	if editor == nil {
+		return NoUserProvidedErr
+	}
 
-	if advert.Details.ContactDetails.IsEmpty() {
-		advert.Details.ContactDetails = user.ContactDetails
+	if !a.IsOwnedBy(editor) {
+		return NotAdvertOwnerErr
 	}
 
-	return advert, nil
+	// work on a copy, so the advert stays untouched if any of the changes is invalid
+	updated := *a
+	for _, option := range opts {
+		err := option(&updated)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !title.Empty() || !description.Empty() {
+		// machine translations of the previous text are outdated, they are made again by the translation job
+		updated.Details.Title = withoutMachineTranslations(updated.Details.Title, a.Details.MachineTranslated)
+		updated.Details.Description = withoutMachineTranslations(updated.Details.Description, a.Details.MachineTranslated)
+		updated.Details.MachineTranslated = nil
+		updated.TranslatedAt = nil
+	}
+
+	if !title.Empty() {
+		title.RemoveUnsupported()
+		updated.Details.Title = title
+	}
+
+	if !description.Empty() {
+		description.RemoveUnsupported()
+		updated.Details.Description = description
+	}
+
+	if err := textsErrors(updated.Details.Title, updated.Details.Description).Err(); err != nil {
+		return err
+	}
+
+	if advertType != "" && !advertType.IsValid() {
+		return validation.Errors{validation.NewFieldError("type", validation.InvalidCode, InvalidAdvertTypeErr)}
+	}
+
+	if advertType != "" && advertType != updated.Details.Type {
+		// attributes of the previous type make no sense anymore, unless new ones were given
+		if reflect.DeepEqual(updated.Details.Attributes, a.Details.Attributes) {
+			updated.Details.Attributes = domain.AdvertAttributes{}
+		}
+		updated.Details.Type = advertType
+	}
+
+	err := updated.Details.Attributes.Validate(updated.Details.Type)
+	if err != nil {
+		return validation.Errors{validation.NewFieldError("attributes", validation.InvalidCode, err)}
+	}
+
+	now := time.Now()
+	updated.UpdatedAt = &now
+
+	err = updated.recordLog(editor, AdvertUpdatedEvent, detailsDiff(a.Details, updated.Details))
+	if err != nil {
+		return err
+	}
+
+	// the rejected advert fixed by the owner waits for the review again
+	if updated.Status == StatusRejected {
+		err = updated.changeStatus(editor, StatusPending, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	*a = updated
+	return nil
+}
+
+// MergeTexts merges texts of the partial update into the texts written by the author, so Update keeps the languages
+// which weren't sent. Machine translations are left out, they are made again for the changed text.
+func (a Advert) MergeTexts(title MultilingualString, description MultilingualString) (MultilingualString, MultilingualString) {
+	if title.Empty() && description.Empty() {
+		return title, description
+	}
+
+	mergedTitle := withoutMachineTranslations(a.Details.Title, a.Details.MachineTranslated)
+	for lang, text := range title {
+		mergedTitle[lang] = text
+	}
+
+	mergedDescription := withoutMachineTranslations(a.Details.Description, a.Details.MachineTranslated)
+	for lang, text := range description {
+		mergedDescription[lang] = text
+	}
+	return mergedTitle, mergedDescription
+}
+
+// Destroy marks the advert as removed by the editor. Adverts are never removed physically, so they can still be audited.
+func (a *Advert) Destroy(editor *user.User) error {
+	if editor == nil {
+		return NoUserProvidedErr
+	}
+
+	if !a.IsOwnedBy(editor) {
+		return NotAdvertOwnerErr
+	}
+
+	now := time.Now()
+	a.DestroyedAt = &now
+	return a.recordLog(editor, AdvertDeletedEvent, nil)
+}
+
+// Renew lists the advert again for the lifetime of its type counting from now
+func (a *Advert) Renew(editor *user.User) error {
+	if editor == nil {
+		return NoUserProvidedErr
+	}
+
+	if !a.IsOwnedBy(editor) {
+		return NotAdvertOwnerErr
+	}
+
+	previous := a.ExpiresAt
+	a.ExpiresAt = time.Now().Add(a.Details.Type.Lifetime())
+	a.ArchivedAt = nil
+	return a.recordLog(editor, AdvertRenewedEvent, map[string]FieldChange{
+		"expires_at": {Old: previous, New: a.ExpiresAt},
+	})
 }
@@ -11,12 +11,17 @@ func Assert(t *testing.T, expected *Advert, actual *Advert) {
 	assert.Equal(t, expected.CreatedAt, actual.CreatedAt)
 	assert.Equal(t, expected.UpdatedAt, actual.UpdatedAt)
 	assert.Equal(t, expected.DestroyedAt, actual.DestroyedAt)
+	assert.Equal(t, expected.Status, actual.Status)
+	assert.Equal(t, expected.ModerationReason, actual.ModerationReason)
 	assert.Equal(t, expected.Details, actual.Details)
 	assert.Equal(t, expected.Details.Title, actual.Details.Title)
 	assert.Equal(t, expected.Details.Description, actual.Details.Description)
 	assert.Equal(t, expected.Details.Type, actual.Details.Type)
 	assert.Equal(t, expected.Details.Views, actual.Details.Views)
+	assert.Equal(t, expected.Details.ContactReveals, actual.Details.ContactReveals)
 	assert.Equal(t, expected.Details.ContactDetails, actual.Details.ContactDetails)
 	assert.Equal(t, expected.Details.ContactDetails.Mail, actual.Details.ContactDetails.Mail)
 	assert.Equal(t, expected.Details.ContactDetails.PhoneNumber, actual.Details.ContactDetails.PhoneNumber)
+	assert.Equal(t, expected.Details.Location, actual.Details.Location)
+	assert.Equal(t, expected.Details.Attributes, actual.Details.Attributes)
 }
@@ -0,0 +1,118 @@
+package advert
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"reflect"
+	"time"
+)
+
+type AdvertLogTrigger string
+
+const (
+	AdvertCreatedEvent AdvertLogTrigger = "created"
+	AdvertUpdatedEvent AdvertLogTrigger = "updated"
+	AdvertDeletedEvent AdvertLogTrigger = "deleted"
+	AdvertRenewedEvent AdvertLogTrigger = "renewed"
+	// AdvertArchivedEvent is triggered by the system, so its UserID is uuid.Nil
+	AdvertArchivedEvent AdvertLogTrigger = "archived"
+	// AdvertModeratedEvent records the status change together with the reason given by the moderator,
+	// its UserID is uuid.Nil when the advert was hidden automatically after reports
+	AdvertModeratedEvent AdvertLogTrigger = "moderated"
+)
+
+type AdvertLog struct {
+	ID       uuid.UUID
+	AdvertID uuid.UUID
+	// UserID is the user who triggered the change
+	UserID    uuid.UUID
+	Trigger   AdvertLogTrigger
+	Meta      json.RawMessage
+	CreatedAt time.Time
+}
+
+// FieldChange is a single entry of the diff stored in updated AdvertLog meta
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+type AdvertHistory struct {
+	AdvertID uuid.UUID
+	OwnerID  uuid.UUID
+	Logs     []AdvertLog
+}
+
+func (h AdvertHistory) CanBeViewedBy(usr *user.User) bool {
+	if usr == nil {
+		return false
+	}
+	return usr.IsAdmin() || usr.ID == h.OwnerID
+}
+
+// NewArchivedLog creates the log of the advert archived by the system after it expired
+func NewArchivedLog(advertID uuid.UUID, archivedAt time.Time) AdvertLog {
+	return AdvertLog{
+		ID:        uuid.New(),
+		AdvertID:  advertID,
+		UserID:    uuid.Nil,
+		Trigger:   AdvertArchivedEvent,
+		CreatedAt: archivedAt,
+	}
+}
+
+func (a *Advert) recordLog(usr *user.User, trigger AdvertLogTrigger, meta interface{}) error {
+	var rawMeta json.RawMessage
+	if meta != nil {
+		var err error
+		rawMeta, err = json.Marshal(meta)
+		if err != nil {
+			return fmt.Errorf("failed marshaling %s advert log meta: %w", trigger, err)
+		}
+	}
+
+	// changes made by the system are recorded with uuid.Nil, the same as archived logs
+	userID := uuid.Nil
+	if usr != nil {
+		userID = usr.ID
+	}
+
+	a.Logs = append(a.Logs, AdvertLog{
+		ID:        uuid.New(),
+		AdvertID:  a.ID,
+		UserID:    userID,
+		Trigger:   trigger,
+		Meta:      rawMeta,
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func detailsSnapshot(details domain.AdvertDetails) map[string]interface{} {
+	return map[string]interface{}{
+		"title":           details.Title,
+		"description":     details.Description,
+		"type":            details.Type,
+		"contact_details": details.ContactDetails,
+		"location":        details.Location,
+		"attributes":      details.Attributes,
+	}
+}
+
+// detailsDiff returns only the fields which have been changed
+func detailsDiff(before domain.AdvertDetails, after domain.AdvertDetails) map[string]FieldChange {
+	oldFields := detailsSnapshot(before)
+	newFields := detailsSnapshot(after)
+
+	diff := map[string]FieldChange{}
+	for field, oldValue := range oldFields {
+		newValue := newFields[field]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diff[field] = FieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	return diff
+}
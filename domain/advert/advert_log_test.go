@@ -0,0 +1,63 @@
+package advert
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"testing"
+)
+
+func TestAdvertLogs(t *testing.T) {
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", domain.ContactDetails{
+		Mail:        newStringPtr("mail"),
+		PhoneNumber: newStringPtr("phone"),
+	})
+	require.NoError(t, err)
+
+	adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport)
+	require.NoError(t, err)
+	require.Len(t, adv.Logs, 1)
+	assert.Equal(t, AdvertCreatedEvent, adv.Logs[0].Trigger)
+	assert.Equal(t, owner.ID, adv.Logs[0].UserID)
+	assert.Equal(t, adv.ID, adv.Logs[0].AdvertID)
+
+	err = adv.Update(owner, MultilingualString{English: "y"}, nil, domain.AdvertTypeJob)
+	require.NoError(t, err)
+	require.Len(t, adv.Logs, 2)
+	assert.Equal(t, AdvertUpdatedEvent, adv.Logs[1].Trigger)
+
+	diff := map[string]FieldChange{}
+	require.NoError(t, json.Unmarshal(adv.Logs[1].Meta, &diff))
+	assert.Equal(t, map[string]FieldChange{
+		"title": {
+			Old: map[string]interface{}{"en": "x"},
+			New: map[string]interface{}{"en": "y"},
+		},
+		"type": {
+			Old: string(domain.AdvertTypeTransport),
+			New: string(domain.AdvertTypeJob),
+		},
+	}, diff)
+
+	err = adv.Destroy(owner)
+	require.NoError(t, err)
+	require.Len(t, adv.Logs, 3)
+	assert.Equal(t, AdvertDeletedEvent, adv.Logs[2].Trigger)
+}
+
+func TestAdvertHistoryCanBeViewedBy(t *testing.T) {
+	owner := &user.User{ID: uuid.New(), Role: user.RoleUser}
+	admin := &user.User{ID: uuid.New(), Role: user.RoleAdmin}
+	stranger := &user.User{ID: uuid.New(), Role: user.RoleUser}
+
+	history := AdvertHistory{AdvertID: uuid.New(), OwnerID: owner.ID}
+	assert.True(t, history.CanBeViewedBy(owner))
+	assert.True(t, history.CanBeViewedBy(admin))
+	assert.False(t, history.CanBeViewedBy(stranger))
+	assert.False(t, history.CanBeViewedBy(nil))
+}
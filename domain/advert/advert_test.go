@@ -2,11 +2,15 @@ package advert
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/ukrainian-brothers/board-backend/domain"
 	"github.com/ukrainian-brothers/board-backend/domain/user"
 	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
 	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+	"strings"
 	"testing"
+	"time"
 )
 
 func newStringPtr(s string) *string {
@@ -104,10 +108,260 @@ func TestAdvertNew(t *testing.T) {
 	for _, tC := range testCases {
 		t.Run(tC.testName, func(t *testing.T) {
 			adv, err := NewAdvert(tC.user, tC.title, tC.description, tC.advertType, tC.opts...)
-			assert.Equal(t, tC.expectations.err, err)
+			assert.ErrorIs(t, err, tC.expectations.err)
 			if err == nil {
 				assert.Equal(t, tC.expectations.contactDetails, adv.Details.ContactDetails)
 			}
 		})
 	}
 }
+
+func TestAdvertUpdate(t *testing.T) {
+	type expectations struct {
+		err         error
+		title       MultilingualString
+		description MultilingualString
+		advertType  domain.AdvertType
+	}
+	type testData struct {
+		testName     string
+		editor       *user.User
+		title        MultilingualString
+		description  MultilingualString
+		advertType   domain.AdvertType
+		opts         []AdvertOption
+		expectations expectations
+	}
+
+	contactDetails := domain.ContactDetails{
+		Mail:        newStringPtr("mail"),
+		PhoneNumber: newStringPtr("phone"),
+	}
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+	stranger, err := user.NewUser("Kamil", "Stoch", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+
+	testCases := []testData{
+		{
+			testName:    "Full update",
+			editor:      owner,
+			title:       MultilingualString{Polish: "tytuł"},
+			description: MultilingualString{Polish: "opis"},
+			advertType:  domain.AdvertTypeJob,
+			expectations: expectations{
+				title:       MultilingualString{Polish: "tytuł"},
+				description: MultilingualString{Polish: "opis"},
+				advertType:  domain.AdvertTypeJob,
+			},
+		},
+		{
+			testName: "Partial update keeps current values",
+			editor:   owner,
+			title:    MultilingualString{English: "new title"},
+			expectations: expectations{
+				title:       MultilingualString{English: "new title"},
+				description: MultilingualString{English: "x"},
+				advertType:  domain.AdvertTypeTransport,
+			},
+		},
+		{
+			testName:   "Unknown type",
+			editor:     owner,
+			advertType: "boat",
+			expectations: expectations{
+				err: InvalidAdvertTypeErr,
+			},
+		},
+		{
+			testName:    "No common language",
+			editor:      owner,
+			description: MultilingualString{Polish: "opis"},
+			expectations: expectations{
+				err: InvalidLanguages,
+			},
+		},
+		{
+			testName: "Empty contact details",
+			editor:   owner,
+			opts:     []AdvertOption{WithContactDetails(domain.ContactDetails{})},
+			expectations: expectations{
+				err: ContactEmptyErr,
+			},
+		},
+		{
+			testName: "Not an owner",
+			editor:   stranger,
+			title:    MultilingualString{English: "new title"},
+			expectations: expectations{
+				err: NotAdvertOwnerErr,
+			},
+		},
+		{
+			testName: "No user provided",
+			editor:   nil,
+			expectations: expectations{
+				err: NoUserProvidedErr,
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.testName, func(t *testing.T) {
+			adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport)
+			assert.NoError(t, err)
+
+			err = adv.Update(tC.editor, tC.title, tC.description, tC.advertType, tC.opts...)
+			assert.ErrorIs(t, err, tC.expectations.err)
+			if err == nil {
+				assert.Equal(t, tC.expectations.title, adv.Details.Title)
+				assert.Equal(t, tC.expectations.description, adv.Details.Description)
+				assert.Equal(t, tC.expectations.advertType, adv.Details.Type)
+				assert.NotNil(t, adv.UpdatedAt)
+			} else {
+				assert.Nil(t, adv.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestAdvertValidationErrors(t *testing.T) {
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", domain.ContactDetails{Mail: newStringPtr("mail")})
+	assert.NoError(t, err)
+
+	_, err = NewAdvert(owner, MultilingualString{English: strings.Repeat("x", MaxTitleLength+1)}, MultilingualString{Polish: "opis"}, domain.AdvertTypeJob,
+		WithAttributes(domain.AdvertAttributes{Transport: &domain.TransportAttributes{}}))
+	assert.ErrorIs(t, err, validation.InvalidErr)
+	assert.ErrorIs(t, err, InvalidLanguages)
+	assert.ErrorIs(t, err, domain.InvalidAttributesErr)
+
+	validationErrors, ok := validation.As(err)
+	require.True(t, ok)
+	var fields []string
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, fieldErr.Field+" "+fieldErr.Code)
+	}
+	assert.Equal(t, []string{"title.en too_long", "description no_common_language", "attributes invalid"}, fields)
+	assert.Equal(t, validation.Params{"language": English, "max_length": MaxTitleLength}, validationErrors[0].Params)
+
+	_, err = NewAdvert(owner, nil, MultilingualString{English: "x"}, domain.AdvertTypeJob)
+	assert.Equal(t, validation.Errors{validation.NewFieldError("title", validation.RequiredCode, MissingBasicInfoErr)}, err)
+
+	_, err = NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, "boat")
+	assert.Equal(t, validation.Errors{validation.NewFieldError("type", validation.InvalidCode, InvalidAdvertTypeErr)}, err)
+
+	_, err = NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, "")
+	assert.Equal(t, validation.Errors{validation.NewFieldError("type", validation.RequiredCode, InvalidAdvertTypeErr)}, err)
+}
+
+func TestAdvertDestroy(t *testing.T) {
+	contactDetails := domain.ContactDetails{
+		Mail:        newStringPtr("mail"),
+		PhoneNumber: newStringPtr("phone"),
+	}
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+	stranger, err := user.NewUser("Kamil", "Stoch", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		testName    string
+		editor      *user.User
+		expectedErr error
+	}{
+		{testName: "Owner", editor: owner},
+		{testName: "Not an owner", editor: stranger, expectedErr: NotAdvertOwnerErr},
+		{testName: "No user provided", editor: nil, expectedErr: NoUserProvidedErr},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.testName, func(t *testing.T) {
+			adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport)
+			assert.NoError(t, err)
+
+			err = adv.Destroy(tC.editor)
+			assert.Equal(t, tC.expectedErr, err)
+			assert.Equal(t, err == nil, adv.DestroyedAt != nil)
+		})
+	}
+}
+
+func TestAdvertMergeTexts(t *testing.T) {
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", domain.ContactDetails{Mail: newStringPtr("mail")})
+	assert.NoError(t, err)
+	adv, err := NewAdvert(owner, MultilingualString{English: "title", Polish: "tytuł"}, MultilingualString{English: "description", Polish: "opis"}, domain.AdvertTypeTransport)
+	assert.NoError(t, err)
+	err = adv.AddMachineTranslation(Ukrainian, "заголовок", "опис")
+	assert.NoError(t, err)
+
+	// nothing sent, nothing to merge
+	title, description := adv.MergeTexts(nil, nil)
+	assert.True(t, title.Empty())
+	assert.True(t, description.Empty())
+
+	// languages which weren't sent are kept, machine translations are dropped
+	title, description = adv.MergeTexts(MultilingualString{Polish: "nowy tytuł"}, nil)
+	assert.Equal(t, MultilingualString{English: "title", Polish: "nowy tytuł"}, title)
+	assert.Equal(t, MultilingualString{English: "description", Polish: "opis"}, description)
+
+	err = adv.Update(owner, title, description, "")
+	assert.NoError(t, err)
+	assert.Equal(t, MultilingualString{English: "title", Polish: "nowy tytuł"}, adv.Details.Title)
+	assert.Empty(t, adv.Details.MachineTranslated)
+}
+
+func TestAdvertAttributes(t *testing.T) {
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", domain.ContactDetails{Mail: newStringPtr("mail")})
+	assert.NoError(t, err)
+	title, description := MultilingualString{English: "x"}, MultilingualString{English: "x"}
+	placeToStay := domain.AdvertAttributes{PlaceToStay: &domain.PlaceToStayAttributes{Capacity: 3}}
+
+	_, err = NewAdvert(owner, title, description, domain.AdvertTypeJob, WithAttributes(placeToStay))
+	assert.ErrorIs(t, err, domain.InvalidAttributesErr)
+
+	adv, err := NewAdvert(owner, title, description, domain.AdvertTypePlaceToStay, WithAttributes(placeToStay))
+	assert.NoError(t, err)
+	assert.Equal(t, placeToStay, adv.Details.Attributes)
+
+	// attributes of the previous type are dropped when the type changes
+	err = adv.Update(owner, nil, nil, domain.AdvertTypeJob)
+	assert.NoError(t, err)
+	assert.True(t, adv.Details.Attributes.Empty())
+
+	job := domain.AdvertAttributes{Job: &domain.JobAttributes{ContractType: domain.ContractTypeContract}}
+	err = adv.Update(owner, nil, nil, "", WithAttributes(job))
+	assert.NoError(t, err)
+	assert.Equal(t, job, adv.Details.Attributes)
+
+	err = adv.Update(owner, nil, nil, "", WithAttributes(placeToStay))
+	assert.ErrorIs(t, err, domain.InvalidAttributesErr)
+	assert.Equal(t, job, adv.Details.Attributes)
+}
+
+func TestAdvertRenew(t *testing.T) {
+	contactDetails := domain.ContactDetails{Mail: newStringPtr("mail")}
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+	stranger, err := user.NewUser("Kamil", "Stoch", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+
+	adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport)
+	assert.NoError(t, err)
+	assert.Equal(t, adv.CreatedAt.Add(7*24*time.Hour), adv.ExpiresAt)
+
+	// expired and archived advert
+	archivedAt := time.Now().Add(-time.Hour)
+	adv.ExpiresAt = archivedAt
+	adv.ArchivedAt = &archivedAt
+	adv.Logs = nil
+
+	assert.Equal(t, NoUserProvidedErr, adv.Renew(nil))
+	assert.Equal(t, NotAdvertOwnerErr, adv.Renew(stranger))
+	assert.Equal(t, archivedAt, adv.ExpiresAt)
+
+	assert.NoError(t, adv.Renew(owner))
+	assert.Nil(t, adv.ArchivedAt)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), adv.ExpiresAt, time.Minute)
+	assert.Len(t, adv.Logs, 1)
+	assert.Equal(t, AdvertRenewedEvent, adv.Logs[0].Trigger)
+}
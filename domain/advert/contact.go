@@ -0,0 +1,34 @@
+package advert
+
+import (
+	"errors"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+)
+
+var TooManyContactRevealsErr = errors.New("too many contacts revealed by the user")
+
+// ContactPolicy decides whether contact details are returned together with the advert
+type ContactPolicy struct {
+	// Protected omits contacts from lists and details, logged in users have to reveal them one advert at a time
+	Protected bool
+}
+
+// ContactVisibleTo tells if the contact of the advert can be shown right away, owners and moderators always see it
+func (p ContactPolicy) ContactVisibleTo(adv Advert, usr *user.User) bool {
+	if !p.Protected || adv.IsOwnedBy(usr) {
+		return true
+	}
+	return usr != nil && usr.IsModerator()
+}
+
+// RevealContact returns the contact of the advert to the logged in user
+func (a Advert) RevealContact(usr *user.User) (domain.ContactDetails, error) {
+	if usr == nil {
+		return domain.ContactDetails{}, NoUserProvidedErr
+	}
+	if a.Details.ContactDetails.IsEmpty() {
+		return domain.ContactDetails{}, ContactEmptyErr
+	}
+	return a.Details.ContactDetails, nil
+}
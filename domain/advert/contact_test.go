@@ -0,0 +1,43 @@
+package advert
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"testing"
+)
+
+func TestContactPolicy(t *testing.T) {
+	owner := &user.User{ID: uuid.New(), Role: user.RoleUser}
+	moderator := &user.User{ID: uuid.New(), Role: user.RoleModerator}
+	stranger := &user.User{ID: uuid.New(), Role: user.RoleUser}
+	adv := Advert{ID: uuid.New(), User: owner, Status: StatusPublished}
+
+	open := ContactPolicy{}
+	assert.True(t, open.ContactVisibleTo(adv, nil))
+	assert.True(t, open.ContactVisibleTo(adv, stranger))
+
+	protected := ContactPolicy{Protected: true}
+	assert.False(t, protected.ContactVisibleTo(adv, nil))
+	assert.False(t, protected.ContactVisibleTo(adv, stranger))
+	assert.True(t, protected.ContactVisibleTo(adv, owner))
+	assert.True(t, protected.ContactVisibleTo(adv, moderator))
+}
+
+func TestAdvertRevealContact(t *testing.T) {
+	mail := "owner@example.com"
+	adv := Advert{ID: uuid.New(), Details: domain.AdvertDetails{ContactDetails: domain.ContactDetails{Mail: &mail}}}
+	stranger := &user.User{ID: uuid.New(), Role: user.RoleUser}
+
+	_, err := adv.RevealContact(nil)
+	assert.ErrorIs(t, err, NoUserProvidedErr)
+
+	contact, err := adv.RevealContact(stranger)
+	assert.NoError(t, err)
+	assert.Equal(t, &mail, contact.Mail)
+
+	adv.Details.ContactDetails = domain.ContactDetails{}
+	_, err = adv.RevealContact(stranger)
+	assert.ErrorIs(t, err, ContactEmptyErr)
+}
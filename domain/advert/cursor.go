@@ -0,0 +1,51 @@
+package advert
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+var InvalidCursorErr = errors.New("invalid cursor")
+
+// Cursor points at the last advert of the page, the next page starts right after it in (created_at, id) order
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func CursorOf(adv *Advert) Cursor {
+	return Cursor{CreatedAt: adv.CreatedAt, ID: adv.ID}
+}
+
+// String encodes the cursor into an opaque token, clients should not rely on its content
+func (c Cursor) String() string {
+	raw := c.CreatedAt.Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, InvalidCursorErr
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return Cursor{}, InvalidCursorErr
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, InvalidCursorErr
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return Cursor{}, InvalidCursorErr
+	}
+
+	return Cursor{CreatedAt: createdAt, ID: id}, nil
+}
@@ -0,0 +1,23 @@
+package advert
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2022, 3, 1, 12, 30, 0, 123456000, time.UTC), ID: uuid.New()}
+
+	parsed, err := ParseCursor(cursor.String())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, cursor.ID, parsed.ID)
+
+	for _, token := range []string{"", "not base64!", "bm8gc2VwYXJhdG9y", cursor.String()[:10]} {
+		_, err := ParseCursor(token)
+		assert.ErrorIs(t, err, InvalidCursorErr, token)
+	}
+}
@@ -0,0 +1,131 @@
+package advert
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"time"
+)
+
+var (
+	TooManyImagesErr = errors.New("advert has too many images")
+	ImageNotFound    = errors.New("image not found")
+)
+
+const MaxImages = 10
+
+// Image is a photo attached to the advert, the processed image and its thumbnail are kept in the blob storage
+type Image struct {
+	ID          uuid.UUID
+	AdvertID    uuid.UUID
+	ContentType string
+	Width       int
+	Height      int
+	CreatedAt   time.Time
+}
+
+func NewImage(advertID uuid.UUID, contentType string, width int, height int) Image {
+	return Image{
+		ID:          uuid.New(),
+		AdvertID:    advertID,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Key is the key of the image in the blob storage
+func (i Image) Key() string {
+	return fmt.Sprintf("adverts/%s/%s", i.AdvertID, i.ID)
+}
+
+func (i Image) ThumbnailKey() string {
+	return fmt.Sprintf("adverts/%s/%s_thumbnail", i.AdvertID, i.ID)
+}
+
+func imageIDs(images []Image) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, img := range images {
+		ids = append(ids, img.ID)
+	}
+	return ids
+}
+
+// FindImage returns the image attached to the advert
+func (a Advert) FindImage(id uuid.UUID) (Image, error) {
+	for _, img := range a.Images {
+		if img.ID == id {
+			return img, nil
+		}
+	}
+	return Image{}, ImageNotFound
+}
+
+// CanAttachImage tells if the editor may attach one more image, so the upload isn't processed in vain
+func (a Advert) CanAttachImage(editor *user.User) error {
+	if editor == nil {
+		return NoUserProvidedErr
+	}
+
+	if !a.IsOwnedBy(editor) {
+		return NotAdvertOwnerErr
+	}
+
+	if len(a.Images) >= MaxImages {
+		return TooManyImagesErr
+	}
+	return nil
+}
+
+func (a *Advert) AttachImage(editor *user.User, img Image) error {
+	err := a.CanAttachImage(editor)
+	if err != nil {
+		return err
+	}
+
+	images := append(append([]Image{}, a.Images...), img)
+	err = a.recordLog(editor, AdvertUpdatedEvent, map[string]FieldChange{
+		"images": {Old: imageIDs(a.Images), New: imageIDs(images)},
+	})
+	if err != nil {
+		return err
+	}
+
+	a.Images = images
+	return nil
+}
+
+// RemoveImage detaches the image from the advert and returns it, so its files can be removed from the storage
+func (a *Advert) RemoveImage(editor *user.User, id uuid.UUID) (Image, error) {
+	if editor == nil {
+		return Image{}, NoUserProvidedErr
+	}
+
+	if !a.IsOwnedBy(editor) {
+		return Image{}, NotAdvertOwnerErr
+	}
+
+	removed, err := a.FindImage(id)
+	if err != nil {
+		return Image{}, err
+	}
+
+	images := []Image{}
+	for _, img := range a.Images {
+		if img.ID != id {
+			images = append(images, img)
+		}
+	}
+
+	err = a.recordLog(editor, AdvertUpdatedEvent, map[string]FieldChange{
+		"images": {Old: imageIDs(a.Images), New: imageIDs(images)},
+	})
+	if err != nil {
+		return Image{}, err
+	}
+
+	a.Images = images
+	return removed, nil
+}
@@ -0,0 +1,53 @@
+package advert
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"testing"
+)
+
+func TestAdvertImages(t *testing.T) {
+	owner := &user.User{ID: uuid.New(), Role: user.RoleUser}
+	stranger := &user.User{ID: uuid.New(), Role: user.RoleUser}
+	adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypePlaceToStay, WithContactDetails(domain.ContactDetails{Mail: newStringPtr("mail")}))
+	assert.NoError(t, err)
+	adv.Logs = nil
+
+	img := NewImage(adv.ID, "image/jpeg", 800, 600)
+	assert.Equal(t, fmt.Sprintf("adverts/%s/%s", adv.ID, img.ID), img.Key())
+	assert.Equal(t, fmt.Sprintf("adverts/%s/%s_thumbnail", adv.ID, img.ID), img.ThumbnailKey())
+
+	assert.Equal(t, NoUserProvidedErr, adv.AttachImage(nil, img))
+	assert.Equal(t, NotAdvertOwnerErr, adv.AttachImage(stranger, img))
+	assert.Empty(t, adv.Images)
+
+	assert.NoError(t, adv.AttachImage(owner, img))
+	found, err := adv.FindImage(img.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, img, found)
+	assert.Len(t, adv.Logs, 1)
+	assert.JSONEq(t, fmt.Sprintf(`{"images": {"old": [], "new": ["%s"]}}`, img.ID), string(adv.Logs[0].Meta))
+
+	for len(adv.Images) < MaxImages {
+		assert.NoError(t, adv.AttachImage(owner, NewImage(adv.ID, "image/png", 10, 10)))
+	}
+	assert.Equal(t, TooManyImagesErr, adv.CanAttachImage(owner))
+	assert.Equal(t, TooManyImagesErr, adv.AttachImage(owner, NewImage(adv.ID, "image/png", 10, 10)))
+
+	_, err = adv.RemoveImage(stranger, img.ID)
+	assert.Equal(t, NotAdvertOwnerErr, err)
+	_, err = adv.RemoveImage(owner, uuid.New())
+	assert.Equal(t, ImageNotFound, err)
+
+	removed, err := adv.RemoveImage(owner, img.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, img, removed)
+	assert.Len(t, adv.Images, MaxImages-1)
+	_, err = adv.FindImage(img.ID)
+	assert.Equal(t, ImageNotFound, err)
+	assert.NoError(t, adv.CanAttachImage(owner))
+}
@@ -0,0 +1,121 @@
+package advert
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"time"
+)
+
+var (
+	InvalidAdvertTypeErr = errors.New("unknown advert type")
+	InvalidDateRangeErr  = errors.New("created after must be before created before")
+	CursorWithQueryErr   = errors.New("cursor can't be used with search query")
+	CursorWithOffsetErr  = errors.New("cursor can't be used with offset")
+	InvalidRadiusErr     = errors.New("radius must be positive and not greater than max radius")
+)
+
+// MaxRadiusKm limits the radius search, larger areas should be searched by region
+const MaxRadiusKm = 500
+
+// ListFilter describes which adverts should be returned by Repository.GetList, zero values mean no filtering
+type ListFilter struct {
+	Languages LanguageTags
+	// IDs limits the list to the given adverts, e.g. the favourite ones
+	IDs []uuid.UUID
+	// Query is the full-text search query, when not empty adverts are ordered by relevance
+	Query            string
+	Types            []domain.AdvertType
+	UserID           *uuid.UUID
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	IncludeDestroyed bool
+	// IncludeExpired lists also expired and archived adverts, so owners can find the ones to renew
+	IncludeExpired bool
+	// Statuses limits the list to adverts in any of the statuses, only published adverts are listed when empty
+	Statuses []Status
+	// City and Region are matched case-insensitively
+	City   string
+	Region string
+	// Near with RadiusKm limits the list to adverts located within the radius from the point
+	Near     *domain.Coordinates
+	RadiusKm float64
+	Limit    int
+	// Offset is kept for the existing clients, After should be preferred as it doesn't drift when new adverts are added
+	Offset int
+	// After makes the list start right after the advert pointed by the cursor
+	After *Cursor
+}
+
+func (f ListFilter) Validate() error {
+	for _, advertType := range f.Types {
+		if !advertType.IsValid() {
+			return fmt.Errorf("%w: %s", InvalidAdvertTypeErr, advertType)
+		}
+	}
+
+	for _, status := range f.Statuses {
+		if !status.IsValid() {
+			return fmt.Errorf("%w: %s", InvalidStatusErr, status)
+		}
+	}
+
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return InvalidDateRangeErr
+	}
+
+	if f.Near != nil {
+		_, err := domain.NewCoordinates(f.Near.Latitude, f.Near.Longitude)
+		if err != nil {
+			return err
+		}
+	}
+
+	if f.Near != nil && !(f.RadiusKm > 0 && f.RadiusKm <= MaxRadiusKm) {
+		return InvalidRadiusErr
+	}
+
+	if f.Near == nil && f.RadiusKm != 0 {
+		return InvalidRadiusErr
+	}
+
+	if f.After != nil && f.Query != "" {
+		return CursorWithQueryErr
+	}
+
+	if f.After != nil && f.Offset != 0 {
+		return CursorWithOffsetErr
+	}
+	return nil
+}
+
+// OnlyPublished reports whether the filter lists only adverts visible to the public
+func (f ListFilter) OnlyPublished() bool {
+	for _, status := range f.Statuses {
+		if status != StatusPublished {
+			return false
+		}
+	}
+	return true
+}
+
+// CanBeUsedBy tells if the user is allowed to list adverts with the filter,
+// destroyed and expired adverts can be seen only by their owner and admins, not published ones by their owner and moderators
+func (f ListFilter) CanBeUsedBy(usr *user.User) bool {
+	if !f.IncludeDestroyed && !f.IncludeExpired && f.OnlyPublished() {
+		return true
+	}
+	if usr == nil {
+		return false
+	}
+	if f.UserID != nil && *f.UserID == usr.ID {
+		return true
+	}
+	if f.IncludeDestroyed || f.IncludeExpired {
+		return usr.IsAdmin()
+	}
+	return usr.IsModerator()
+}
@@ -0,0 +1,128 @@
+package advert
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"testing"
+	"time"
+)
+
+func TestListFilterValidate(t *testing.T) {
+	now := time.Now()
+	hourAgo := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name     string
+		filter   ListFilter
+		expected error
+	}{
+		{
+			name:   "empty filter",
+			filter: ListFilter{},
+		},
+		{
+			name:   "known types",
+			filter: ListFilter{Types: []domain.AdvertType{domain.AdvertTypeJob, domain.AdvertTypePlaceToStay}},
+		},
+		{
+			name:     "unknown type",
+			filter:   ListFilter{Types: []domain.AdvertType{domain.AdvertTypeJob, "spaceship"}},
+			expected: InvalidAdvertTypeErr,
+		},
+		{
+			name:   "known statuses",
+			filter: ListFilter{Statuses: []Status{StatusPending, StatusRejected}},
+		},
+		{
+			name:     "unknown status",
+			filter:   ListFilter{Statuses: []Status{StatusPending, "hidden"}},
+			expected: InvalidStatusErr,
+		},
+		{
+			name:   "valid date range",
+			filter: ListFilter{CreatedAfter: &hourAgo, CreatedBefore: &now},
+		},
+		{
+			name:     "inverted date range",
+			filter:   ListFilter{CreatedAfter: &now, CreatedBefore: &hourAgo},
+			expected: InvalidDateRangeErr,
+		},
+		{
+			name:   "radius search",
+			filter: ListFilter{Near: &domain.Coordinates{Latitude: 50.45, Longitude: 30.52}, RadiusKm: 20},
+		},
+		{
+			name:     "radius without point",
+			filter:   ListFilter{RadiusKm: 20},
+			expected: InvalidRadiusErr,
+		},
+		{
+			name:     "point without radius",
+			filter:   ListFilter{Near: &domain.Coordinates{Latitude: 50.45, Longitude: 30.52}},
+			expected: InvalidRadiusErr,
+		},
+		{
+			name:     "too big radius",
+			filter:   ListFilter{Near: &domain.Coordinates{Latitude: 50.45, Longitude: 30.52}, RadiusKm: MaxRadiusKm + 1},
+			expected: InvalidRadiusErr,
+		},
+		{
+			name:     "invalid point",
+			filter:   ListFilter{Near: &domain.Coordinates{Latitude: 100}, RadiusKm: 20},
+			expected: domain.InvalidCoordinatesErr,
+		},
+		{
+			name:   "cursor",
+			filter: ListFilter{After: &Cursor{CreatedAt: now, ID: uuid.New()}},
+		},
+		{
+			name:     "cursor with search query",
+			filter:   ListFilter{Query: "room", After: &Cursor{CreatedAt: now, ID: uuid.New()}},
+			expected: CursorWithQueryErr,
+		},
+		{
+			name:     "cursor with offset",
+			filter:   ListFilter{Offset: 10, After: &Cursor{CreatedAt: now, ID: uuid.New()}},
+			expected: CursorWithOffsetErr,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			err := tC.filter.Validate()
+			assert.True(t, errors.Is(err, tC.expected), "expected %v, got %v", tC.expected, err)
+		})
+	}
+}
+
+func TestListFilterCanBeUsedBy(t *testing.T) {
+	owner := &user.User{ID: uuid.New(), Role: user.RoleUser}
+	admin := &user.User{ID: uuid.New(), Role: user.RoleAdmin}
+	stranger := &user.User{ID: uuid.New(), Role: user.RoleUser}
+
+	assert.True(t, ListFilter{}.CanBeUsedBy(nil))
+
+	filter := ListFilter{UserID: &owner.ID, IncludeDestroyed: true}
+	assert.True(t, filter.CanBeUsedBy(owner))
+	assert.True(t, filter.CanBeUsedBy(admin))
+	assert.False(t, filter.CanBeUsedBy(stranger))
+	assert.False(t, filter.CanBeUsedBy(nil))
+	assert.False(t, ListFilter{IncludeDestroyed: true}.CanBeUsedBy(owner))
+
+	moderator := &user.User{ID: uuid.New(), Role: user.RoleModerator}
+	assert.False(t, filter.CanBeUsedBy(moderator))
+
+	assert.True(t, ListFilter{Statuses: []Status{StatusPublished}}.CanBeUsedBy(nil))
+
+	pending := ListFilter{UserID: &owner.ID, Statuses: []Status{StatusPending}}
+	assert.True(t, pending.CanBeUsedBy(owner))
+	assert.True(t, pending.CanBeUsedBy(moderator))
+	assert.True(t, pending.CanBeUsedBy(admin))
+	assert.False(t, pending.CanBeUsedBy(stranger))
+	assert.False(t, pending.CanBeUsedBy(nil))
+	assert.True(t, ListFilter{Statuses: []Status{StatusPending}}.CanBeUsedBy(moderator))
+	assert.False(t, ListFilter{Statuses: []Status{StatusPending}}.CanBeUsedBy(owner))
+}
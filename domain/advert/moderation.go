@@ -0,0 +1,175 @@
+package advert
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"strings"
+)
+
+// Status is the moderation state of the advert, only published adverts are visible to the public
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPublished Status = "published"
+	StatusRejected  Status = "rejected"
+	StatusSuspended Status = "suspended"
+)
+
+var statuses = []Status{
+	StatusPending, StatusPublished, StatusRejected, StatusSuspended,
+}
+
+func (s Status) IsValid() bool {
+	for _, status := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses returns all the statuses an advert can be in
+func Statuses() []Status {
+	return append([]Status{}, statuses...)
+}
+
+// MaxModerationReasonLength matches adverts.moderation_reason column
+const MaxModerationReasonLength = 500
+
+var (
+	NotModeratorErr            = errors.New("user is not a moderator")
+	InvalidStatusErr           = errors.New("unknown advert status")
+	InvalidStatusTransitionErr = errors.New("advert can't be moved to this status")
+	InvalidModerationReasonErr = errors.New("moderation reason must be given and not longer than max length")
+)
+
+// ModerationPolicy decides whether new adverts have to wait for the review
+type ModerationPolicy struct {
+	// AutoPublishTrusted publishes adverts of trusted users right away
+	AutoPublishTrusted bool
+}
+
+// RequiresReview tells if adverts of the user have to be approved by a moderator before they are published
+func (p ModerationPolicy) RequiresReview(usr *user.User) bool {
+	if usr == nil {
+		return true
+	}
+	if usr.IsModerator() {
+		return false
+	}
+	return !(p.AutoPublishTrusted && usr.Trusted)
+}
+
+// CanBeViewedBy tells if the user can see the advert, not published ones are visible only to their owner and moderators
+func (a Advert) CanBeViewedBy(usr *user.User) bool {
+	if a.Status == StatusPublished {
+		return true
+	}
+	if usr == nil {
+		return false
+	}
+	return a.IsOwnedBy(usr) || usr.IsModerator()
+}
+
+// Publish skips the review of the advert just created by the owner, who doesn't require it according to the policy
+func (a *Advert) Publish(policy ModerationPolicy) error {
+	if a.Status != StatusPending || policy.RequiresReview(a.User) {
+		return InvalidStatusTransitionErr
+	}
+	return a.changeStatus(a.User, StatusPublished, "")
+}
+
+// ReviewChanges sends the published advert changed by its owner back to the review when the owner's adverts require it,
+// otherwise the approved advert could be swapped for content nobody has reviewed
+func (a *Advert) ReviewChanges(policy ModerationPolicy) error {
+	if a.Status != StatusPublished || !policy.RequiresReview(a.User) {
+		return nil
+	}
+	return a.changeStatus(a.User, StatusPending, "")
+}
+
+// Approve publishes the advert, it's also used to lift the suspension or the rejection
+func (a *Advert) Approve(moderator *user.User) error {
+	err := checkModerator(moderator)
+	if err != nil {
+		return err
+	}
+
+	if a.Status == StatusPublished {
+		return InvalidStatusTransitionErr
+	}
+	return a.changeStatus(moderator, StatusPublished, "")
+}
+
+// Reject refuses the pending advert, the owner can fix it according to the reason and it will wait for the review again
+func (a *Advert) Reject(moderator *user.User, reason string) error {
+	err := checkModerator(moderator)
+	if err != nil {
+		return err
+	}
+
+	if a.Status != StatusPending {
+		return InvalidStatusTransitionErr
+	}
+	return a.changeStatus(moderator, StatusRejected, reason)
+}
+
+// Suspend hides the already published advert until a moderator approves it again
+func (a *Advert) Suspend(moderator *user.User, reason string) error {
+	err := checkModerator(moderator)
+	if err != nil {
+		return err
+	}
+
+	if a.Status != StatusPublished {
+		return InvalidStatusTransitionErr
+	}
+	return a.changeStatus(moderator, StatusSuspended, reason)
+}
+
+// HideReported suspends the published advert on behalf of the system once it has been reported too many times,
+// it stays hidden until a moderator reviews the reports and approves it again
+func (a *Advert) HideReported(reports int) error {
+	if a.Status != StatusPublished {
+		return InvalidStatusTransitionErr
+	}
+	return a.changeStatus(nil, StatusSuspended, fmt.Sprintf("hidden automatically after %d reports", reports))
+}
+
+func checkModerator(usr *user.User) error {
+	if usr == nil {
+		return NoUserProvidedErr
+	}
+	if !usr.IsModerator() {
+		return NotModeratorErr
+	}
+	return nil
+}
+
+// changeStatus moves the advert to the status, reason is required for rejected and suspended adverts.
+// Nil user means the change is made by the system.
+func (a *Advert) changeStatus(usr *user.User, status Status, reason string) error {
+	reason = strings.TrimSpace(reason)
+	needsReason := status == StatusRejected || status == StatusSuspended
+	if needsReason && (reason == "" || len([]rune(reason)) > MaxModerationReasonLength) {
+		return InvalidModerationReasonErr
+	}
+
+	meta := map[string]interface{}{
+		"status": FieldChange{Old: a.Status, New: status},
+	}
+	if reason != "" {
+		meta["reason"] = reason
+	}
+
+	err := a.recordLog(usr, AdvertModeratedEvent, meta)
+	if err != nil {
+		return err
+	}
+
+	a.Status = status
+	a.ModerationReason = reason
+	return nil
+}
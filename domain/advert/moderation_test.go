@@ -0,0 +1,143 @@
+package advert
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"strings"
+	"testing"
+)
+
+func TestAdvertModeration(t *testing.T) {
+	contactDetails := domain.ContactDetails{Mail: newStringPtr("mail")}
+	owner, err := user.NewUser("Adam", "Małysz", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+	moderator, err := user.NewUser("Kamil", "Stoch", *test_helpers.RandomMail(), "abc", contactDetails)
+	assert.NoError(t, err)
+	moderator.Role = user.RoleModerator
+
+	adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, adv.Status)
+	assert.False(t, adv.CanBeViewedBy(nil))
+	assert.True(t, adv.CanBeViewedBy(owner))
+	assert.True(t, adv.CanBeViewedBy(moderator))
+	adv.Logs = nil
+
+	assert.Equal(t, NoUserProvidedErr, adv.Approve(nil))
+	assert.Equal(t, NotModeratorErr, adv.Approve(owner))
+	assert.Equal(t, NotModeratorErr, adv.Reject(owner, "spam"))
+	assert.Equal(t, InvalidStatusTransitionErr, adv.Suspend(moderator, "spam"))
+	assert.Equal(t, InvalidModerationReasonErr, adv.Reject(moderator, "  "))
+	assert.Equal(t, InvalidModerationReasonErr, adv.Reject(moderator, strings.Repeat("x", MaxModerationReasonLength+1)))
+	assert.Equal(t, StatusPending, adv.Status)
+	assert.Empty(t, adv.Logs)
+
+	// rejected advert fixed by the owner waits for the review again
+	assert.NoError(t, adv.Reject(moderator, " missing phone number "))
+	assert.Equal(t, StatusRejected, adv.Status)
+	assert.Equal(t, "missing phone number", adv.ModerationReason)
+	assert.NoError(t, adv.Update(owner, MultilingualString{English: "y"}, nil, ""))
+	assert.Equal(t, StatusPending, adv.Status)
+	assert.Empty(t, adv.ModerationReason)
+
+	assert.NoError(t, adv.Approve(moderator))
+	assert.Equal(t, StatusPublished, adv.Status)
+	assert.True(t, adv.CanBeViewedBy(nil))
+	assert.Equal(t, InvalidStatusTransitionErr, adv.Approve(moderator))
+	assert.Equal(t, InvalidStatusTransitionErr, adv.Reject(moderator, "spam"))
+
+	assert.NoError(t, adv.Suspend(moderator, "reported as scam"))
+	assert.Equal(t, StatusSuspended, adv.Status)
+	assert.False(t, adv.CanBeViewedBy(nil))
+	assert.NoError(t, adv.Approve(moderator))
+	assert.Empty(t, adv.ModerationReason)
+
+	var triggers []AdvertLogTrigger
+	for _, advLog := range adv.Logs {
+		triggers = append(triggers, advLog.Trigger)
+	}
+	assert.Equal(t, []AdvertLogTrigger{
+		AdvertModeratedEvent, AdvertUpdatedEvent, AdvertModeratedEvent, AdvertModeratedEvent, AdvertModeratedEvent, AdvertModeratedEvent,
+	}, triggers)
+	assert.Equal(t, moderator.ID, adv.Logs[0].UserID)
+	assert.JSONEq(t, `{"status": {"old": "pending", "new": "rejected"}, "reason": "missing phone number"}`, string(adv.Logs[0].Meta))
+}
+
+func TestAdvertReviewChanges(t *testing.T) {
+	owner := &user.User{ID: uuid.New(), Role: user.RoleUser, Trusted: true}
+	newPublished := func() *Advert {
+		adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport, WithContactDetails(domain.ContactDetails{Mail: newStringPtr("mail")}))
+		assert.NoError(t, err)
+		adv.Status = StatusPublished
+		adv.Logs = nil
+		return adv
+	}
+
+	// the approved advert edited by the owner can't go live without another review
+	adv := newPublished()
+	assert.NoError(t, adv.Update(owner, MultilingualString{English: "y"}, nil, ""))
+	assert.NoError(t, adv.ReviewChanges(ModerationPolicy{}))
+	assert.Equal(t, StatusPending, adv.Status)
+	assert.Equal(t, AdvertModeratedEvent, adv.Logs[len(adv.Logs)-1].Trigger)
+	assert.Equal(t, owner.ID, adv.Logs[len(adv.Logs)-1].UserID)
+
+	adv = newPublished()
+	assert.NoError(t, adv.AttachImage(owner, NewImage(adv.ID, "image/png", 10, 10)))
+	assert.NoError(t, adv.ReviewChanges(ModerationPolicy{}))
+	assert.Equal(t, StatusPending, adv.Status)
+
+	// owners who don't require the review keep their adverts published
+	adv = newPublished()
+	assert.NoError(t, adv.ReviewChanges(ModerationPolicy{AutoPublishTrusted: true}))
+	assert.Equal(t, StatusPublished, adv.Status)
+
+	adv = newPublished()
+	adv.Status = StatusSuspended
+	assert.NoError(t, adv.ReviewChanges(ModerationPolicy{}))
+	assert.Equal(t, StatusSuspended, adv.Status)
+}
+
+func TestAdvertHideReported(t *testing.T) {
+	owner := &user.User{Role: user.RoleUser}
+	adv, err := NewAdvert(owner, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport, WithContactDetails(domain.ContactDetails{Mail: newStringPtr("mail")}))
+	assert.NoError(t, err)
+	adv.Logs = nil
+
+	assert.Equal(t, InvalidStatusTransitionErr, adv.HideReported(5))
+
+	adv.Status = StatusPublished
+	assert.NoError(t, adv.HideReported(5))
+	assert.Equal(t, StatusSuspended, adv.Status)
+	assert.Equal(t, "hidden automatically after 5 reports", adv.ModerationReason)
+	assert.Len(t, adv.Logs, 1)
+	assert.Equal(t, uuid.Nil, adv.Logs[0].UserID)
+}
+
+func TestModerationPolicy(t *testing.T) {
+	usr := &user.User{Role: user.RoleUser}
+	trusted := &user.User{Role: user.RoleUser, Trusted: true}
+	moderator := &user.User{Role: user.RoleModerator}
+
+	policy := ModerationPolicy{}
+	assert.True(t, policy.RequiresReview(nil))
+	assert.True(t, policy.RequiresReview(usr))
+	assert.True(t, policy.RequiresReview(trusted))
+	assert.False(t, policy.RequiresReview(moderator))
+
+	policy = ModerationPolicy{AutoPublishTrusted: true}
+	assert.True(t, policy.RequiresReview(usr))
+	assert.False(t, policy.RequiresReview(trusted))
+
+	adv, err := NewAdvert(usr, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport, WithContactDetails(domain.ContactDetails{Mail: newStringPtr("mail")}))
+	assert.NoError(t, err)
+	assert.Equal(t, InvalidStatusTransitionErr, adv.Publish(policy))
+	assert.Equal(t, StatusPending, adv.Status)
+
+	adv.User = trusted
+	assert.NoError(t, adv.Publish(policy))
+	assert.Equal(t, StatusPublished, adv.Status)
+}
@@ -4,17 +4,40 @@ import (
 	"context"
 	"errors"
 	"github.com/google/uuid"
-	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"time"
 )
 
 var (
 	AdvertAlreadyExists = errors.New("advert already exists in repository")
 	AdvertNotFound      = errors.New("advert not found in repository")
+	// AdvertChangedErr is returned by Update when someone else has changed the advert since it was loaded
+	AdvertChangedErr = errors.New("advert has been changed since it was loaded")
 )
 
 type Repository interface {
 	Get(ctx context.Context, id uuid.UUID) (Advert, error)
-	GetList(ctx context.Context, langs LanguageTags, limit int, offset int) ([]*Advert, error)
+	// GetList returns adverts matching the filter from the newest one, filter should be validated before
+	GetList(ctx context.Context, filter ListFilter) ([]*Advert, error)
 	Add(ctx context.Context, advert *Advert) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Update stores the changed advert, AdvertChangedErr is returned if its Version doesn't match the stored one anymore
+	Update(ctx context.Context, advert *Advert) error
+	Delete(ctx context.Context, advert *Advert) error
+	// AddImage stores the image attached by Advert.AttachImage, TooManyImagesErr is returned if the advert is full already
+	AddImage(ctx context.Context, advert *Advert, img Image) error
+	// RemoveImage removes the image detached by Advert.RemoveImage, ImageNotFound is returned if it's gone already
+	RemoveImage(ctx context.Context, advert *Advert, img Image) error
+	IncrementViews(ctx context.Context, id uuid.UUID) error
+	IncrementContactReveals(ctx context.Context, id uuid.UUID) error
+	// GetUntranslated returns adverts waiting for machine translation from the oldest one
+	GetUntranslated(ctx context.Context, limit int) ([]*Advert, error)
+	// SaveTranslations stores machine translations, TranslationOutdatedErr is returned if the advert has been changed since it was loaded
+	SaveTranslations(ctx context.Context, advert *Advert) error
+	// FailTranslation records the failed translation, adverts are moved behind the other ones and after maxAttempts failures they are marked as translated
+	FailTranslation(ctx context.Context, id uuid.UUID, maxAttempts int) error
+	// ArchiveExpired archives adverts which expired before now and returns their ids
+	ArchiveExpired(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+}
+
+type LogRepository interface {
+	GetHistory(ctx context.Context, advertID uuid.UUID) (AdvertHistory, error)
 }
@@ -0,0 +1,81 @@
+package advert
+
+import (
+	"errors"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTranslationAttempts limits failed translations of the advert, then it's left untranslated, so it doesn't hold up the queue
+const MaxTranslationAttempts = 5
+
+var (
+	// TranslationOutdatedErr is returned by the repository when the advert has been changed while it was translated
+	TranslationOutdatedErr = errors.New("advert has been changed since it was loaded for translation")
+	InvalidTranslationErr  = errors.New("translation must be given in supported language not available yet")
+)
+
+// MissingLanguages returns the supported languages in which the title or the description isn't available
+func (a Advert) MissingLanguages() LanguageTags {
+	var missing LanguageTags
+	for _, lang := range SupportedLanguages() {
+		if a.Details.Title[lang] == "" || a.Details.Description[lang] == "" {
+			missing = append(missing, lang)
+		}
+	}
+	return missing
+}
+
+// SourceLanguage returns the language written by the author which the advert should be translated from
+func (a Advert) SourceLanguage() (LanguageTag, bool) {
+	for _, lang := range SupportedLanguages() {
+		if a.Details.Title[lang] != "" && a.Details.Description[lang] != "" && !a.Details.MachineTranslated.Contains(lang) {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// AddMachineTranslation fills the missing language with the title and description translated by machine.
+// Translations are often longer than the source, so they are shortened to MaxTitleLength and MaxDescriptionLength.
+func (a *Advert) AddMachineTranslation(lang LanguageTag, title string, description string) error {
+	title, description = truncate(strings.TrimSpace(title), MaxTitleLength), truncate(strings.TrimSpace(description), MaxDescriptionLength)
+	if title == "" || description == "" || !a.MissingLanguages().Contains(lang) {
+		return InvalidTranslationErr
+	}
+
+	// copy the maps, so the advert loaded by someone else isn't changed behind their back
+	a.Details.Title = a.Details.Title.Filter(SupportedLanguages())
+	a.Details.Description = a.Details.Description.Filter(SupportedLanguages())
+	a.Details.Title[lang] = title
+	a.Details.Description[lang] = description
+	a.Details.MachineTranslated = append(append(LanguageTags{}, a.Details.MachineTranslated...), lang)
+	return nil
+}
+
+// MarkTranslated marks that the advert doesn't wait for machine translation anymore, even if some languages couldn't be translated
+func (a *Advert) MarkTranslated() {
+	now := time.Now()
+	a.TranslatedAt = &now
+}
+
+// truncate shortens the text to maxLength runes marking the cut with an ellipsis
+func truncate(text string, maxLength int) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+	return strings.TrimSpace(string([]rune(text)[:maxLength-1])) + "…"
+}
+
+// withoutMachineTranslations returns the copy of the text written by the author only
+func withoutMachineTranslations(s MultilingualString, machineTranslated LanguageTags) MultilingualString {
+	authored := MultilingualString{}
+	for lang, text := range s {
+		if !machineTranslated.Contains(lang) {
+			authored[lang] = text
+		}
+	}
+	return authored
+}
@@ -0,0 +1,67 @@
+package advert
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAdvertMachineTranslation(t *testing.T) {
+	owner := &user.User{ID: uuid.New(), ContactDetails: domain.ContactDetails{Mail: newStringPtr("owner@example.com")}}
+	adv, err := NewAdvert(owner, MultilingualString{Polish: "Pokój", English: "Room"}, MultilingualString{Polish: "Wolny pokój"}, domain.AdvertTypePlaceToStay)
+	require.NoError(t, err)
+	assert.Nil(t, adv.TranslatedAt)
+
+	source, ok := adv.SourceLanguage()
+	assert.True(t, ok)
+	assert.Equal(t, Polish, source)
+	assert.Equal(t, LanguageTags{English, Ukrainian}, adv.MissingLanguages())
+
+	loaded := *adv
+	assert.ErrorIs(t, adv.AddMachineTranslation(Polish, "Pokój", "Pokój"), InvalidTranslationErr)
+	assert.ErrorIs(t, adv.AddMachineTranslation("de", "Zimmer", "Freies Zimmer"), InvalidTranslationErr)
+	assert.ErrorIs(t, adv.AddMachineTranslation(Ukrainian, " ", "Вільна кімната"), InvalidTranslationErr)
+
+	require.NoError(t, adv.AddMachineTranslation(Ukrainian, "Кімната", "Вільна кімната"))
+	require.NoError(t, adv.AddMachineTranslation(English, "Room", "Free room"))
+	adv.MarkTranslated()
+	assert.Empty(t, adv.MissingLanguages())
+	assert.Equal(t, LanguageTags{Ukrainian, English}, adv.Details.MachineTranslated)
+	assert.NotNil(t, adv.TranslatedAt)
+	assert.Len(t, loaded.Details.Title, 2, "translation mustn't change the copy of the advert")
+
+	t.Run("too long translation", func(t *testing.T) {
+		adv, err := NewAdvert(owner, MultilingualString{Polish: "Pokój"}, MultilingualString{Polish: "Wolny pokój"}, domain.AdvertTypePlaceToStay)
+		require.NoError(t, err)
+
+		require.NoError(t, adv.AddMachineTranslation(English, strings.Repeat("Room ", MaxTitleLength), strings.Repeat("d", MaxDescriptionLength+1)))
+		assert.Equal(t, MaxTitleLength, utf8.RuneCountInString(adv.Details.Title[English]))
+		assert.True(t, strings.HasSuffix(adv.Details.Title[English], "m…"))
+		assert.Equal(t, MaxDescriptionLength, utf8.RuneCountInString(adv.Details.Description[English]))
+
+		require.NoError(t, adv.AddMachineTranslation(Ukrainian, strings.Repeat("к", MaxTitleLength), "Вільна кімната"))
+		assert.Equal(t, strings.Repeat("к", MaxTitleLength), adv.Details.Title[Ukrainian])
+	})
+
+	t.Run("update drops outdated translations", func(t *testing.T) {
+		translated := *adv
+		require.NoError(t, translated.Update(owner, nil, MultilingualString{Polish: "Pokój z balkonem"}, ""))
+		assert.Equal(t, MultilingualString{Polish: "Pokój"}, translated.Details.Title)
+		assert.Equal(t, MultilingualString{Polish: "Pokój z balkonem"}, translated.Details.Description)
+		assert.Nil(t, translated.Details.MachineTranslated)
+		assert.Nil(t, translated.TranslatedAt)
+		assert.Len(t, adv.Details.Title, 3)
+
+		// changes not touching the text keep the translations
+		translated = *adv
+		require.NoError(t, translated.Update(owner, nil, nil, domain.AdvertTypeTransport))
+		assert.Equal(t, adv.Details.MachineTranslated, translated.Details.MachineTranslated)
+		assert.NotNil(t, translated.TranslatedAt)
+	})
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var InvalidAttributesErr = errors.New("invalid advert attributes")
+
+const (
+	maxAttributeTextLength = 60
+	maxSpecializations     = 10
+)
+
+// AdvertAttributes holds the details specific for the advert type, at most the one matching the type is set
+type AdvertAttributes struct {
+	PlaceToStay *PlaceToStayAttributes `json:"place_to_stay,omitempty"`
+	Transport   *TransportAttributes   `json:"transport,omitempty"`
+	Job         *JobAttributes         `json:"job,omitempty"`
+	Lawyer      *LawyerAttributes      `json:"lawyer,omitempty"`
+}
+
+type PlaceToStayAttributes struct {
+	// Capacity is the number of people who can be hosted
+	Capacity        int        `json:"capacity"`
+	Rooms           int        `json:"rooms,omitempty"`
+	PetsAllowed     bool       `json:"pets_allowed"`
+	ChildrenAllowed bool       `json:"children_allowed"`
+	AvailableFrom   *time.Time `json:"available_from,omitempty"`
+	AvailableTo     *time.Time `json:"available_to,omitempty"`
+}
+
+type TransportAttributes struct {
+	Origin        string     `json:"origin"`
+	Destination   string     `json:"destination"`
+	DepartureTime *time.Time `json:"departure_time,omitempty"`
+	FreeSeats     int        `json:"free_seats"`
+}
+
+type ContractType string
+
+const (
+	ContractTypeEmployment   ContractType = "employment"
+	ContractTypeContract     ContractType = "contract"
+	ContractTypeSelfEmployed ContractType = "self_employed"
+	ContractTypeTemporary    ContractType = "temporary"
+)
+
+type JobAttributes struct {
+	SalaryMin    *int         `json:"salary_min,omitempty"`
+	SalaryMax    *int         `json:"salary_max,omitempty"`
+	Currency     string       `json:"currency,omitempty"` // ISO 4217
+	ContractType ContractType `json:"contract_type,omitempty"`
+}
+
+type LawyerAttributes struct {
+	Specializations []string `json:"specializations,omitempty"`
+	ProBono         bool     `json:"pro_bono"`
+}
+
+func invalidAttributes(reason string) error {
+	return fmt.Errorf("%w: %s", InvalidAttributesErr, reason)
+}
+
+// Validate checks the attributes against the advert type, empty attributes are always valid as they are optional
+func (a AdvertAttributes) Validate(advertType AdvertType) error {
+	var set []AdvertType
+	if a.PlaceToStay != nil {
+		set = append(set, AdvertTypePlaceToStay)
+	}
+	if a.Transport != nil {
+		set = append(set, AdvertTypeTransport)
+	}
+	if a.Job != nil {
+		set = append(set, AdvertTypeJob)
+	}
+	if a.Lawyer != nil {
+		set = append(set, AdvertTypeLawyer)
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+	if len(set) > 1 || set[0] != advertType {
+		return invalidAttributes(fmt.Sprintf("attributes don't match %s advert", advertType))
+	}
+
+	switch advertType {
+	case AdvertTypePlaceToStay:
+		return a.PlaceToStay.validate()
+	case AdvertTypeTransport:
+		return a.Transport.validate()
+	case AdvertTypeJob:
+		return a.Job.validate()
+	case AdvertTypeLawyer:
+		return a.Lawyer.validate()
+	}
+	return nil
+}
+
+// Empty reports whether none of the attributes is set
+func (a AdvertAttributes) Empty() bool {
+	return a.PlaceToStay == nil && a.Transport == nil && a.Job == nil && a.Lawyer == nil
+}
+
+func (a PlaceToStayAttributes) validate() error {
+	if a.Capacity < 1 {
+		return invalidAttributes("capacity must be positive")
+	}
+	if a.Rooms < 0 {
+		return invalidAttributes("rooms can't be negative")
+	}
+	if a.AvailableFrom != nil && a.AvailableTo != nil && a.AvailableTo.Before(*a.AvailableFrom) {
+		return invalidAttributes("available to must be after available from")
+	}
+	return nil
+}
+
+func (a TransportAttributes) validate() error {
+	if strings.TrimSpace(a.Origin) == "" || strings.TrimSpace(a.Destination) == "" {
+		return invalidAttributes("origin and destination are required")
+	}
+	if utf8.RuneCountInString(a.Origin) > maxAttributeTextLength || utf8.RuneCountInString(a.Destination) > maxAttributeTextLength {
+		return invalidAttributes("origin or destination too long")
+	}
+	if a.FreeSeats < 1 {
+		return invalidAttributes("free seats must be positive")
+	}
+	return nil
+}
+
+func (a JobAttributes) validate() error {
+	if (a.SalaryMin != nil && *a.SalaryMin < 0) || (a.SalaryMax != nil && *a.SalaryMax < 0) {
+		return invalidAttributes("salary can't be negative")
+	}
+	if a.SalaryMin != nil && a.SalaryMax != nil && *a.SalaryMin > *a.SalaryMax {
+		return invalidAttributes("salary min must not be greater than salary max")
+	}
+	if (a.SalaryMin != nil || a.SalaryMax != nil) && len(a.Currency) != 3 {
+		return invalidAttributes("salary requires 3 letter currency code")
+	}
+
+	switch a.ContractType {
+	case "", ContractTypeEmployment, ContractTypeContract, ContractTypeSelfEmployed, ContractTypeTemporary:
+		return nil
+	}
+	return invalidAttributes("unknown contract type")
+}
+
+func (a LawyerAttributes) validate() error {
+	if len(a.Specializations) > maxSpecializations {
+		return invalidAttributes("too many specializations")
+	}
+	for _, specialization := range a.Specializations {
+		if strings.TrimSpace(specialization) == "" || utf8.RuneCountInString(specialization) > maxAttributeTextLength {
+			return invalidAttributes("invalid specialization")
+		}
+	}
+	return nil
+}
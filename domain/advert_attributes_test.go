@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newIntPtr(i int) *int {
+	return &i
+}
+
+func TestAdvertAttributesValidate(t *testing.T) {
+	now := time.Now()
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+
+	testCases := []struct {
+		name       string
+		advertType AdvertType
+		attributes AdvertAttributes
+		valid      bool
+	}{
+		{name: "no attributes", advertType: AdvertTypeJob, valid: true},
+		{
+			name:       "place to stay",
+			advertType: AdvertTypePlaceToStay,
+			attributes: AdvertAttributes{PlaceToStay: &PlaceToStayAttributes{Capacity: 4, Rooms: 2, PetsAllowed: true, AvailableFrom: &weekAgo, AvailableTo: &now}},
+			valid:      true,
+		},
+		{
+			name:       "place to stay without capacity",
+			advertType: AdvertTypePlaceToStay,
+			attributes: AdvertAttributes{PlaceToStay: &PlaceToStayAttributes{Rooms: 2}},
+		},
+		{
+			name:       "place to stay available to before available from",
+			advertType: AdvertTypePlaceToStay,
+			attributes: AdvertAttributes{PlaceToStay: &PlaceToStayAttributes{Capacity: 1, AvailableFrom: &now, AvailableTo: &weekAgo}},
+		},
+		{
+			name:       "attributes of other type",
+			advertType: AdvertTypeJob,
+			attributes: AdvertAttributes{PlaceToStay: &PlaceToStayAttributes{Capacity: 4}},
+		},
+		{
+			name:       "attributes of many types",
+			advertType: AdvertTypeJob,
+			attributes: AdvertAttributes{Job: &JobAttributes{}, Lawyer: &LawyerAttributes{}},
+		},
+		{
+			name:       "transport",
+			advertType: AdvertTypeTransport,
+			attributes: AdvertAttributes{Transport: &TransportAttributes{Origin: "Przemyśl", Destination: "Kraków", DepartureTime: &now, FreeSeats: 3}},
+			valid:      true,
+		},
+		{
+			name:       "transport without destination",
+			advertType: AdvertTypeTransport,
+			attributes: AdvertAttributes{Transport: &TransportAttributes{Origin: "Przemyśl", FreeSeats: 3}},
+		},
+		{
+			name:       "job",
+			advertType: AdvertTypeJob,
+			attributes: AdvertAttributes{Job: &JobAttributes{SalaryMin: newIntPtr(4000), SalaryMax: newIntPtr(6000), Currency: "PLN", ContractType: ContractTypeEmployment}},
+			valid:      true,
+		},
+		{
+			name:       "job with inverted salary range",
+			advertType: AdvertTypeJob,
+			attributes: AdvertAttributes{Job: &JobAttributes{SalaryMin: newIntPtr(6000), SalaryMax: newIntPtr(4000), Currency: "PLN"}},
+		},
+		{
+			name:       "job salary without currency",
+			advertType: AdvertTypeJob,
+			attributes: AdvertAttributes{Job: &JobAttributes{SalaryMin: newIntPtr(4000)}},
+		},
+		{
+			name:       "job with unknown contract",
+			advertType: AdvertTypeJob,
+			attributes: AdvertAttributes{Job: &JobAttributes{ContractType: "slavery"}},
+		},
+		{
+			name:       "lawyer",
+			advertType: AdvertTypeLawyer,
+			attributes: AdvertAttributes{Lawyer: &LawyerAttributes{Specializations: []string{"immigration", "labour"}, ProBono: true}},
+			valid:      true,
+		},
+		{
+			name:       "lawyer with empty specialization",
+			advertType: AdvertTypeLawyer,
+			attributes: AdvertAttributes{Lawyer: &LawyerAttributes{Specializations: []string{" "}}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			err := tC.attributes.Validate(tC.advertType)
+			if tC.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, InvalidAttributesErr)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
 	"regexp"
 )
 
@@ -16,6 +17,9 @@ var (
 	phoneRegex        = regexp.MustCompile("^\\+[0-9]{2} [0-9]{3} [0-9]{3} [0-9]{3}$")
 )
 
+// phoneFormat is the example of the phone number matching phoneRegex
+const phoneFormat = "+48 123 456 789"
+
 func NewContactDetails(mail, phoneNumber string) (ContactDetails, error) {
 	details := ContactDetails{
 		Mail:        &mail,
@@ -25,12 +29,28 @@ func NewContactDetails(mail, phoneNumber string) (ContactDetails, error) {
 	isPhoneValid := phoneRegex.MatchString(phoneNumber)
 
 	if !isMailValid && !isPhoneValid {
-		return ContactDetails{}, InvalidDataErr
+		return ContactDetails{}, contactErrors(mail, phoneNumber)
 	}
 
 	return details, nil
 }
 
+// contactErrors explains why neither the mail nor the phone number can be used, a single valid one would be enough
+func contactErrors(mail, phoneNumber string) validation.Errors {
+	var errs validation.Errors
+	if mail == "" {
+		errs.Add(validation.NewFieldError("mail", validation.RequiredCode, InvalidDataErr).With("alternative", "phone"))
+	} else {
+		errs.Add(validation.NewFieldError("mail", validation.InvalidFormatCode, InvalidDataErr))
+	}
+	if phoneNumber == "" {
+		errs.Add(validation.NewFieldError("phone", validation.RequiredCode, InvalidDataErr).With("alternative", "mail"))
+	} else {
+		errs.Add(validation.NewFieldError("phone", validation.InvalidFormatCode, InvalidDataErr).With("format", phoneFormat))
+	}
+	return errs
+}
+
 func (cd ContactDetails) IsEmpty() bool {
 	return cd.Mail == nil && cd.PhoneNumber == nil
 }
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+	"testing"
+)
+
+func TestNewContactDetails(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mail     string
+		phone    string
+		expected error
+	}{
+		{name: "mail", mail: "adam@wp.pl"},
+		{name: "phone", phone: "+48 111 222 333"},
+		{name: "invalid phone with valid mail", mail: "adam@wp.pl", phone: "111"},
+		{name: "missing both", expected: validation.Errors{
+			validation.NewFieldError("mail", validation.RequiredCode, InvalidDataErr).With("alternative", "phone"),
+			validation.NewFieldError("phone", validation.RequiredCode, InvalidDataErr).With("alternative", "mail"),
+		}},
+		{name: "invalid both", mail: "adam", phone: "111", expected: validation.Errors{
+			validation.NewFieldError("mail", validation.InvalidFormatCode, InvalidDataErr),
+			validation.NewFieldError("phone", validation.InvalidFormatCode, InvalidDataErr).With("format", phoneFormat),
+		}},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			_, err := NewContactDetails(tC.mail, tC.phone)
+			if tC.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tC.expected, err)
+			assert.ErrorIs(t, err, InvalidDataErr)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"strings"
+	"time"
+)
+
+// MaxMessageLength matches conversation_messages.body column
+const MaxMessageLength = 2000
+
+var (
+	NoUserProvidedErr    = errors.New("no user provided")
+	NoAdvertOwnerErr     = errors.New("advert has no owner to talk to")
+	OwnAdvertErr         = errors.New("user can't start conversation about own advert")
+	NotParticipantErr    = errors.New("user doesn't take part in the conversation")
+	NotOwnerErr          = errors.New("user is not the owner of the advert")
+	BlockedErr           = errors.New("sender is blocked by the advert owner")
+	InvalidMessageErr    = errors.New("message is required and can't be longer than max length")
+	ConversationNotFound = errors.New("conversation not found in repository")
+)
+
+// Conversation is the thread between the advert owner and the user who asked about the advert,
+// there is at most one conversation per advert and seeker
+type Conversation struct {
+	ID       uuid.UUID
+	AdvertID uuid.UUID
+	OwnerID  uuid.UUID
+	SeekerID uuid.UUID
+	// OwnerReadAt and SeekerReadAt mark the moment until which the participant has read the messages
+	OwnerReadAt   *time.Time
+	SeekerReadAt  *time.Time
+	LastMessageAt time.Time
+	// BlockedAt is set when the owner doesn't want to get more messages from the seeker
+	BlockedAt *time.Time
+	CreatedAt time.Time
+}
+
+type Message struct {
+	ID             uuid.UUID
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Body           string
+	CreatedAt      time.Time
+}
+
+// NewConversation starts the conversation of the seeker with the owner of the advert
+func NewConversation(adv *advert.Advert, seeker *user.User) (*Conversation, error) {
+	if seeker == nil {
+		return nil, NoUserProvidedErr
+	}
+	if adv.User == nil {
+		return nil, NoAdvertOwnerErr
+	}
+	if adv.User.ID == seeker.ID {
+		return nil, OwnAdvertErr
+	}
+
+	now := time.Now()
+	return &Conversation{
+		ID:            uuid.New(),
+		AdvertID:      adv.ID,
+		OwnerID:       adv.User.ID,
+		SeekerID:      seeker.ID,
+		LastMessageAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+func (c Conversation) IsParticipant(usr *user.User) bool {
+	return usr != nil && (usr.ID == c.OwnerID || usr.ID == c.SeekerID)
+}
+
+func (c Conversation) IsBlocked() bool {
+	return c.BlockedAt != nil
+}
+
+// setReadAt moves the read marker of the participant
+func (c *Conversation) setReadAt(usr *user.User, at time.Time) {
+	if usr.ID == c.OwnerID {
+		c.OwnerReadAt = &at
+		return
+	}
+	c.SeekerReadAt = &at
+}
+
+// AddMessage creates the message sent by the participant, the sender has obviously read the conversation so far
+func (c *Conversation) AddMessage(sender *user.User, body string) (*Message, error) {
+	if !c.IsParticipant(sender) {
+		return nil, NotParticipantErr
+	}
+	if c.IsBlocked() && sender.ID == c.SeekerID {
+		return nil, BlockedErr
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" || len([]rune(body)) > MaxMessageLength {
+		return nil, InvalidMessageErr
+	}
+
+	message := &Message{
+		ID:             uuid.New(),
+		ConversationID: c.ID,
+		SenderID:       sender.ID,
+		Body:           body,
+		CreatedAt:      time.Now(),
+	}
+	c.LastMessageAt = message.CreatedAt
+	c.setReadAt(sender, message.CreatedAt)
+	return message, nil
+}
+
+// MarkRead marks all messages sent until now as read by the participant
+func (c *Conversation) MarkRead(usr *user.User) error {
+	if !c.IsParticipant(usr) {
+		return NotParticipantErr
+	}
+
+	c.setReadAt(usr, time.Now())
+	return nil
+}
+
+// Block stops the seeker from sending messages, only the advert owner can do it
+func (c *Conversation) Block(usr *user.User) error {
+	if usr == nil || usr.ID != c.OwnerID {
+		return NotOwnerErr
+	}
+	if c.IsBlocked() {
+		return nil
+	}
+
+	now := time.Now()
+	c.BlockedAt = &now
+	return nil
+}
+
+func (c *Conversation) Unblock(usr *user.User) error {
+	if usr == nil || usr.ID != c.OwnerID {
+		return NotOwnerErr
+	}
+
+	c.BlockedAt = nil
+	return nil
+}
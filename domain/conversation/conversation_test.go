@@ -0,0 +1,95 @@
+package conversation
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"strings"
+	"testing"
+)
+
+func TestNewConversation(t *testing.T) {
+	owner := &user.User{ID: uuid.New()}
+	seeker := &user.User{ID: uuid.New()}
+	adv := &advert.Advert{ID: uuid.New(), User: owner}
+
+	testCases := []struct {
+		name     string
+		advert   *advert.Advert
+		seeker   *user.User
+		expected error
+	}{
+		{name: "success", advert: adv, seeker: seeker},
+		{name: "no seeker", advert: adv, expected: NoUserProvidedErr},
+		{name: "advert without owner", advert: &advert.Advert{ID: uuid.New()}, seeker: seeker, expected: NoAdvertOwnerErr},
+		{name: "own advert", advert: adv, seeker: owner, expected: OwnAdvertErr},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			conv, err := NewConversation(tC.advert, tC.seeker)
+			assert.Equal(t, tC.expected, err)
+			if tC.expected != nil {
+				return
+			}
+			assert.Equal(t, adv.ID, conv.AdvertID)
+			assert.True(t, conv.IsParticipant(owner))
+			assert.True(t, conv.IsParticipant(seeker))
+			assert.False(t, conv.IsParticipant(&user.User{ID: uuid.New()}))
+			assert.False(t, conv.IsParticipant(nil))
+		})
+	}
+}
+
+func TestConversationMessages(t *testing.T) {
+	owner := &user.User{ID: uuid.New()}
+	seeker := &user.User{ID: uuid.New()}
+	stranger := &user.User{ID: uuid.New()}
+
+	conv, err := NewConversation(&advert.Advert{ID: uuid.New(), User: owner}, seeker)
+	require.NoError(t, err)
+
+	_, err = conv.AddMessage(stranger, "hello")
+	assert.Equal(t, NotParticipantErr, err)
+	_, err = conv.AddMessage(seeker, "   ")
+	assert.Equal(t, InvalidMessageErr, err)
+	_, err = conv.AddMessage(seeker, strings.Repeat("x", MaxMessageLength+1))
+	assert.Equal(t, InvalidMessageErr, err)
+
+	msg, err := conv.AddMessage(seeker, " Is the room still available? ")
+	require.NoError(t, err)
+	assert.Equal(t, "Is the room still available?", msg.Body)
+	assert.Equal(t, conv.ID, msg.ConversationID)
+	assert.Equal(t, msg.CreatedAt, conv.LastMessageAt)
+	assert.Equal(t, &msg.CreatedAt, conv.SeekerReadAt)
+	assert.Nil(t, conv.OwnerReadAt)
+
+	assert.Equal(t, NotParticipantErr, conv.MarkRead(stranger))
+	assert.NoError(t, conv.MarkRead(owner))
+	assert.NotNil(t, conv.OwnerReadAt)
+}
+
+func TestConversationBlock(t *testing.T) {
+	owner := &user.User{ID: uuid.New()}
+	seeker := &user.User{ID: uuid.New()}
+
+	conv, err := NewConversation(&advert.Advert{ID: uuid.New(), User: owner}, seeker)
+	require.NoError(t, err)
+
+	assert.Equal(t, NotOwnerErr, conv.Block(seeker))
+	assert.NoError(t, conv.Block(owner))
+	assert.True(t, conv.IsBlocked())
+
+	_, err = conv.AddMessage(seeker, "hello?")
+	assert.Equal(t, BlockedErr, err)
+	// the owner can still write, e.g. to explain the block
+	_, err = conv.AddMessage(owner, "please stop")
+	assert.NoError(t, err)
+
+	assert.Equal(t, NotOwnerErr, conv.Unblock(seeker))
+	assert.NoError(t, conv.Unblock(owner))
+	_, err = conv.AddMessage(seeker, "sorry")
+	assert.NoError(t, err)
+}
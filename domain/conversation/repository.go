@@ -0,0 +1,27 @@
+package conversation
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Get(ctx context.Context, id uuid.UUID) (Conversation, error)
+	// GetByAdvert returns the conversation of the seeker about the advert, ConversationNotFound is returned when they haven't talked yet
+	GetByAdvert(ctx context.Context, advertID uuid.UUID, seekerID uuid.UUID) (Conversation, error)
+	// GetList returns conversations of the participant from the most recently active one
+	GetList(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]Conversation, error)
+	// Add stores the new conversation with its first message
+	Add(ctx context.Context, conv *Conversation, message *Message) error
+	// Update stores read markers of the conversation
+	Update(ctx context.Context, conv *Conversation) error
+	// UpdateBlock stores the block set by Conversation.Block or Conversation.Unblock, it's the only method writing it
+	UpdateBlock(ctx context.Context, conv *Conversation) error
+	// AddMessage stores the message with the conversation changed by Conversation.AddMessage,
+	// BlockedErr is returned when the seeker has been blocked since the conversation was loaded
+	AddMessage(ctx context.Context, conv *Conversation, message *Message) error
+	// GetMessages returns messages of the conversation from the newest one
+	GetMessages(ctx context.Context, conversationID uuid.UUID, limit int, offset int) ([]Message, error)
+	// CountUnread returns the number of unread messages per conversation of the participant, conversations without them are missing in the map
+	CountUnread(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error)
+}
@@ -0,0 +1,35 @@
+package favourite
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"time"
+)
+
+// MaxFavourites limits the shortlist of a single user
+const MaxFavourites = 200
+
+var (
+	NoUserProvidedErr    = errors.New("no user provided")
+	TooManyFavouritesErr = errors.New("user has too many favourite adverts")
+)
+
+// Favourite is the advert put on the shortlist of the user
+type Favourite struct {
+	UserID    uuid.UUID
+	AdvertID  uuid.UUID
+	CreatedAt time.Time
+}
+
+func NewFavourite(usr *user.User, advertID uuid.UUID) (*Favourite, error) {
+	if usr == nil {
+		return nil, NoUserProvidedErr
+	}
+
+	return &Favourite{
+		UserID:    usr.ID,
+		AdvertID:  advertID,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,18 @@
+package favourite
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Add puts the advert on the shortlist, adding it again keeps the original date
+	Add(ctx context.Context, favourite *Favourite) error
+	// Remove takes the advert off the shortlist, missing favourites are ignored
+	Remove(ctx context.Context, userID uuid.UUID, advertID uuid.UUID) error
+	// GetList returns favourites of the user from the most recently added one
+	GetList(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]Favourite, error)
+	Count(ctx context.Context, userID uuid.UUID) (int, error)
+	// AreFavourite tells which of the adverts are on the shortlist of the user, the rest of them are missing in the map
+	AreFavourite(ctx context.Context, userID uuid.UUID, advertIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
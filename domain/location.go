@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxLocationFieldLength = 60
+
+var (
+	InvalidLocationErr    = errors.New("invalid location")
+	InvalidCoordinatesErr = errors.New("invalid coordinates")
+)
+
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+func NewCoordinates(latitude, longitude float64) (Coordinates, error) {
+	// negated ranges reject NaN as well
+	if !(latitude >= -90 && latitude <= 90) || !(longitude >= -180 && longitude <= 180) {
+		return Coordinates{}, InvalidCoordinatesErr
+	}
+	return Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// Location is the place the advert refers to, coordinates are optional as most people know only the city
+type Location struct {
+	City        string
+	Region      string
+	Country     string
+	Coordinates *Coordinates
+}
+
+func NewLocation(city, region, country string, coordinates *Coordinates) (Location, error) {
+	location := Location{
+		City:        strings.TrimSpace(city),
+		Region:      strings.TrimSpace(region),
+		Country:     strings.TrimSpace(country),
+		Coordinates: coordinates,
+	}
+
+	if location.Country == "" || (location.City == "" && location.Region == "") {
+		return Location{}, InvalidLocationErr
+	}
+
+	for _, field := range []string{location.City, location.Region, location.Country} {
+		if utf8.RuneCountInString(field) > maxLocationFieldLength {
+			return Location{}, InvalidLocationErr
+		}
+	}
+
+	if coordinates != nil {
+		_, err := NewCoordinates(coordinates.Latitude, coordinates.Longitude)
+		if err != nil {
+			return Location{}, err
+		}
+	}
+
+	return location, nil
+}
+
+// earthRadiusKm is the mean radius used by advert_distance_km as well, so both give the same results
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between the points
+func (c Coordinates) DistanceKm(other Coordinates) float64 {
+	lat1, lat2 := c.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - c.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNewLocation(t *testing.T) {
+	warsaw := &Coordinates{Latitude: 52.2297, Longitude: 21.0122}
+
+	testCases := []struct {
+		name        string
+		city        string
+		region      string
+		country     string
+		coordinates *Coordinates
+		expectedErr error
+	}{
+		{name: "city with coordinates", city: " Warszawa ", country: "Polska", coordinates: warsaw},
+		{name: "only region", region: "Mazowieckie", country: "Polska"},
+		{name: "missing country", city: "Warszawa", expectedErr: InvalidLocationErr},
+		{name: "missing city and region", country: "Polska", coordinates: warsaw, expectedErr: InvalidLocationErr},
+		{name: "too long city", city: strings.Repeat("a", maxLocationFieldLength+1), country: "Polska", expectedErr: InvalidLocationErr},
+		{name: "invalid latitude", city: "Warszawa", country: "Polska", coordinates: &Coordinates{Latitude: 91}, expectedErr: InvalidCoordinatesErr},
+		{name: "not a number latitude", city: "Warszawa", country: "Polska", coordinates: &Coordinates{Latitude: math.NaN()}, expectedErr: InvalidCoordinatesErr},
+		{name: "invalid longitude", city: "Warszawa", country: "Polska", coordinates: &Coordinates{Longitude: -181}, expectedErr: InvalidCoordinatesErr},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			location, err := NewLocation(tC.city, tC.region, tC.country, tC.coordinates)
+			assert.Equal(t, tC.expectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, strings.TrimSpace(tC.city), location.City)
+			assert.Equal(t, tC.coordinates, location.Coordinates)
+		})
+	}
+}
+
+func TestCoordinatesDistanceKm(t *testing.T) {
+	warsaw := Coordinates{Latitude: 52.2297, Longitude: 21.0122}
+	krakow := Coordinates{Latitude: 50.0647, Longitude: 19.9450}
+
+	assert.InDelta(t, 252, warsaw.DistanceKm(krakow), 1)
+	assert.InDelta(t, warsaw.DistanceKm(krakow), krakow.DistanceKm(warsaw), 1e-9)
+	assert.Zero(t, warsaw.DistanceKm(warsaw))
+}
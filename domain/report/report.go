@@ -0,0 +1,127 @@
+package report
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"strings"
+	"time"
+)
+
+// Reason is the category chosen by the reporter, so moderators can handle the most harmful reports first
+type Reason string
+
+const (
+	ReasonScam        Reason = "scam"
+	ReasonTrafficking Reason = "trafficking"
+	ReasonSpam        Reason = "spam"
+	ReasonOffensive   Reason = "offensive"
+	ReasonOther       Reason = "other"
+)
+
+var reasons = []Reason{
+	ReasonScam, ReasonTrafficking, ReasonSpam, ReasonOffensive, ReasonOther,
+}
+
+func (r Reason) IsValid() bool {
+	for _, reason := range reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolution is the decision of the moderator who handled the report
+type Resolution string
+
+const (
+	// ResolutionUpheld means the report was right and the advert has been dealt with
+	ResolutionUpheld Resolution = "upheld"
+	// ResolutionDismissed means the advert doesn't break the rules
+	ResolutionDismissed Resolution = "dismissed"
+)
+
+func (r Resolution) IsValid() bool {
+	return r == ResolutionUpheld || r == ResolutionDismissed
+}
+
+// MaxDetailsLength matches advert_reports.details column
+const MaxDetailsLength = 1000
+
+var (
+	InvalidReasonErr     = errors.New("unknown report reason")
+	InvalidDetailsErr    = errors.New("report details are required for other reason and can't be longer than max length")
+	InvalidResolutionErr = errors.New("unknown report resolution")
+	NotModeratorErr      = errors.New("user is not a moderator")
+	AlreadyResolvedErr   = errors.New("report is already resolved")
+	AlreadyReportedErr   = errors.New("advert is already reported by the user")
+	TooManyReportsErr    = errors.New("too many reports sent by the reporter")
+	ReportNotFound       = errors.New("report not found in repository")
+)
+
+type Report struct {
+	ID       uuid.UUID
+	AdvertID uuid.UUID
+	// ReporterID is nil for anonymous reports
+	ReporterID *uuid.UUID
+	// ReporterIP is the address of the anonymous reporter, so one client counts as a single reporter of the advert
+	ReporterIP string
+	Reason     Reason
+	Details    string
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+	ResolvedBy *uuid.UUID
+	Resolution Resolution
+}
+
+// NewReport creates the report of the advert, reporter is nil for anonymous users who are told apart by reporterIP
+func NewReport(advertID uuid.UUID, reporter *user.User, reporterIP string, reason Reason, details string) (*Report, error) {
+	if !reason.IsValid() {
+		return nil, InvalidReasonErr
+	}
+
+	details = strings.TrimSpace(details)
+	if (reason == ReasonOther && details == "") || len([]rune(details)) > MaxDetailsLength {
+		return nil, InvalidDetailsErr
+	}
+
+	report := &Report{
+		ID:        uuid.New(),
+		AdvertID:  advertID,
+		Reason:    reason,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+	if reporter != nil {
+		report.ReporterID = &reporter.ID
+	} else {
+		report.ReporterIP = reporterIP
+	}
+	return report, nil
+}
+
+func (r Report) IsResolved() bool {
+	return r.ResolvedAt != nil
+}
+
+// Resolve closes the report with the decision of the moderator, resolved reports don't count towards hiding the advert
+func (r *Report) Resolve(moderator *user.User, resolution Resolution) error {
+	if moderator == nil || !moderator.IsModerator() {
+		return NotModeratorErr
+	}
+
+	if !resolution.IsValid() {
+		return InvalidResolutionErr
+	}
+
+	if r.IsResolved() {
+		return AlreadyResolvedErr
+	}
+
+	now := time.Now()
+	r.ResolvedAt = &now
+	r.ResolvedBy = &moderator.ID
+	r.Resolution = resolution
+	return nil
+}
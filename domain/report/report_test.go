@@ -0,0 +1,68 @@
+package report
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"strings"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	reporter := &user.User{ID: uuid.New(), Role: user.RoleUser}
+
+	testCases := []struct {
+		name     string
+		reporter *user.User
+		reason   Reason
+		details  string
+		expected error
+	}{
+		{name: "anonymous", reason: ReasonScam},
+		{name: "logged in", reporter: reporter, reason: ReasonTrafficking, details: "asks for passport"},
+		{name: "unknown reason", reason: "boring", expected: InvalidReasonErr},
+		{name: "other without details", reason: ReasonOther, details: "  ", expected: InvalidDetailsErr},
+		{name: "too long details", reason: ReasonSpam, details: strings.Repeat("x", MaxDetailsLength+1), expected: InvalidDetailsErr},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			advertID := uuid.New()
+			report, err := NewReport(advertID, tC.reporter, "203.0.113.7", tC.reason, tC.details)
+			assert.Equal(t, tC.expected, err)
+			if tC.expected != nil {
+				return
+			}
+			assert.Equal(t, advertID, report.AdvertID)
+			assert.Equal(t, strings.TrimSpace(tC.details), report.Details)
+			assert.False(t, report.IsResolved())
+			if tC.reporter == nil {
+				assert.Nil(t, report.ReporterID)
+				assert.Equal(t, "203.0.113.7", report.ReporterIP)
+			} else {
+				assert.Equal(t, &tC.reporter.ID, report.ReporterID)
+				assert.Empty(t, report.ReporterIP)
+			}
+		})
+	}
+}
+
+func TestReportResolve(t *testing.T) {
+	usr := &user.User{ID: uuid.New(), Role: user.RoleUser}
+	moderator := &user.User{ID: uuid.New(), Role: user.RoleModerator}
+
+	report, err := NewReport(uuid.New(), nil, "", ReasonScam, "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, NotModeratorErr, report.Resolve(nil, ResolutionUpheld))
+	assert.Equal(t, NotModeratorErr, report.Resolve(usr, ResolutionUpheld))
+	assert.Equal(t, InvalidResolutionErr, report.Resolve(moderator, "ignored"))
+	assert.False(t, report.IsResolved())
+
+	assert.NoError(t, report.Resolve(moderator, ResolutionDismissed))
+	assert.True(t, report.IsResolved())
+	assert.Equal(t, &moderator.ID, report.ResolvedBy)
+	assert.Equal(t, ResolutionDismissed, report.Resolution)
+
+	assert.Equal(t, AlreadyResolvedErr, report.Resolve(moderator, ResolutionUpheld))
+}
@@ -0,0 +1,29 @@
+package report
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+// ListFilter describes which reports should be returned by Repository.GetList, zero values mean no filtering
+type ListFilter struct {
+	AdvertID *uuid.UUID
+	// OnlyOpen skips the reports which have been already resolved
+	OnlyOpen bool
+	Limit    int
+	Offset   int
+}
+
+type Repository interface {
+	Get(ctx context.Context, id uuid.UUID) (Report, error)
+	// GetList returns reports matching the filter from the oldest one, so they are handled in order
+	GetList(ctx context.Context, filter ListFilter) ([]Report, error)
+	Add(ctx context.Context, report *Report) error
+	// Resolve stores the resolution of the report
+	Resolve(ctx context.Context, report *Report) error
+	// CountOpenReporters returns the number of distinct reporters of the not resolved reports of the advert
+	CountOpenReporters(ctx context.Context, advertID uuid.UUID) (int, error)
+	// HasOpen tells if the reporter of the report, the user or the address of the anonymous one, has already reported
+	// the same advert and the report is not resolved yet
+	HasOpen(ctx context.Context, report Report) (bool, error)
+}
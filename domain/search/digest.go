@@ -0,0 +1,66 @@
+package search
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"time"
+)
+
+// Match is the advert found by the saved search, it waits for the next digest of the user
+type Match struct {
+	SearchID  uuid.UUID
+	UserID    uuid.UUID
+	AdvertID  uuid.UUID
+	CreatedAt time.Time
+}
+
+func NewMatch(search SavedSearch, adv *advert.Advert) Match {
+	return Match{
+		SearchID:  search.ID,
+		UserID:    search.UserID,
+		AdvertID:  adv.ID,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Digest batches matches of a single user, so a burst of new adverts results in one notification
+type Digest struct {
+	UserID  uuid.UUID
+	Matches []Match
+}
+
+// AdvertIDs returns matched adverts in the order they were matched, the advert found by several searches is listed once
+func (d Digest) AdvertIDs() []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	var ids []uuid.UUID
+	for _, match := range d.Matches {
+		if seen[match.AdvertID] {
+			continue
+		}
+		seen[match.AdvertID] = true
+		ids = append(ids, match.AdvertID)
+	}
+	return ids
+}
+
+// NewDigests groups matches by user, digests are ordered by the first match of the user
+func NewDigests(matches []Match) []Digest {
+	var digests []Digest
+	byUser := map[uuid.UUID]int{}
+	for _, match := range matches {
+		i, ok := byUser[match.UserID]
+		if !ok {
+			i = len(digests)
+			byUser[match.UserID] = i
+			digests = append(digests, Digest{UserID: match.UserID})
+		}
+		digests[i].Matches = append(digests[i].Matches, match)
+	}
+	return digests
+}
+
+// Notifier delivers digests to users, e.g. by email or push notification
+type Notifier interface {
+	Notify(ctx context.Context, digest Digest) error
+}
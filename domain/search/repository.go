@@ -0,0 +1,27 @@
+package search
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"time"
+)
+
+type Repository interface {
+	Get(ctx context.Context, id uuid.UUID) (SavedSearch, error)
+	// GetList returns searches saved by the user from the oldest one
+	GetList(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error)
+	Add(ctx context.Context, savedSearch *SavedSearch) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Count(ctx context.Context, userID uuid.UUID) (int, error)
+	// GetCandidates returns searches which may match the advert of the type and title languages,
+	// i.e. the ones without types or including it and without languages or sharing one of them
+	GetCandidates(ctx context.Context, advertType domain.AdvertType, languages LanguageTags) ([]SavedSearch, error)
+	// AddMatches stores matches waiting for the digest, matches stored already are ignored
+	AddMatches(ctx context.Context, matches []Match) error
+	// GetPendingMatches returns matches not notified yet from the oldest one, skipping adverts which aren't published currently.
+	// Skipped matches stay pending, so they are sent if the advert is published again.
+	GetPendingMatches(ctx context.Context, limit int) ([]Match, error)
+	MarkNotified(ctx context.Context, matches []Match, notifiedAt time.Time) error
+}
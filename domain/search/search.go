@@ -0,0 +1,167 @@
+package search
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxSavedSearches limits searches saved by a single user
+	MaxSavedSearches = 20
+	// MaxQueryLength matches the limit of the search query of the adverts list
+	MaxQueryLength = 100
+)
+
+var (
+	NoUserProvidedErr  = errors.New("no user provided")
+	EmptyCriteriaErr   = errors.New("saved search needs at least one criterion")
+	QueryTooLongErr    = errors.New("search query too long")
+	InvalidLanguageErr = errors.New("unsupported language")
+	TooManySearchesErr = errors.New("user has too many saved searches")
+	SearchNotFound     = errors.New("saved search not found in repository")
+	MatchingFailedErr  = errors.New("matching saved searches failed")
+)
+
+// Criteria describes adverts the user is looking for, zero values mean no filtering like in advert.ListFilter
+type Criteria struct {
+	Types     []domain.AdvertType
+	Languages LanguageTags
+	// City and Region are matched case-insensitively
+	City   string
+	Region string
+	// Near with RadiusKm matches adverts located within the radius from the point
+	Near     *domain.Coordinates
+	RadiusKm float64
+	// Query is matched when every word of it is found in the title or the description
+	Query string
+}
+
+func (c Criteria) Empty() bool {
+	return len(c.Types) == 0 && c.Languages.Empty() && c.City == "" && c.Region == "" && c.Near == nil && c.Query == ""
+}
+
+// Filter returns the filter listing adverts matching the criteria, so the saved search can be opened as a regular list
+func (c Criteria) Filter() advert.ListFilter {
+	return advert.ListFilter{
+		Types:     c.Types,
+		Languages: c.Languages,
+		City:      c.City,
+		Region:    c.Region,
+		Near:      c.Near,
+		RadiusKm:  c.RadiusKm,
+		Query:     c.Query,
+	}
+}
+
+type SavedSearch struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Criteria  Criteria
+	CreatedAt time.Time
+}
+
+func NewSavedSearch(usr *user.User, criteria Criteria) (*SavedSearch, error) {
+	if usr == nil {
+		return nil, NoUserProvidedErr
+	}
+
+	criteria.City = strings.TrimSpace(criteria.City)
+	criteria.Region = strings.TrimSpace(criteria.Region)
+	criteria.Query = strings.TrimSpace(criteria.Query)
+	if criteria.Empty() {
+		return nil, EmptyCriteriaErr
+	}
+
+	if len(criteria.Languages.Supported()) != len(criteria.Languages) {
+		return nil, InvalidLanguageErr
+	}
+
+	if len([]rune(criteria.Query)) > MaxQueryLength {
+		return nil, QueryTooLongErr
+	}
+
+	// the same rules apply as to the adverts list, e.g. types have to be known and the radius limited
+	err := criteria.Filter().Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SavedSearch{
+		ID:        uuid.New(),
+		UserID:    usr.ID,
+		Criteria:  criteria,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (s SavedSearch) BelongsTo(usr *user.User) bool {
+	return usr != nil && usr.ID == s.UserID
+}
+
+// Matches tells if the published advert fulfills all criteria of the search. The query is matched by words,
+// so it's looser than the full-text search of the adverts list, which is fine for notifications.
+func (s SavedSearch) Matches(adv *advert.Advert) bool {
+	c := s.Criteria
+	details := adv.Details
+
+	if len(c.Types) > 0 && !containsType(c.Types, details.Type) {
+		return false
+	}
+
+	if !c.Languages.Empty() && details.Title.Filter(c.Languages).Empty() {
+		return false
+	}
+
+	if c.City != "" || c.Region != "" || c.Near != nil {
+		if details.Location == nil {
+			return false
+		}
+		if c.City != "" && !strings.EqualFold(c.City, details.Location.City) {
+			return false
+		}
+		if c.Region != "" && !strings.EqualFold(c.Region, details.Location.Region) {
+			return false
+		}
+		if c.Near != nil && (details.Location.Coordinates == nil || c.Near.DistanceKm(*details.Location.Coordinates) > c.RadiusKm) {
+			return false
+		}
+	}
+
+	if c.Query != "" && !containsWords(details, strings.Fields(strings.ToLower(c.Query))) {
+		return false
+	}
+	return true
+}
+
+func containsType(types []domain.AdvertType, advertType domain.AdvertType) bool {
+	for _, t := range types {
+		if t == advertType {
+			return true
+		}
+	}
+	return false
+}
+
+func containsWords(details domain.AdvertDetails, words []string) bool {
+	var texts []string
+	for _, title := range details.Title {
+		texts = append(texts, title)
+	}
+	for _, description := range details.Description {
+		texts = append(texts, description)
+	}
+	text := strings.ToLower(strings.Join(texts, " "))
+
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
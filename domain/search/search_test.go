@@ -0,0 +1,119 @@
+package search
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"strings"
+	"testing"
+)
+
+func TestNewSavedSearch(t *testing.T) {
+	usr := &user.User{ID: uuid.New()}
+	warsaw := &domain.Coordinates{Latitude: 52.2297, Longitude: 21.0122}
+
+	testCases := []struct {
+		name     string
+		user     *user.User
+		criteria Criteria
+		expected error
+	}{
+		{name: "type", user: usr, criteria: Criteria{Types: []domain.AdvertType{domain.AdvertTypeJob}}},
+		{name: "radius", user: usr, criteria: Criteria{Near: warsaw, RadiusKm: 20}},
+		{name: "no user", criteria: Criteria{Query: "flat"}, expected: NoUserProvidedErr},
+		{name: "no criteria", user: usr, criteria: Criteria{City: "  "}, expected: EmptyCriteriaErr},
+		{name: "unsupported language", user: usr, criteria: Criteria{Languages: LanguageTags{"de"}}, expected: InvalidLanguageErr},
+		{name: "too long query", user: usr, criteria: Criteria{Query: strings.Repeat("x", MaxQueryLength+1)}, expected: QueryTooLongErr},
+		{name: "unknown type", user: usr, criteria: Criteria{Types: []domain.AdvertType{"boat"}}, expected: advert.InvalidAdvertTypeErr},
+		{name: "radius too large", user: usr, criteria: Criteria{Near: warsaw, RadiusKm: advert.MaxRadiusKm + 1}, expected: advert.InvalidRadiusErr},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			search, err := NewSavedSearch(tC.user, tC.criteria)
+			assert.ErrorIs(t, err, tC.expected)
+			if tC.expected != nil {
+				return
+			}
+			assert.Equal(t, usr.ID, search.UserID)
+			assert.True(t, search.BelongsTo(usr))
+			assert.False(t, search.BelongsTo(&user.User{ID: uuid.New()}))
+		})
+	}
+}
+
+func TestSavedSearchMatches(t *testing.T) {
+	adv := &advert.Advert{
+		ID: uuid.New(),
+		Details: domain.AdvertDetails{
+			Title:       MultilingualString{Polish: "Mieszkanie dla rodziny", English: "Flat for a family"},
+			Description: MultilingualString{Polish: "Dwa pokoje w centrum", English: "Two rooms downtown"},
+			Type:        domain.AdvertTypePlaceToStay,
+			Location: &domain.Location{
+				City:        "Kraków",
+				Region:      "Małopolskie",
+				Country:     "Polska",
+				Coordinates: &domain.Coordinates{Latitude: 50.0647, Longitude: 19.9450},
+			},
+		},
+	}
+	warsaw := &domain.Coordinates{Latitude: 52.2297, Longitude: 21.0122}
+
+	testCases := []struct {
+		name     string
+		criteria Criteria
+		expected bool
+	}{
+		{name: "type", criteria: Criteria{Types: []domain.AdvertType{domain.AdvertTypeJob, domain.AdvertTypePlaceToStay}}, expected: true},
+		{name: "other type", criteria: Criteria{Types: []domain.AdvertType{domain.AdvertTypeJob}}},
+		{name: "language", criteria: Criteria{Languages: LanguageTags{Ukrainian, Polish}}, expected: true},
+		{name: "missing language", criteria: Criteria{Languages: LanguageTags{Ukrainian}}},
+		{name: "city ignoring case", criteria: Criteria{City: "kraków"}, expected: true},
+		{name: "other region", criteria: Criteria{City: "Kraków", Region: "Mazowieckie"}},
+		{name: "within radius", criteria: Criteria{Near: warsaw, RadiusKm: 300}, expected: true},
+		{name: "out of radius", criteria: Criteria{Near: warsaw, RadiusKm: 100}},
+		{name: "query words in different fields", criteria: Criteria{Query: "FAMILY downtown"}, expected: true},
+		{name: "query word missing", criteria: Criteria{Query: "family garden"}},
+		{name: "all criteria", criteria: Criteria{
+			Types:     []domain.AdvertType{domain.AdvertTypePlaceToStay},
+			Languages: LanguageTags{English},
+			Region:    "małopolskie",
+			Query:     "pokoje",
+		}, expected: true},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			search := SavedSearch{ID: uuid.New(), UserID: uuid.New(), Criteria: tC.criteria}
+			assert.Equal(t, tC.expected, search.Matches(adv))
+		})
+	}
+
+	t.Run("location required", func(t *testing.T) {
+		search := SavedSearch{Criteria: Criteria{City: "Kraków"}}
+		assert.False(t, search.Matches(&advert.Advert{Details: domain.AdvertDetails{Title: adv.Details.Title}}))
+	})
+}
+
+func TestNewDigests(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	advertA, advertB := uuid.New(), uuid.New()
+
+	matches := []Match{
+		{SearchID: uuid.New(), UserID: first, AdvertID: advertA},
+		{SearchID: uuid.New(), UserID: second, AdvertID: advertA},
+		{SearchID: uuid.New(), UserID: first, AdvertID: advertB},
+		{SearchID: uuid.New(), UserID: first, AdvertID: advertA},
+	}
+
+	digests := NewDigests(matches)
+	assert.Len(t, digests, 2)
+	assert.Equal(t, first, digests[0].UserID)
+	assert.Len(t, digests[0].Matches, 3)
+	assert.Equal(t, []uuid.UUID{advertA, advertB}, digests[0].AdvertIDs())
+	assert.Equal(t, second, digests[1].UserID)
+	assert.Equal(t, []uuid.UUID{advertA}, digests[1].AdvertIDs())
+}
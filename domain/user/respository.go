@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"github.com/google/uuid"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
 )
 
 type Repository interface {
@@ -11,4 +12,5 @@ type Repository interface {
 	Add(ctx context.Context, user *User) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Exists(ctx context.Context, login string) (bool, error)
+	UpdateLanguage(ctx context.Context, id uuid.UUID, language LanguageTag) error
 }
@@ -5,7 +5,18 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/ukrainian-brothers/board-backend/domain"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
 	"time"
+	"unicode/utf8"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
 )
 
 type User struct {
@@ -14,22 +25,41 @@ type User struct {
 	Password       *string
 	Person         domain.Person
 	ContactDetails domain.ContactDetails
+	Role           Role
+	// Trusted users may have their adverts published without review, see advert.ModerationPolicy
+	Trusted bool
+	// Language the user wants to get messages in, empty when the user hasn't chosen any
+	Language LanguageTag
 }
 
 var (
 	MissingPersonalDataErr = errors.New("missing personal data")
+	InvalidPersonalDataErr = errors.New("invalid personal data")
 	MissingContactDataErr  = errors.New("missing contact data")
+	InvalidLanguageErr     = errors.New("unsupported language")
 )
 
+// MaxNameLength matches name and surname columns of users
+const MaxNameLength = 15
+
 func NewUser(firstName string, sureName string, login string, password string, contactDetails domain.ContactDetails) (*User, error) {
 	// TODO: regex for login, password and write tests
 
-	if firstName == "" || sureName == "" {
-		return nil, MissingPersonalDataErr
+	var errs validation.Errors
+	for _, field := range []struct{ name, value string }{{"firstname", firstName}, {"surname", sureName}} {
+		if field.value == "" {
+			errs.Add(validation.NewFieldError(field.name, validation.RequiredCode, MissingPersonalDataErr))
+		} else if utf8.RuneCountInString(field.value) > MaxNameLength {
+			errs.Add(validation.NewFieldError(field.name, validation.TooLongCode, InvalidPersonalDataErr).With("max_length", MaxNameLength))
+		}
 	}
 
 	if contactDetails.IsEmpty() {
-		return nil, MissingContactDataErr
+		errs.Add(validation.NewFieldError("contact_details", validation.RequiredCode, MissingContactDataErr))
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
 	}
 
 	usr := &User{
@@ -41,11 +71,39 @@ func NewUser(firstName string, sureName string, login string, password string, c
 			Surname:   sureName,
 		},
 		ContactDetails: contactDetails,
+		Role:           RoleUser,
 	}
 
 	return usr, nil
 }
 
+// SetLanguage sets the preferred language given as BCP 47 tag, empty tag clears the preference
+func (u *User) SetLanguage(tag string) error {
+	if tag == "" {
+		u.Language = ""
+		return nil
+	}
+
+	language, err := ParseLanguageTag(tag)
+	if err != nil {
+		return validation.Errors{validation.NewFieldError("language", validation.InvalidLanguageCode, InvalidLanguageErr).With("language", tag)}
+	}
+	if !SupportedLanguages().Contains(language) {
+		return validation.Errors{validation.NewFieldError("language", validation.UnsupportedLanguageCode, InvalidLanguageErr).With("language", tag)}
+	}
+	u.Language = language
+	return nil
+}
+
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsModerator tells if the user can review adverts, admins are moderators as well
+func (u User) IsModerator() bool {
+	return u.Role == RoleModerator || u.Role == RoleAdmin
+}
+
 type Social struct {
 	UserID       uuid.UUID       `json:"user_id"`
 	Social       string          `json:"social"`
@@ -14,4 +14,7 @@ func Assert(t *testing.T, expected *User, actual *User) {
 	assert.Equal(t, expected.Person.Surname, actual.Person.Surname)
 	assert.Equal(t, expected.ContactDetails.Mail, actual.ContactDetails.Mail)
 	assert.Equal(t, expected.ContactDetails.PhoneNumber, actual.ContactDetails.PhoneNumber)
+	assert.Equal(t, expected.Role, actual.Role)
+	assert.Equal(t, expected.Trusted, actual.Trusted)
+	assert.Equal(t, expected.Language, actual.Language)
 }
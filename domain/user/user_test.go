@@ -4,6 +4,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/ukrainian-brothers/board-backend/domain"
 	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+	"strings"
 	"testing"
 )
 
@@ -56,8 +59,42 @@ func TestUserCreation(t *testing.T) {
 	for _, tC := range testCases {
 		t.Run(tC.testName, func(t *testing.T) {
 			_, err := NewUser(tC.firstName, tC.surname, tC.login, tC.password, tC.contactDetails)
-			assert.Equal(t, tC.expectedErr, err)
+			assert.ErrorIs(t, err, tC.expectedErr)
 		})
 
 	}
 }
+
+func TestUserValidationErrors(t *testing.T) {
+	_, err := NewUser("", strings.Repeat("x", MaxNameLength+1), "login", "abc", domain.ContactDetails{})
+	assert.Equal(t, validation.Errors{
+		validation.NewFieldError("firstname", validation.RequiredCode, MissingPersonalDataErr),
+		validation.NewFieldError("surname", validation.TooLongCode, InvalidPersonalDataErr).With("max_length", MaxNameLength),
+		validation.NewFieldError("contact_details", validation.RequiredCode, MissingContactDataErr),
+	}, err)
+	assert.ErrorIs(t, err, validation.InvalidErr)
+}
+
+func TestUserSetLanguage(t *testing.T) {
+	usr := User{}
+
+	assert.NoError(t, usr.SetLanguage("uk-UA"))
+	assert.Equal(t, Ukrainian, usr.Language)
+
+	// legacy code is accepted as well
+	assert.NoError(t, usr.SetLanguage("ua"))
+	assert.Equal(t, Ukrainian, usr.Language)
+
+	err := usr.SetLanguage("de")
+	assert.ErrorIs(t, err, InvalidLanguageErr)
+	assert.Equal(t, validation.Errors{
+		validation.NewFieldError("language", validation.UnsupportedLanguageCode, InvalidLanguageErr).With("language", "de"),
+	}, err)
+	assert.Equal(t, Ukrainian, usr.Language)
+
+	err = usr.SetLanguage("x")
+	assert.ErrorIs(t, err, InvalidLanguageErr)
+
+	assert.NoError(t, usr.SetLanguage(""))
+	assert.Empty(t, usr.Language)
+}
@@ -0,0 +1,126 @@
+package advert
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal"
+	"github.com/ukrainian-brothers/board-backend/internal/common"
+	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"testing"
+)
+
+const benchmarkPageSize = 50
+
+func (tr *advertTranslations) Filter(langs []LanguageTag) {
+	tr.Title = tr.Title.Filter(langs)
+	tr.Description = tr.Description.Filter(langs)
+}
+
+// getListPerAdvert is the previous GetList implementation kept for comparison,
+// it loads translations with a query per advert and drops adverts without requested languages after LIMIT.
+// The adverts are restricted by the user, status and expiry like in GetList, so both read the same rows.
+func (repo PostgresAdvertRepository) getListPerAdvert(ctx context.Context, filter advert.ListFilter) ([]*advert.Advert, error) {
+	sqlExec := repo.db.WithContext(ctx)
+
+	var advertsDB []AdvertDB
+	_, err := sqlExec.Select(&advertsDB, `SELECT * FROM adverts
+		WHERE user_id=$1 AND status=$2 AND destroyed_at IS NULL AND archived_at IS NULL AND expires_at > now()
+		ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4`,
+		filter.UserID.String(), string(advert.StatusPublished), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting many adverts with translations: %w", err)
+	}
+
+	var adverts []*advert.Advert
+	for _, advDB := range advertsDB {
+		translation, err := repo.getAdvertTranslations(ctx, advDB.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !filter.Languages.Empty() {
+			translation.Filter(filter.Languages)
+		}
+
+		if translation.Title.Empty() || translation.Description.Empty() {
+			continue
+		}
+
+		adverts = append(adverts, &advert.Advert{
+			ID: advDB.ID,
+			Details: domain.AdvertDetails{
+				Title:          translation.Title,
+				Description:    translation.Description,
+				Type:           advDB.Type,
+				Views:          advDB.Views,
+				ContactDetails: advDB.ContactDetails,
+			},
+			User:        &user.User{ID: advDB.UserID},
+			CreatedAt:   advDB.CreatedAt,
+			UpdatedAt:   advDB.UpdatedAt,
+			DestroyedAt: advDB.DestroyedAt,
+		})
+	}
+
+	return adverts, nil
+}
+
+func BenchmarkAdvertPostgresGetList(b *testing.B) {
+	cfg := internal.GetTestConfig(b)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(b, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("benchmark_user"))
+	require.NoError(b, db.Insert(&userDB))
+	defer func() {
+		_, err := db.Exec("DELETE FROM adverts WHERE user_id=$1", userDB.ID.String())
+		require.NoError(b, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID.String())
+		require.NoError(b, err)
+	}()
+
+	// every second advert is written only in polish, so it is filtered out when asking for ukrainian
+	for i := 0; i < 2*benchmarkPageSize; i++ {
+		advDB := GenerateTestAdvertDB(uuid_(fmt.Sprintf("benchmark_advert_%d", i)), userDB.ID)
+		require.NoError(b, db.Insert(&advDB))
+
+		languages := []LanguageTag{Polish}
+		if i%2 == 0 {
+			languages = append(languages, Ukrainian, English)
+		}
+		for _, lang := range languages {
+			detailsDB := GenerateTestAdvertDetailsDB(advDB.ID, lang)
+			require.NoError(b, db.Insert(&detailsDB))
+		}
+	}
+
+	// both paths get the same filter, so they read the adverts of the benchmark user only
+	filter := advert.ListFilter{Languages: LanguageTags{Ukrainian}, UserID: &userDB.ID, Limit: benchmarkPageSize}
+	b.Run("query per advert", func(b *testing.B) {
+		var adverts []*advert.Advert
+		for i := 0; i < b.N; i++ {
+			adverts, err = repo.getListPerAdvert(context.Background(), filter)
+			require.NoError(b, err)
+		}
+		b.ReportMetric(float64(len(adverts)), "adverts/op")
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		var adverts []*advert.Advert
+		for i := 0; i < b.N; i++ {
+			adverts, err = repo.GetList(context.Background(), filter)
+			require.NoError(b, err)
+		}
+		b.ReportMetric(float64(len(adverts)), "adverts/op")
+	})
+}
@@ -0,0 +1,110 @@
+package advert
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"strings"
+)
+
+// kmPerLatitudeDegree is the length of a degree of latitude, which is almost the same everywhere on the Earth
+const kmPerLatitudeDegree = 111.0
+
+// queryArgs collects arguments of dynamically built query, add returns the placeholder of the added argument
+type queryArgs []interface{}
+
+func (a *queryArgs) add(arg interface{}) string {
+	*a = append(*a, arg)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// listLanguages returns languages of the translations returned by the list, unsupported languages are never returned
+func listLanguages(filter advert.ListFilter) []string {
+	if filter.Languages.Empty() {
+		return SupportedLanguages().Strings()
+	}
+	return filter.Languages.Supported().Strings()
+}
+
+// listConditions translates the filter into the WHERE conditions on the adverts table
+func listConditions(filter advert.ListFilter, args *queryArgs) string {
+	// adverts without any translation in listed languages are filtered out here, so LIMIT is applied to the adverts actually returned
+	conditions := []string{fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM adverts_details WHERE adverts_details.advert_id = adverts.id AND adverts_details.language = ANY(%s::varchar[]))",
+		args.add(pq.Array(listLanguages(filter))),
+	)}
+
+	if !filter.IncludeDestroyed {
+		conditions = append(conditions, "adverts.destroyed_at IS NULL")
+	}
+
+	if !filter.IncludeExpired {
+		conditions = append(conditions, "adverts.archived_at IS NULL AND adverts.expires_at > now()")
+	}
+
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []advert.Status{advert.StatusPublished}
+	}
+	var statusNames []string
+	for _, status := range statuses {
+		statusNames = append(statusNames, string(status))
+	}
+	conditions = append(conditions, fmt.Sprintf("adverts.status = ANY(%s::varchar[])", args.add(pq.Array(statusNames))))
+
+	if len(filter.Types) > 0 {
+		var types []string
+		for _, advertType := range filter.Types {
+			types = append(types, string(advertType))
+		}
+		conditions = append(conditions, fmt.Sprintf("adverts.type = ANY(%s::varchar[])", args.add(pq.Array(types))))
+	}
+
+	if filter.UserID != nil {
+		conditions = append(conditions, fmt.Sprintf("adverts.user_id = %s", args.add(filter.UserID.String())))
+	}
+
+	if len(filter.IDs) > 0 {
+		var ids []string
+		for _, id := range filter.IDs {
+			ids = append(ids, id.String())
+		}
+		conditions = append(conditions, fmt.Sprintf("adverts.id = ANY(%s::varchar[])", args.add(pq.Array(ids))))
+	}
+
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, fmt.Sprintf("adverts.created_at > %s", args.add(*filter.CreatedAfter)))
+	}
+
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("adverts.created_at < %s", args.add(*filter.CreatedBefore)))
+	}
+
+	if filter.City != "" {
+		conditions = append(conditions, fmt.Sprintf("lower(adverts.city) = lower(%s)", args.add(filter.City)))
+	}
+
+	if filter.Region != "" {
+		conditions = append(conditions, fmt.Sprintf("lower(adverts.region) = lower(%s)", args.add(filter.Region)))
+	}
+
+	if filter.Near != nil {
+		latitude, longitude := args.add(filter.Near.Latitude), args.add(filter.Near.Longitude)
+		radius := args.add(filter.RadiusKm)
+		// latitude range narrows down the rows using adverts_latitude_index before computing the exact distance
+		conditions = append(conditions, fmt.Sprintf(
+			"adverts.latitude BETWEEN %[1]s::float8 - %[3]s::float8 / %[4]v AND %[1]s::float8 + %[3]s::float8 / %[4]v "+
+				"AND advert_distance_km(adverts.latitude, adverts.longitude, %[1]s, %[2]s) <= %[3]s",
+			latitude, longitude, radius, kmPerLatitudeDegree,
+		))
+	}
+
+	if filter.After != nil {
+		// row comparison matches the (created_at, id) ordering of the list, so it can use adverts_created_at_id_index
+		conditions = append(conditions, fmt.Sprintf("(adverts.created_at, adverts.id) < (%s, %s)",
+			args.add(filter.After.CreatedAt), args.add(filter.After.ID.String())))
+	}
+
+	return strings.Join(conditions, " AND ")
+}
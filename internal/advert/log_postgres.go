@@ -0,0 +1,88 @@
+package advert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-gorp/gorp"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"time"
+)
+
+type PostgresAdvertLogRepository struct {
+	db *gorp.DbMap
+}
+
+func NewPostgresAdvertLogRepository(db *gorp.DbMap) *PostgresAdvertLogRepository {
+	db.AddTableWithName(AdvertLogDB{}, "advert_logs").SetKeys(false, "id")
+
+	return &PostgresAdvertLogRepository{
+		db: db,
+	}
+}
+
+type AdvertLogDB struct {
+	ID        uuid.UUID               `db:"id"`
+	AdvertID  uuid.UUID               `db:"advert_id"`
+	UserID    uuid.UUID               `db:"user_id"`
+	Trigger   advert.AdvertLogTrigger `db:"trigger"`
+	Meta      json.RawMessage         `db:"meta,json"`
+	CreatedAt time.Time               `db:"created_at"`
+}
+
+// insertAdvertLogs is used by PostgresAdvertRepository, so logs are stored within the same transaction as the change itself
+func insertAdvertLogs(sqlExecutor gorp.SqlExecutor, logs []advert.AdvertLog) error {
+	for _, log := range logs {
+		logDB := AdvertLogDB{
+			ID:        log.ID,
+			AdvertID:  log.AdvertID,
+			UserID:    log.UserID,
+			Trigger:   log.Trigger,
+			Meta:      log.Meta,
+			CreatedAt: log.CreatedAt,
+		}
+		err := sqlExecutor.Insert(&logDB)
+		if err != nil {
+			return fmt.Errorf("failed inserting %s advert log: %w", log.Trigger, err)
+		}
+	}
+	return nil
+}
+
+// GetHistory returns logs of the advert ordered from the oldest one, destroyed adverts are included.
+func (repo PostgresAdvertLogRepository) GetHistory(ctx context.Context, advertID uuid.UUID) (advert.AdvertHistory, error) {
+	sqlExec := repo.db.WithContext(ctx)
+
+	ownerID, err := sqlExec.SelectStr("SELECT user_id FROM adverts WHERE id=$1", advertID.String())
+	if err != nil {
+		return advert.AdvertHistory{}, fmt.Errorf("failed selecting advert owner: %w", err)
+	}
+
+	owner, err := uuid.Parse(ownerID)
+	if err != nil {
+		return advert.AdvertHistory{}, fmt.Errorf("failed parsing advert owner id: %w", err)
+	}
+
+	var logsDB []AdvertLogDB
+	_, err = sqlExec.Select(&logsDB, "SELECT * FROM advert_logs WHERE advert_id=$1 ORDER BY created_at", advertID.String())
+	if err != nil {
+		return advert.AdvertHistory{}, fmt.Errorf("failed selecting advert logs: %w", err)
+	}
+
+	history := advert.AdvertHistory{
+		AdvertID: advertID,
+		OwnerID:  owner,
+	}
+	for _, logDB := range logsDB {
+		history.Logs = append(history.Logs, advert.AdvertLog{
+			ID:        logDB.ID,
+			AdvertID:  logDB.AdvertID,
+			UserID:    logDB.UserID,
+			Trigger:   logDB.Trigger,
+			Meta:      logDB.Meta,
+			CreatedAt: logDB.CreatedAt,
+		})
+	}
+	return history, nil
+}
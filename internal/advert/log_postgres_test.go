@@ -0,0 +1,69 @@
+package advert
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal"
+	"github.com/ukrainian-brothers/board-backend/internal/common"
+	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"testing"
+)
+
+func TestAdvertLogPostgresGetHistory(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	logRepo := NewPostgresAdvertLogRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	usr, err := user.NewUser("Mac", "Cheese", test_helpers.RandomString(10), "pass", getContactDetails())
+	require.NoError(t, err)
+	usrDB := internalUser.UserDB{}
+	usrDB.LoadUser(usr)
+	require.NoError(t, db.Insert(&usrDB))
+	defer func() {
+		_, err := db.Exec("DELETE FROM users WHERE id=$1", usr.ID)
+		assert.NoError(t, err)
+	}()
+
+	adv, err := advert.NewAdvert(usr, MultilingualString{English: "x"}, MultilingualString{English: "x"}, domain.AdvertTypeTransport)
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(context.Background(), adv))
+	defer func() {
+		// advert_logs should be removed due to fk policy
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", adv.ID)
+		assert.NoError(t, err)
+	}()
+	assert.Empty(t, adv.Logs)
+
+	require.NoError(t, adv.Update(usr, MultilingualString{English: "y"}, nil, ""))
+	require.NoError(t, repo.Update(context.Background(), adv))
+
+	require.NoError(t, adv.Destroy(usr))
+	require.NoError(t, repo.Delete(context.Background(), adv))
+
+	history, err := logRepo.GetHistory(context.Background(), adv.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, usr.ID, history.OwnerID)
+	require.Len(t, history.Logs, 3)
+	assert.Equal(t, advert.AdvertCreatedEvent, history.Logs[0].Trigger)
+	assert.Equal(t, advert.AdvertUpdatedEvent, history.Logs[1].Trigger)
+	assert.Equal(t, advert.AdvertDeletedEvent, history.Logs[2].Trigger)
+	for _, log := range history.Logs {
+		assert.Equal(t, usr.ID, log.UserID)
+	}
+
+	_, err = logRepo.GetHistory(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
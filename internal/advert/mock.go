@@ -4,14 +4,11 @@ package advert
 
 import (
 	context "context"
-
-	advert "github.com/ukrainian-brothers/board-backend/domain/advert"
-
-	mock "github.com/stretchr/testify/mock"
-
-	translation "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	time "time"
 
 	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+	advert "github.com/ukrainian-brothers/board-backend/domain/advert"
 )
 
 // RepositoryMock is an autogenerated mock type for the Repository type
@@ -33,13 +30,64 @@ func (_m *RepositoryMock) Add(ctx context.Context, _a1 *advert.Advert) error {
 	return r0
 }
 
-// Delete provides a mock function with given fields: ctx, id
-func (_m *RepositoryMock) Delete(ctx context.Context, id uuid.UUID) error {
-	ret := _m.Called(ctx, id)
+// AddImage provides a mock function with given fields: ctx, _a1, img
+func (_m *RepositoryMock) AddImage(ctx context.Context, _a1 *advert.Advert, img advert.Image) error {
+	ret := _m.Called(ctx, _a1, img)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, *advert.Advert, advert.Image) error); ok {
+		r0 = rf(ctx, _a1, img)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ArchiveExpired provides a mock function with given fields: ctx, now
+func (_m *RepositoryMock) ArchiveExpired(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []uuid.UUID
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []uuid.UUID); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, _a1
+func (_m *RepositoryMock) Delete(ctx context.Context, _a1 *advert.Advert) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *advert.Advert) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FailTranslation provides a mock function with given fields: ctx, id, maxAttempts
+func (_m *RepositoryMock) FailTranslation(ctx context.Context, id uuid.UUID, maxAttempts int) error {
+	ret := _m.Called(ctx, id, maxAttempts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) error); ok {
+		r0 = rf(ctx, id, maxAttempts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -68,13 +116,36 @@ func (_m *RepositoryMock) Get(ctx context.Context, id uuid.UUID) (advert.Advert,
 	return r0, r1
 }
 
-// GetList provides a mock function with given fields: ctx, langs, limit, offset
-func (_m *RepositoryMock) GetList(ctx context.Context, langs translation.LanguageTags, limit int, offset int) ([]*advert.Advert, error) {
-	ret := _m.Called(ctx, langs, limit, offset)
+// GetList provides a mock function with given fields: ctx, filter
+func (_m *RepositoryMock) GetList(ctx context.Context, filter advert.ListFilter) ([]*advert.Advert, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*advert.Advert
+	if rf, ok := ret.Get(0).(func(context.Context, advert.ListFilter) []*advert.Advert); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*advert.Advert)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, advert.ListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUntranslated provides a mock function with given fields: ctx, limit
+func (_m *RepositoryMock) GetUntranslated(ctx context.Context, limit int) ([]*advert.Advert, error) {
+	ret := _m.Called(ctx, limit)
 
 	var r0 []*advert.Advert
-	if rf, ok := ret.Get(0).(func(context.Context, translation.LanguageTags, int, int) []*advert.Advert); ok {
-		r0 = rf(ctx, langs, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*advert.Advert); ok {
+		r0 = rf(ctx, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*advert.Advert)
@@ -82,11 +153,81 @@ func (_m *RepositoryMock) GetList(ctx context.Context, langs translation.Languag
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, translation.LanguageTags, int, int) error); ok {
-		r1 = rf(ctx, langs, limit, offset)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
 
 	return r0, r1
 }
+
+// IncrementContactReveals provides a mock function with given fields: ctx, id
+func (_m *RepositoryMock) IncrementContactReveals(ctx context.Context, id uuid.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IncrementViews provides a mock function with given fields: ctx, id
+func (_m *RepositoryMock) IncrementViews(ctx context.Context, id uuid.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveImage provides a mock function with given fields: ctx, _a1, img
+func (_m *RepositoryMock) RemoveImage(ctx context.Context, _a1 *advert.Advert, img advert.Image) error {
+	ret := _m.Called(ctx, _a1, img)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *advert.Advert, advert.Image) error); ok {
+		r0 = rf(ctx, _a1, img)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveTranslations provides a mock function with given fields: ctx, _a1
+func (_m *RepositoryMock) SaveTranslations(ctx context.Context, _a1 *advert.Advert) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *advert.Advert) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, _a1
+func (_m *RepositoryMock) Update(ctx context.Context, _a1 *advert.Advert) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *advert.Advert) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -2,9 +2,11 @@ package advert
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/go-gorp/gorp"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/ukrainian-brothers/board-backend/domain"
 	"github.com/ukrainian-brothers/board-backend/domain/advert"
 	"github.com/ukrainian-brothers/board-backend/domain/user"
@@ -19,6 +21,8 @@ type PostgresAdvertRepository struct {
 func NewPostgresAdvertRepository(db *gorp.DbMap) *PostgresAdvertRepository {
 	db.AddTableWithName(AdvertDB{}, "adverts").SetKeys(false, "id")
 	db.AddTableWithName(AdvertDetailsDB{}, "adverts_details").SetKeys(false, "id")
+	db.AddTableWithName(AdvertLogDB{}, "advert_logs").SetKeys(false, "id")
+	db.AddTableWithName(ImageDB{}, "advert_images").SetKeys(false, "id")
 
 	return &PostgresAdvertRepository{
 		db: db,
@@ -30,14 +34,85 @@ func newStringPtr(s string) *string {
 }
 
 type AdvertDB struct {
-	ID             uuid.UUID             `db:"id"`
-	UserID         uuid.UUID             `db:"user_id"`
-	Type           domain.AdvertType     `db:"type"`
-	Views          int                   `db:"views"`
-	ContactDetails domain.ContactDetails `db:"contact_details,json"`
-	CreatedAt      time.Time             `db:"created_at"`
-	UpdatedAt      *time.Time            `db:"updated_at"`
-	DestroyedAt    *time.Time            `db:"destroyed_at"`
+	ID             uuid.UUID               `db:"id"`
+	UserID         uuid.UUID               `db:"user_id"`
+	Type           domain.AdvertType       `db:"type"`
+	Views          int                     `db:"views"`
+	ContactReveals int                     `db:"contact_reveals"`
+	ContactDetails domain.ContactDetails   `db:"contact_details,json"`
+	CreatedAt      time.Time               `db:"created_at"`
+	UpdatedAt      *time.Time              `db:"updated_at"`
+	DestroyedAt    *time.Time              `db:"destroyed_at"`
+	ExpiresAt      time.Time               `db:"expires_at"`
+	ArchivedAt     *time.Time              `db:"archived_at"`
+	TranslatedAt   *time.Time              `db:"translated_at"`
+	Attributes     domain.AdvertAttributes `db:"attributes,json"`
+	Status         advert.Status           `db:"status"`
+	// ModerationReason is NULL unless the advert was rejected or suspended
+	ModerationReason *string `db:"moderation_reason"`
+	// TranslationAttempts counts failed machine translations, see advert.MaxTranslationAttempts
+	TranslationAttempts int `db:"translation_attempts"`
+	// Version is incremented by every change of the columns Update writes, see advert.Advert.Version
+	Version int `db:"version"`
+	LocationDB
+}
+
+func newModerationReasonDB(reason string) *string {
+	if reason == "" {
+		return nil
+	}
+	return &reason
+}
+
+func (a AdvertDB) moderationReason() string {
+	if a.ModerationReason == nil {
+		return ""
+	}
+	return *a.ModerationReason
+}
+
+// LocationDB keeps the location in separate nullable columns, so adverts can be filtered by city, region and distance
+type LocationDB struct {
+	City      *string  `db:"city"`
+	Region    *string  `db:"region"`
+	Country   *string  `db:"country"`
+	Latitude  *float64 `db:"latitude"`
+	Longitude *float64 `db:"longitude"`
+}
+
+func newLocationDB(location *domain.Location) LocationDB {
+	if location == nil {
+		return LocationDB{}
+	}
+
+	locationDB := LocationDB{
+		City:    newStringPtr(location.City),
+		Region:  newStringPtr(location.Region),
+		Country: newStringPtr(location.Country),
+	}
+	if location.Coordinates != nil {
+		locationDB.Latitude = &location.Coordinates.Latitude
+		locationDB.Longitude = &location.Coordinates.Longitude
+	}
+	return locationDB
+}
+
+func (l LocationDB) Location() *domain.Location {
+	if l.Country == nil {
+		return nil
+	}
+
+	location := &domain.Location{Country: *l.Country}
+	if l.City != nil {
+		location.City = *l.City
+	}
+	if l.Region != nil {
+		location.Region = *l.Region
+	}
+	if l.Latitude != nil && l.Longitude != nil {
+		location.Coordinates = &domain.Coordinates{Latitude: *l.Latitude, Longitude: *l.Longitude}
+	}
+	return location
 }
 
 type AdvertDetailsDB struct {
@@ -46,35 +121,81 @@ type AdvertDetailsDB struct {
 	Language    LanguageTag `db:"language"` // ISO 639-1
 	Title       string      `db:"title"`
 	Description string      `db:"description"`
+	// MachineTranslated is set for the translations filled by the translation job
+	MachineTranslated bool `db:"machine_translated"`
 }
 
-type advertTranslations struct {
-	Title       MultilingualString
-	Description MultilingualString
+type ImageDB struct {
+	ID          uuid.UUID `db:"id"`
+	AdvertID    uuid.UUID `db:"advert_id"`
+	ContentType string    `db:"content_type"`
+	Width       int       `db:"width"`
+	Height      int       `db:"height"`
+	CreatedAt   time.Time `db:"created_at"`
 }
 
-func (tr *advertTranslations) Filter(langs []LanguageTag) {
-	newTitle := make(MultilingualString)
-	newDesc := make(MultilingualString)
-	for _, expectedLang := range langs {
-		t, ok := tr.Title[expectedLang]
-		if ok {
-			newTitle[expectedLang] = t
-		}
+func newImageDB(img advert.Image) ImageDB {
+	return ImageDB{
+		ID:          img.ID,
+		AdvertID:    img.AdvertID,
+		ContentType: img.ContentType,
+		Width:       img.Width,
+		Height:      img.Height,
+		CreatedAt:   img.CreatedAt,
+	}
+}
+
+func (i ImageDB) Image() advert.Image {
+	return advert.Image{
+		ID:          i.ID,
+		AdvertID:    i.AdvertID,
+		ContentType: i.ContentType,
+		Width:       i.Width,
+		Height:      i.Height,
+		CreatedAt:   i.CreatedAt,
+	}
+}
+
+// getAdvertsImages loads images of all the adverts at once, they are ordered in the same way as they were attached
+func (repo PostgresAdvertRepository) getAdvertsImages(sqlExec gorp.SqlExecutor, ids []string) (map[uuid.UUID][]advert.Image, error) {
+	images := make(map[uuid.UUID][]advert.Image, len(ids))
+	if len(ids) == 0 {
+		return images, nil
+	}
+
+	var imagesDB []ImageDB
+	_, err := sqlExec.Select(&imagesDB, "SELECT * FROM advert_images WHERE advert_id = ANY($1::varchar[]) ORDER BY created_at, id", pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed getting adverts images: %w", err)
+	}
+
+	for _, imgDB := range imagesDB {
+		images[imgDB.AdvertID] = append(images[imgDB.AdvertID], imgDB.Image())
+	}
+	return images, nil
+}
 
-		d, ok := tr.Description[expectedLang]
-		if ok {
-			newDesc[expectedLang] = d
+func insertAdvertImages(sqlExecutor gorp.SqlExecutor, adv *advert.Advert) error {
+	for _, img := range adv.Images {
+		imageDB := newImageDB(img)
+		err := sqlExecutor.Insert(&imageDB)
+		if err != nil {
+			return fmt.Errorf("failed inserting advert image: %w", err)
 		}
 	}
-	tr.Title = newTitle
-	tr.Description = newDesc
+	return nil
+}
+
+type advertTranslations struct {
+	Title             MultilingualString
+	Description       MultilingualString
+	MachineTranslated LanguageTags
 }
 
 func (repo PostgresAdvertRepository) getAdvertTranslations(ctx context.Context, advertID uuid.UUID) (advertTranslations, error) {
 	sqlExec := repo.db.WithContext(ctx)
 	var advDetailsDB []AdvertDetailsDB
-	_, err := sqlExec.Select(&advDetailsDB, "SELECT * FROM adverts_details WHERE advert_id=$1", advertID.String())
+	_, err := sqlExec.Select(&advDetailsDB, "SELECT * FROM adverts_details WHERE advert_id=$1 ORDER BY language", advertID.String())
 	if err != nil {
 		return advertTranslations{}, fmt.Errorf("failed getting advert translations: %w", err)
 	}
@@ -87,6 +208,9 @@ func (repo PostgresAdvertRepository) getAdvertTranslations(ctx context.Context,
 	for _, val := range advDetailsDB {
 		translation.Title[val.Language] = val.Title
 		translation.Description[val.Language] = val.Description
+		if val.MachineTranslated {
+			translation.MachineTranslated = append(translation.MachineTranslated, val.Language)
+		}
 	}
 
 	translation.Title.RemoveUnsupported()
@@ -94,33 +218,78 @@ func (repo PostgresAdvertRepository) getAdvertTranslations(ctx context.Context,
 	return translation, nil
 }
 
+// getAdvertsTranslations loads translations of all the adverts at once, only the given languages are selected
+func (repo PostgresAdvertRepository) getAdvertsTranslations(sqlExec gorp.SqlExecutor, advertsDB []AdvertDB, languages []string) (map[uuid.UUID]advertTranslations, error) {
+	translations := make(map[uuid.UUID]advertTranslations, len(advertsDB))
+	if len(advertsDB) == 0 {
+		return translations, nil
+	}
+
+	var ids []string
+	for _, advDB := range advertsDB {
+		ids = append(ids, advDB.ID.String())
+		translations[advDB.ID] = advertTranslations{
+			Title:       make(MultilingualString),
+			Description: make(MultilingualString),
+		}
+	}
+
+	var advDetailsDB []AdvertDetailsDB
+	_, err := sqlExec.Select(&advDetailsDB, "SELECT * FROM adverts_details WHERE advert_id = ANY($1::varchar[]) AND language = ANY($2::varchar[]) ORDER BY language",
+		pq.Array(ids), pq.Array(languages))
+	if err != nil {
+		return nil, fmt.Errorf("failed getting adverts translations: %w", err)
+	}
+
+	for _, val := range advDetailsDB {
+		translation := translations[val.AdvertID]
+		translation.Title[val.Language] = val.Title
+		translation.Description[val.Language] = val.Description
+		if val.MachineTranslated {
+			translation.MachineTranslated = append(translation.MachineTranslated, val.Language)
+		}
+		translations[val.AdvertID] = translation
+	}
+	return translations, nil
+}
+
 func (repo PostgresAdvertRepository) Get(ctx context.Context, id uuid.UUID) (advert.Advert, error) {
 	sqlExec := repo.db.WithContext(ctx)
 
 	type advertAndUserDB struct {
 		AdvertDB
-		ID          uuid.UUID `db:"id"`
-		Login       string    `db:"login"`
-		Password    *string   `db:"password"`
-		FirstName   string    `db:"name"`
-		Surname     string    `db:"surname"`
-		Mail        *string   `db:"mail"`
-		PhoneNumber *string   `db:"phone_number"`
+		Login       string  `db:"login"`
+		FirstName   string  `db:"name"`
+		Surname     string  `db:"surname"`
+		Mail        *string `db:"mail"`
+		PhoneNumber *string `db:"phone_number"`
 	}
 
 	adv := advertAndUserDB{}
 
-	err := sqlExec.SelectOne(&adv, "SELECT * FROM adverts JOIN users ON (adverts.user_id = users.id) WHERE adverts.id=$1;", id.String())
+	// password is intentionally not selected, the author is only needed for presentation and ownership checks
+	err := sqlExec.SelectOne(&adv, `
+	SELECT adverts.id, adverts.user_id, adverts.type, adverts.views, adverts.contact_reveals, adverts.contact_details,
+	       adverts.created_at, adverts.updated_at, adverts.destroyed_at, adverts.expires_at, adverts.archived_at, adverts.translated_at,
+	       adverts.city, adverts.region, adverts.country, adverts.latitude, adverts.longitude, adverts.attributes,
+	       adverts.status, adverts.moderation_reason, adverts.version,
+	       users.login, users.name, users.surname, users.mail, users.phone_number
+	FROM adverts JOIN users ON (adverts.user_id = users.id) WHERE adverts.id=$1 AND adverts.destroyed_at IS NULL;`, id.String())
 	if err != nil {
 		return advert.Advert{}, fmt.Errorf("getting advert failed while selecting from db %w", err)
 	}
 
-	usr, err := user.NewUser(adv.FirstName, adv.Surname, adv.Login, *adv.Password, domain.ContactDetails{
-		Mail:        adv.Mail,
-		PhoneNumber: adv.PhoneNumber,
-	})
-	if err != nil {
-		return advert.Advert{}, fmt.Errorf("getting advert failed while performing NewUser() %w", err)
+	usr := &user.User{
+		ID:    adv.UserID,
+		Login: adv.Login,
+		Person: domain.Person{
+			FirstName: adv.FirstName,
+			Surname:   adv.Surname,
+		},
+		ContactDetails: domain.ContactDetails{
+			Mail:        adv.Mail,
+			PhoneNumber: adv.PhoneNumber,
+		},
 	}
 
 	translation, err := repo.getAdvertTranslations(ctx, adv.ID)
@@ -128,19 +297,35 @@ func (repo PostgresAdvertRepository) Get(ctx context.Context, id uuid.UUID) (adv
 		return advert.Advert{}, err
 	}
 
+	images, err := repo.getAdvertsImages(sqlExec, []string{adv.ID.String()})
+	if err != nil {
+		return advert.Advert{}, err
+	}
+
 	return advert.Advert{
 		ID: adv.ID,
 		Details: domain.AdvertDetails{
-			Title:          translation.Title,
-			Description:    translation.Description,
-			Type:           adv.Type,
-			Views:          adv.Views,
-			ContactDetails: adv.ContactDetails,
+			Title:             translation.Title,
+			Description:       translation.Description,
+			Type:              adv.Type,
+			Views:             adv.Views,
+			ContactDetails:    adv.ContactDetails,
+			Location:          adv.Location(),
+			Attributes:        adv.Attributes,
+			ContactReveals:    adv.ContactReveals,
+			MachineTranslated: translation.MachineTranslated,
 		},
-		User:        usr,
-		CreatedAt:   adv.CreatedAt,
-		UpdatedAt:   adv.UpdatedAt,
-		DestroyedAt: adv.DestroyedAt,
+		User:             usr,
+		CreatedAt:        adv.CreatedAt,
+		UpdatedAt:        adv.UpdatedAt,
+		DestroyedAt:      adv.DestroyedAt,
+		ExpiresAt:        adv.ExpiresAt,
+		ArchivedAt:       adv.ArchivedAt,
+		Status:           adv.Status,
+		ModerationReason: adv.moderationReason(),
+		Images:           images[adv.ID],
+		TranslatedAt:     adv.TranslatedAt,
+		Version:          adv.Version,
 	}, nil
 }
 
@@ -149,23 +334,60 @@ func (repo PostgresAdvertRepository) Add(ctx context.Context, advert *advert.Adv
 	if err != nil {
 		return fmt.Errorf("failed creating transaction for adding advert to repo: %w", err)
 	}
-	sqlExecutor := trans.WithContext(ctx)
 
+	err = repo.add(trans.WithContext(ctx), advert)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing added advert: %w", err)
+	}
+	advert.Logs = nil
+	return nil
+}
+
+func (repo PostgresAdvertRepository) add(sqlExecutor gorp.SqlExecutor, advert *advert.Advert) error {
 	advertDb := AdvertDB{
-		ID:             advert.ID,
-		UserID:         advert.User.ID,
-		Type:           advert.Details.Type,
-		ContactDetails: advert.Details.ContactDetails,
-		CreatedAt:      advert.CreatedAt,
-		DestroyedAt:    advert.DestroyedAt,
-		UpdatedAt:      advert.UpdatedAt,
+		ID:               advert.ID,
+		UserID:           advert.User.ID,
+		Type:             advert.Details.Type,
+		Views:            advert.Details.Views,
+		ContactReveals:   advert.Details.ContactReveals,
+		ContactDetails:   advert.Details.ContactDetails,
+		CreatedAt:        advert.CreatedAt,
+		DestroyedAt:      advert.DestroyedAt,
+		UpdatedAt:        advert.UpdatedAt,
+		ExpiresAt:        advert.ExpiresAt,
+		ArchivedAt:       advert.ArchivedAt,
+		TranslatedAt:     advert.TranslatedAt,
+		Attributes:       advert.Details.Attributes,
+		Status:           advert.Status,
+		ModerationReason: newModerationReasonDB(advert.ModerationReason),
+		LocationDB:       newLocationDB(advert.Details.Location),
 	}
 
-	err = sqlExecutor.Insert(&advertDb)
+	err := sqlExecutor.Insert(&advertDb)
 	if err != nil {
 		return fmt.Errorf("adding advert failed while performing sql %w", err)
 	}
 
+	err = insertAdvertDetails(sqlExecutor, advert)
+	if err != nil {
+		return err
+	}
+
+	err = insertAdvertImages(sqlExecutor, advert)
+	if err != nil {
+		return err
+	}
+
+	return insertAdvertLogs(sqlExecutor, advert.Logs)
+}
+
+func insertAdvertDetails(sqlExecutor gorp.SqlExecutor, advert *advert.Advert) error {
 	for lang, title := range advert.Details.Title {
 		description, ok := advert.Details.Description[lang]
 		if !ok {
@@ -173,45 +395,266 @@ func (repo PostgresAdvertRepository) Add(ctx context.Context, advert *advert.Adv
 		}
 
 		advertDetailsDB := AdvertDetailsDB{
-			ID:          uuid.New(),
-			AdvertID:    advert.ID,
-			Language:    lang,
-			Title:       title,
-			Description: description,
+			ID:                uuid.New(),
+			AdvertID:          advert.ID,
+			Language:          lang,
+			Title:             title,
+			Description:       description,
+			MachineTranslated: advert.Details.MachineTranslated.Contains(lang),
 		}
-		err = sqlExecutor.Insert(&advertDetailsDB)
+		err := sqlExecutor.Insert(&advertDetailsDB)
 		if err != nil {
 			return fmt.Errorf("failed inserting advertDetails: %w", err)
 		}
 	}
+	return nil
+}
+
+// Update overwrites the advert row together with all of its translations within a single transaction.
+// Views, contact reveals, creation date and images are left untouched, images are stored by AddImage and RemoveImage. The advert is overwritten only if it wasn't changed
+// since it was loaded, otherwise advert.AdvertChangedErr is returned and the caller has to load it again.
+func (repo PostgresAdvertRepository) Update(ctx context.Context, advert *advert.Advert) error {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for updating advert: %w", err)
+	}
+
+	err = repo.update(trans.WithContext(ctx), advert)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing updated advert: %w", err)
+	}
+	advert.Logs = nil
+	advert.Version++
+	return nil
+}
+
+func (repo PostgresAdvertRepository) update(sqlExecutor gorp.SqlExecutor, adv *advert.Advert) error {
+	contactDetails, err := json.Marshal(adv.Details.ContactDetails)
+	if err != nil {
+		return fmt.Errorf("failed marshaling contact details: %w", err)
+	}
+
+	attributes, err := json.Marshal(adv.Details.Attributes)
+	if err != nil {
+		return fmt.Errorf("failed marshaling attributes: %w", err)
+	}
+
+	location := newLocationDB(adv.Details.Location)
+	result, err := sqlExecutor.Exec(`
+	UPDATE adverts SET type=$1, contact_details=$2, updated_at=$3, attributes=$4,
+	                   city=$5, region=$6, country=$7, latitude=$8, longitude=$9,
+	                   expires_at=$10, archived_at=$11, status=$12, moderation_reason=$13, translated_at=$14, version=version+1
+	WHERE id=$15 AND destroyed_at IS NULL AND version=$16`, adv.Details.Type, string(contactDetails), adv.UpdatedAt, string(attributes),
+		location.City, location.Region, location.Country, location.Latitude, location.Longitude,
+		adv.ExpiresAt, adv.ArchivedAt, adv.Status, newModerationReasonDB(adv.ModerationReason), adv.TranslatedAt, adv.ID.String(), adv.Version)
+	if err != nil {
+		return fmt.Errorf("updating advert failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("updating advert failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return repo.updateConflict(sqlExecutor, adv.ID)
+	}
+
+	_, err = sqlExecutor.Exec("DELETE FROM adverts_details WHERE advert_id=$1", adv.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed removing old advertDetails: %w", err)
+	}
+
+	err = insertAdvertDetails(sqlExecutor, adv)
+	if err != nil {
+		return err
+	}
+
+	return insertAdvertLogs(sqlExecutor, adv.Logs)
+}
+
+// AddImage stores the image of the advert, concurrent uploads are serialized by locking the advert row,
+// so the advert never ends up with more than advert.MaxImages images.
+func (repo PostgresAdvertRepository) AddImage(ctx context.Context, adv *advert.Advert, img advert.Image) error {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for adding advert image: %w", err)
+	}
+
+	err = repo.addImage(trans.WithContext(ctx), adv, img)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing added advert image: %w", err)
+	}
+	adv.Logs = nil
+	return nil
+}
+
+func (repo PostgresAdvertRepository) addImage(sqlExecutor gorp.SqlExecutor, adv *advert.Advert, img advert.Image) error {
+	var locked []string
+	_, err := sqlExecutor.Select(&locked, "SELECT id FROM adverts WHERE id=$1 AND destroyed_at IS NULL FOR UPDATE", adv.ID.String())
+	if err != nil {
+		return fmt.Errorf("adding advert image failed while locking the advert %w", err)
+	}
+	if len(locked) == 0 {
+		return advert.AdvertNotFound
+	}
+
+	images, err := sqlExecutor.SelectInt("SELECT count(*) FROM advert_images WHERE advert_id=$1", adv.ID.String())
+	if err != nil {
+		return fmt.Errorf("adding advert image failed while counting images %w", err)
+	}
+	if images >= advert.MaxImages {
+		return advert.TooManyImagesErr
+	}
+
+	imageDB := newImageDB(img)
+	err = sqlExecutor.Insert(&imageDB)
+	if err != nil {
+		return fmt.Errorf("failed inserting advert image: %w", err)
+	}
+
+	if adv.Status == advert.StatusPending {
+		// the image waits for the review, also when the advert has been approved since it was loaded
+		_, err = sqlExecutor.Exec("UPDATE adverts SET status=$1, moderation_reason='', version=version+1 WHERE id=$2 AND status=$3",
+			string(advert.StatusPending), adv.ID.String(), string(advert.StatusPublished))
+		if err != nil {
+			return fmt.Errorf("adding advert image failed while sending the advert to review %w", err)
+		}
+	}
+
+	return insertAdvertLogs(sqlExecutor, adv.Logs)
+}
+
+// RemoveImage removes the image of the advert, the files in the blob storage are removed by the caller
+func (repo PostgresAdvertRepository) RemoveImage(ctx context.Context, adv *advert.Advert, img advert.Image) error {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for removing advert image: %w", err)
+	}
+
+	err = repo.removeImage(trans.WithContext(ctx), adv, img)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing removed advert image: %w", err)
+	}
+	adv.Logs = nil
+	return nil
+}
+
+func (repo PostgresAdvertRepository) removeImage(sqlExecutor gorp.SqlExecutor, adv *advert.Advert, img advert.Image) error {
+	result, err := sqlExecutor.Exec("DELETE FROM advert_images WHERE id=$1 AND advert_id=$2", img.ID.String(), adv.ID.String())
+	if err != nil {
+		return fmt.Errorf("removing advert image failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("removing advert image failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return advert.ImageNotFound
+	}
+
+	return insertAdvertLogs(sqlExecutor, adv.Logs)
+}
+
+// updateConflict tells why no advert was updated, either it doesn't exist anymore or its version has changed
+func (repo PostgresAdvertRepository) updateConflict(sqlExecutor gorp.SqlExecutor, id uuid.UUID) error {
+	exists, err := sqlExecutor.SelectInt("SELECT count(*) FROM adverts WHERE id=$1 AND destroyed_at IS NULL", id.String())
+	if err != nil {
+		return fmt.Errorf("updating advert failed while checking its existence %w", err)
+	}
+	if exists == 0 {
+		return advert.AdvertNotFound
+	}
+	return advert.AdvertChangedErr
+}
+
+// search performs full-text search over the translations, only the translations in requested languages are matched.
+// Text search configuration of every translation is chosen by advert_search_config() defined in sql/create_tables.sql
+func (repo PostgresAdvertRepository) search(sqlExec gorp.SqlExecutor, filter advert.ListFilter) ([]AdvertDB, error) {
+	args := queryArgs{}
+	query := args.add(filter.Query)
+	languagesArg := args.add(pq.Array(listLanguages(filter)))
+	conditions := listConditions(filter, &args)
 
-	return trans.Commit()
+	var advertsDB []AdvertDB
+	_, err := sqlExec.Select(&advertsDB, fmt.Sprintf(`
+	SELECT adverts.* FROM adverts
+	JOIN (
+		SELECT advert_id, max(ts_rank(
+			advert_search_vector(language, title, description),
+			plainto_tsquery(advert_search_config(language), %[1]s)
+		)) AS rank
+		FROM adverts_details
+		WHERE advert_search_vector(language, title, description) @@ plainto_tsquery(advert_search_config(language), %[1]s)
+		  AND language = ANY(%[2]s::varchar[])
+		GROUP BY advert_id
+	) matches ON (matches.advert_id = adverts.id)
+	WHERE %[3]s
+	ORDER BY matches.rank DESC, adverts.created_at DESC, adverts.id DESC
+	LIMIT %[4]s OFFSET %[5]s`, query, languagesArg, conditions, args.add(filter.Limit), args.add(filter.Offset)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed searching adverts: %w", err)
+	}
+	return advertsDB, nil
 }
 
-func (repo PostgresAdvertRepository) GetList(ctx context.Context, langs LanguageTags, limit int, offset int) ([]*advert.Advert, error) {
+func (repo PostgresAdvertRepository) GetList(ctx context.Context, filter advert.ListFilter) ([]*advert.Advert, error) {
 	sqlExec := repo.db.WithContext(ctx)
 
 	var advertsDB []AdvertDB
-	_, err := sqlExec.Select(&advertsDB, "SELECT * FROM adverts LIMIT $1 OFFSET $2", limit, offset)
+	var err error
+	if filter.Query == "" {
+		args := queryArgs{}
+		conditions := listConditions(filter, &args)
+		_, err = sqlExec.Select(&advertsDB, fmt.Sprintf("SELECT * FROM adverts WHERE %s ORDER BY created_at DESC, id DESC LIMIT %s OFFSET %s",
+			conditions, args.add(filter.Limit), args.add(filter.Offset)), args...)
+	} else {
+		advertsDB, err = repo.search(sqlExec, filter)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed selecting many adverts with translations: %w", err)
 	}
 
-	var adverts []*advert.Advert
-	for _, advDB := range advertsDB {
-		translation, err := repo.getAdvertTranslations(ctx, advDB.ID)
-		if err != nil {
-			return nil, err
-		}
+	return repo.loadAdverts(sqlExec, advertsDB, listLanguages(filter))
+}
 
-		// don't filter if there are no langs selected
-		if !langs.Empty() {
-			translation.Filter(langs)
-		}
+// loadAdverts completes the selected adverts with their translations in the given languages and images
+func (repo PostgresAdvertRepository) loadAdverts(sqlExec gorp.SqlExecutor, advertsDB []AdvertDB, languages []string) ([]*advert.Advert, error) {
+	translations, err := repo.getAdvertsTranslations(sqlExec, advertsDB, languages)
+	if err != nil {
+		return nil, err
+	}
 
-		if translation.Title.Empty() || translation.Description.Empty() {
-			continue
-		}
+	var ids []string
+	for _, advDB := range advertsDB {
+		ids = append(ids, advDB.ID.String())
+	}
+	images, err := repo.getAdvertsImages(sqlExec, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var adverts []*advert.Advert
+	for _, advDB := range advertsDB {
+		translation := translations[advDB.ID]
 
 		adverts = append(adverts, &advert.Advert{
 			ID: advDB.ID,
@@ -224,17 +667,237 @@ func (repo PostgresAdvertRepository) GetList(ctx context.Context, langs Language
 					Mail:        advDB.ContactDetails.Mail,
 					PhoneNumber: advDB.ContactDetails.PhoneNumber,
 				},
+				Location:          advDB.Location(),
+				Attributes:        advDB.Attributes,
+				ContactReveals:    advDB.ContactReveals,
+				MachineTranslated: translation.MachineTranslated,
 			},
-			User:        &user.User{ID: advDB.UserID},
-			CreatedAt:   advDB.CreatedAt,
-			UpdatedAt:   advDB.UpdatedAt,
-			DestroyedAt: advDB.DestroyedAt,
+			User:             &user.User{ID: advDB.UserID},
+			CreatedAt:        advDB.CreatedAt,
+			UpdatedAt:        advDB.UpdatedAt,
+			DestroyedAt:      advDB.DestroyedAt,
+			ExpiresAt:        advDB.ExpiresAt,
+			ArchivedAt:       advDB.ArchivedAt,
+			Status:           advDB.Status,
+			ModerationReason: advDB.moderationReason(),
+			Images:           images[advDB.ID],
+			TranslatedAt:     advDB.TranslatedAt,
+			Version:          advDB.Version,
 		})
 	}
 
 	return adverts, nil
 }
 
-func (repo PostgresAdvertRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	panic("implement me")
+// Delete performs soft deletion of the advert, from now on it won't be returned by Get and GetList.
+func (repo PostgresAdvertRepository) Delete(ctx context.Context, advert *advert.Advert) error {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for deleting advert: %w", err)
+	}
+
+	err = repo.delete(trans.WithContext(ctx), advert)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing deleted advert: %w", err)
+	}
+	advert.Logs = nil
+	return nil
+}
+
+func (repo PostgresAdvertRepository) delete(sqlExecutor gorp.SqlExecutor, adv *advert.Advert) error {
+	destroyedAt := time.Now()
+	if adv.DestroyedAt != nil {
+		destroyedAt = *adv.DestroyedAt
+	}
+
+	result, err := sqlExecutor.Exec("UPDATE adverts SET destroyed_at=$1 WHERE id=$2 AND destroyed_at IS NULL", destroyedAt, adv.ID.String())
+	if err != nil {
+		return fmt.Errorf("deleting advert failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("deleting advert failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return advert.AdvertNotFound
+	}
+
+	return insertAdvertLogs(sqlExecutor, adv.Logs)
+}
+
+// IncrementViews bumps the counter within a single statement, so concurrent views are never lost
+func (repo PostgresAdvertRepository) IncrementViews(ctx context.Context, id uuid.UUID) error {
+	sqlExecutor := repo.db.WithContext(ctx)
+	result, err := sqlExecutor.Exec("UPDATE adverts SET views=COALESCE(views, 0)+1 WHERE id=$1 AND destroyed_at IS NULL", id.String())
+	if err != nil {
+		return fmt.Errorf("incrementing advert views failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("incrementing advert views failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return advert.AdvertNotFound
+	}
+	return nil
+}
+
+// IncrementContactReveals bumps the counter the same way as IncrementViews
+func (repo PostgresAdvertRepository) IncrementContactReveals(ctx context.Context, id uuid.UUID) error {
+	sqlExecutor := repo.db.WithContext(ctx)
+	result, err := sqlExecutor.Exec("UPDATE adverts SET contact_reveals=contact_reveals+1 WHERE id=$1 AND destroyed_at IS NULL", id.String())
+	if err != nil {
+		return fmt.Errorf("incrementing advert contact reveals failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("incrementing advert contact reveals failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return advert.AdvertNotFound
+	}
+	return nil
+}
+
+// GetUntranslated returns adverts waiting for machine translation from the oldest one, archived adverts are skipped.
+// Adverts which failed to be translated go last, so they can't fill the whole batch.
+func (repo PostgresAdvertRepository) GetUntranslated(ctx context.Context, limit int) ([]*advert.Advert, error) {
+	sqlExec := repo.db.WithContext(ctx)
+
+	var advertsDB []AdvertDB
+	_, err := sqlExec.Select(&advertsDB, `
+	SELECT * FROM adverts WHERE translated_at IS NULL AND destroyed_at IS NULL AND archived_at IS NULL
+	ORDER BY translation_attempts, created_at, id LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting untranslated adverts: %w", err)
+	}
+
+	return repo.loadAdverts(sqlExec, advertsDB, SupportedLanguages().Strings())
+}
+
+// FailTranslation bumps the translation attempts of the advert, the advert is marked as translated once it reaches maxAttempts
+func (repo PostgresAdvertRepository) FailTranslation(ctx context.Context, id uuid.UUID, maxAttempts int) error {
+	sqlExecutor := repo.db.WithContext(ctx)
+	_, err := sqlExecutor.Exec(`
+	UPDATE adverts SET translation_attempts=translation_attempts+1,
+	                   translated_at=CASE WHEN translation_attempts+1 >= $2 THEN now() ELSE translated_at END,
+	                   version=CASE WHEN translation_attempts+1 >= $2 THEN version+1 ELSE version END
+	WHERE id=$1 AND translated_at IS NULL`, id.String(), maxAttempts)
+	if err != nil {
+		return fmt.Errorf("recording failed translation failed while performing sql %w", err)
+	}
+	return nil
+}
+
+// SaveTranslations stores machine translations of the advert and marks it as translated.
+// The translations are dropped with advert.TranslationOutdatedErr when the advert has been changed since it was loaded.
+func (repo PostgresAdvertRepository) SaveTranslations(ctx context.Context, adv *advert.Advert) error {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for saving translations: %w", err)
+	}
+
+	err = repo.saveTranslations(trans.WithContext(ctx), adv)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing saved translations: %w", err)
+	}
+	return nil
+}
+
+func (repo PostgresAdvertRepository) saveTranslations(sqlExecutor gorp.SqlExecutor, adv *advert.Advert) error {
+	result, err := sqlExecutor.Exec(`
+	UPDATE adverts SET translated_at=$1, version=version+1
+	WHERE id=$2 AND translated_at IS NULL AND destroyed_at IS NULL AND updated_at IS NOT DISTINCT FROM $3`,
+		adv.TranslatedAt, adv.ID.String(), adv.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("marking advert as translated failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("marking advert as translated failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return advert.TranslationOutdatedErr
+	}
+
+	_, err = sqlExecutor.Exec("DELETE FROM adverts_details WHERE advert_id=$1 AND machine_translated", adv.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed removing old machine translations: %w", err)
+	}
+
+	for _, lang := range adv.Details.MachineTranslated {
+		advertDetailsDB := AdvertDetailsDB{
+			ID:                uuid.New(),
+			AdvertID:          adv.ID,
+			Language:          lang,
+			Title:             adv.Details.Title[lang],
+			Description:       adv.Details.Description[lang],
+			MachineTranslated: true,
+		}
+		err := sqlExecutor.Insert(&advertDetailsDB)
+		if err != nil {
+			return fmt.Errorf("failed inserting machine translation: %w", err)
+		}
+	}
+	return nil
+}
+
+// ArchiveExpired archives all the adverts which expired before now and returns their ids
+func (repo PostgresAdvertRepository) ArchiveExpired(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed creating transaction for archiving adverts: %w", err)
+	}
+
+	ids, err := repo.archiveExpired(trans.WithContext(ctx), now)
+	if err != nil {
+		_ = trans.Rollback()
+		return nil, err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed committing archived adverts: %w", err)
+	}
+	return ids, nil
+}
+
+func (repo PostgresAdvertRepository) archiveExpired(sqlExecutor gorp.SqlExecutor, now time.Time) ([]uuid.UUID, error) {
+	var archivedIDs []string
+	_, err := sqlExecutor.Select(&archivedIDs, `
+	UPDATE adverts SET archived_at=$1, version=version+1
+	WHERE archived_at IS NULL AND destroyed_at IS NULL AND expires_at <= $2
+	RETURNING id`, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("archiving adverts failed while performing sql %w", err)
+	}
+
+	var ids []uuid.UUID
+	var logs []advert.AdvertLog
+	for _, archivedID := range archivedIDs {
+		id, err := uuid.Parse(archivedID)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing archived advert id: %w", err)
+		}
+		ids = append(ids, id)
+		logs = append(logs, advert.NewArchivedLog(id, now))
+	}
+
+	return ids, insertAdvertLogs(sqlExecutor, logs)
 }
@@ -2,6 +2,8 @@ package advert
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,10 +15,20 @@ import (
 	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
 	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
 	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 )
 
+func newTimePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func newUUIDPtr(id uuid.UUID) *uuid.UUID {
+	return &id
+}
+
 func getContactDetails() domain.ContactDetails {
 	return domain.ContactDetails{
 		Mail:        newStringPtr("foo@gmail.com"),
@@ -172,8 +184,12 @@ func TestAdvertPostgresGet(t *testing.T) {
 				defer tC.cleanUp(t, tC.input)
 			}
 
-			_, err := repo.Get(context.Background(), tC.input.advertDB.ID)
+			adv, err := repo.Get(context.Background(), tC.input.advertDB.ID)
 			assert.Equal(t, tC.expectedErr, err)
+			if tC.expectedErr == nil {
+				assert.Equal(t, tC.input.userDB.ID, adv.User.ID)
+				assert.Nil(t, adv.User.Password)
+			}
 
 		})
 	}
@@ -194,12 +210,6 @@ func TestAdvertPostgresGetList(t *testing.T) {
 		advertDetailsDB []AdvertDetailsDB
 	}
 
-	type input struct {
-		langs  []LanguageTag
-		limit  int
-		offset int
-	}
-
 	type expected struct {
 		err        error
 		advertsLen int
@@ -208,12 +218,56 @@ func TestAdvertPostgresGetList(t *testing.T) {
 	type testCase struct {
 		name     string
 		dbInput  dbInput
-		input    input
+		input    advert.ListFilter
 		pre      func(t *testing.T, input dbInput)
 		cleanUp  func(t *testing.T, input dbInput)
 		expected expected
 	}
+	insertInput := func(t *testing.T, input dbInput) {
+		err := db.Insert(&input.userDB)
+		assert.NoError(t, err)
+
+		for _, advDB := range input.advertDB {
+			err := db.Insert(&advDB)
+			assert.NoError(t, err)
+		}
+
+		for _, detailsDb := range input.advertDetailsDB {
+			err := db.Insert(&detailsDb)
+			assert.NoError(t, err)
+		}
+	}
+	removeInput := func(t *testing.T, input dbInput) {
+		for _, advertDB := range input.advertDB {
+			_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+			// advert_details should be removed due to fk policy
+			assert.NoError(t, err)
+		}
+
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", input.userDB.ID)
+		assert.NoError(t, err)
+	}
+
 	uuid_ := internal.HumanFriendlyUUID
+	jobAdvert := GenerateTestAdvertDB(uuid_("job_advert"), uuid_("type_user"))
+	jobAdvert.Type = domain.AdvertTypeJob
+	oldAdvert := GenerateTestAdvertDB(uuid_("old_advert"), uuid_("date_user"))
+	oldAdvert.CreatedAt = time.Now().Add(-48 * time.Hour)
+	destroyedAdvert := GenerateTestAdvertDB(uuid_("destroyed_advert"), uuid_("destroyed_user"))
+	destroyedAdvert.DestroyedAt = newTimePtr(time.Now())
+	pendingAdvert := GenerateTestAdvertDB(uuid_("pending_advert"), uuid_("pending_user"))
+	pendingAdvert.Status = advert.StatusPending
+	lvivAdvert := GenerateTestAdvertDB(uuid_("lviv_advert"), uuid_("location_user"))
+	lvivAdvert.LocationDB = newLocationDB(&domain.Location{
+		City: "Lviv", Country: "Ukraine", Coordinates: &domain.Coordinates{Latitude: 49.84, Longitude: 24.03},
+	})
+	kyivAdvert := GenerateTestAdvertDB(uuid_("kyiv_advert"), uuid_("location_user"))
+	kyivAdvert.LocationDB = newLocationDB(&domain.Location{
+		City: "Kyiv", Country: "Ukraine", Coordinates: &domain.Coordinates{Latitude: 50.45, Longitude: 30.52},
+	})
+	olderUkrainianAdvert := GenerateTestAdvertDB(uuid_("ukrainian_advert"), uuid_("lang_user"))
+	olderUkrainianAdvert.CreatedAt = time.Now().Add(-time.Hour)
+
 	testCases := []testCase{
 		{
 			name: "success",
@@ -231,39 +285,180 @@ func TestAdvertPostgresGetList(t *testing.T) {
 					GenerateTestAdvertDetailsDB(uuid_("second_advert"), English),
 				},
 			},
-			input: input{
-				langs: []LanguageTag{English},
-				limit: 10,
+			input: advert.ListFilter{
+				Languages: []LanguageTag{English},
+				Limit:     10,
 			},
-			pre: func(t *testing.T, input dbInput) {
-				err := db.Insert(&input.userDB)
-				assert.NoError(t, err)
-
-				for _, advDB := range input.advertDB {
-					err := db.Insert(&advDB)
-					assert.NoError(t, err)
-				}
-
-				for _, detailsDb := range input.advertDetailsDB {
-					err := db.Insert(&detailsDb)
-					assert.NoError(t, err)
-				}
+			pre:     insertInput,
+			cleanUp: removeInput,
+			expected: expected{
+				err:        nil,
+				advertsLen: 2,
 			},
-			cleanUp: func(t *testing.T, input dbInput) {
-				for _, advertDB := range input.advertDB {
-					_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
-					// advert_details should be removed due to fk policy
-					assert.NoError(t, err)
-				}
-
-				_, err = db.Exec("DELETE FROM users WHERE id=$1", input.userDB.ID)
-				assert.NoError(t, err)
+		},
+		{
+			name: "filter by type",
+			dbInput: dbInput{
+				userDB: internalUser.GenerateTestUserDB(uuid_("type_user")),
+				advertDB: []AdvertDB{
+					jobAdvert,
+					GenerateTestAdvertDB(uuid_("transport_advert"), uuid_("type_user")),
+				},
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("job_advert"), English),
+					GenerateTestAdvertDetailsDB(uuid_("transport_advert"), English),
+				},
+			},
+			input: advert.ListFilter{
+				Types:  []domain.AdvertType{domain.AdvertTypeJob, domain.AdvertTypeLawyer},
+				UserID: newUUIDPtr(uuid_("type_user")),
+				Limit:  10,
 			},
+			pre:     insertInput,
+			cleanUp: removeInput,
+			expected: expected{
+				advertsLen: 1,
+			},
+		},
+		{
+			name: "filter by creation date",
+			dbInput: dbInput{
+				userDB: internalUser.GenerateTestUserDB(uuid_("date_user")),
+				advertDB: []AdvertDB{
+					oldAdvert,
+					GenerateTestAdvertDB(uuid_("new_advert"), uuid_("date_user")),
+				},
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("old_advert"), English),
+					GenerateTestAdvertDetailsDB(uuid_("new_advert"), English),
+				},
+			},
+			input: advert.ListFilter{
+				UserID:       newUUIDPtr(uuid_("date_user")),
+				CreatedAfter: newTimePtr(time.Now().Add(-24 * time.Hour)),
+				Limit:        10,
+			},
+			pre:     insertInput,
+			cleanUp: removeInput,
+			expected: expected{
+				advertsLen: 1,
+			},
+		},
+		{
+			name: "limit honoured with language filter",
+			dbInput: dbInput{
+				userDB: internalUser.GenerateTestUserDB(uuid_("lang_user")),
+				advertDB: []AdvertDB{
+					olderUkrainianAdvert,
+					GenerateTestAdvertDB(uuid_("polish_advert"), uuid_("lang_user")),
+				},
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("ukrainian_advert"), Ukrainian),
+					GenerateTestAdvertDetailsDB(uuid_("polish_advert"), Polish),
+				},
+			},
+			input: advert.ListFilter{
+				Languages: LanguageTags{Ukrainian},
+				UserID:    newUUIDPtr(uuid_("lang_user")),
+				Limit:     1,
+			},
+			pre:     insertInput,
+			cleanUp: removeInput,
+			expected: expected{
+				advertsLen: 1,
+			},
+		},
+		{
+			name: "filter by distance",
+			dbInput: dbInput{
+				userDB:   internalUser.GenerateTestUserDB(uuid_("location_user")),
+				advertDB: []AdvertDB{lvivAdvert, kyivAdvert},
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("lviv_advert"), English),
+					GenerateTestAdvertDetailsDB(uuid_("kyiv_advert"), English),
+				},
+			},
+			input: advert.ListFilter{
+				UserID:   newUUIDPtr(uuid_("location_user")),
+				Near:     &domain.Coordinates{Latitude: 49.80, Longitude: 24.00},
+				RadiusKm: 50,
+				Limit:    10,
+			},
+			pre:     insertInput,
+			cleanUp: removeInput,
+			expected: expected{
+				advertsLen: 1,
+			},
+		},
+		{
+			name: "filter by city",
+			dbInput: dbInput{
+				userDB:   internalUser.GenerateTestUserDB(uuid_("location_user")),
+				advertDB: []AdvertDB{lvivAdvert, kyivAdvert},
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("lviv_advert"), English),
+					GenerateTestAdvertDetailsDB(uuid_("kyiv_advert"), English),
+				},
+			},
+			input: advert.ListFilter{
+				UserID: newUUIDPtr(uuid_("location_user")),
+				City:   "kyiv",
+				Limit:  10,
+			},
+			pre:     insertInput,
+			cleanUp: removeInput,
+			expected: expected{
+				advertsLen: 1,
+			},
+		},
+		{
+			name: "destroyed adverts included on demand",
+			dbInput: dbInput{
+				userDB: internalUser.GenerateTestUserDB(uuid_("destroyed_user")),
+				advertDB: []AdvertDB{
+					destroyedAdvert,
+					GenerateTestAdvertDB(uuid_("alive_advert"), uuid_("destroyed_user")),
+				},
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("destroyed_advert"), English),
+					GenerateTestAdvertDetailsDB(uuid_("alive_advert"), English),
+				},
+			},
+			input: advert.ListFilter{
+				UserID:           newUUIDPtr(uuid_("destroyed_user")),
+				IncludeDestroyed: true,
+				Limit:            10,
+			},
+			pre:     insertInput,
+			cleanUp: removeInput,
 			expected: expected{
-				err:        nil,
 				advertsLen: 2,
 			},
 		},
+		{
+			name: "pending adverts listed on demand",
+			dbInput: dbInput{
+				userDB: internalUser.GenerateTestUserDB(uuid_("pending_user")),
+				advertDB: []AdvertDB{
+					pendingAdvert,
+					GenerateTestAdvertDB(uuid_("published_advert"), uuid_("pending_user")),
+				},
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("pending_advert"), English),
+					GenerateTestAdvertDetailsDB(uuid_("published_advert"), English),
+				},
+			},
+			input: advert.ListFilter{
+				UserID:   newUUIDPtr(uuid_("pending_user")),
+				Statuses: []advert.Status{advert.StatusPending},
+				Limit:    10,
+			},
+			pre:     insertInput,
+			cleanUp: removeInput,
+			expected: expected{
+				advertsLen: 1,
+			},
+		},
 	}
 
 	for _, tC := range testCases {
@@ -275,10 +470,707 @@ func TestAdvertPostgresGetList(t *testing.T) {
 				defer tC.cleanUp(t, tC.dbInput)
 			}
 
-			adverts, err := repo.GetList(context.Background(), tC.input.langs, tC.input.limit, tC.input.offset)
+			adverts, err := repo.GetList(context.Background(), tC.input)
 			assert.Equal(t, tC.expected.err, err)
 			assert.Equal(t, tC.expected.advertsLen, len(adverts))
 
 		})
 	}
 }
+
+func TestAdvertPostgresDistance(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		from, to domain.Coordinates
+	}{
+		{name: "same point", from: domain.Coordinates{Latitude: 49.84, Longitude: 24.03}, to: domain.Coordinates{Latitude: 49.84, Longitude: 24.03}},
+		{name: "Lviv to Kyiv", from: domain.Coordinates{Latitude: 49.84, Longitude: 24.03}, to: domain.Coordinates{Latitude: 50.45, Longitude: 30.52}},
+		{name: "antipodes on the equator", from: domain.Coordinates{Latitude: 0, Longitude: 0}, to: domain.Coordinates{Latitude: 0, Longitude: 180}},
+		{name: "antipodes", from: domain.Coordinates{Latitude: 50.45, Longitude: 30.52}, to: domain.Coordinates{Latitude: -50.45, Longitude: -149.48}},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			distance, err := db.SelectFloat("SELECT advert_distance_km($1, $2, $3, $4)",
+				tC.from.Latitude, tC.from.Longitude, tC.to.Latitude, tC.to.Longitude)
+			require.NoError(t, err)
+			assert.InDelta(t, tC.from.DistanceKm(tC.to), distance, 1e-6)
+		})
+	}
+}
+
+func TestAdvertPostgresGetListCursor(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("cursor_user"))
+	require.NoError(t, db.Insert(&userDB))
+
+	// two of the adverts share created_at, so the order between them is decided by id
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Microsecond).UTC()
+	var advertsDB []AdvertDB
+	for i, offset := range []time.Duration{0, time.Minute, time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		advDB := GenerateTestAdvertDB(uuid_(fmt.Sprintf("cursor_advert_%d", i)), userDB.ID)
+		advDB.CreatedAt = createdAt.Add(offset)
+		require.NoError(t, db.Insert(&advDB))
+		detailsDB := GenerateTestAdvertDetailsDB(advDB.ID, English)
+		require.NoError(t, db.Insert(&detailsDB))
+		advertsDB = append(advertsDB, advDB)
+	}
+	defer func() {
+		for _, advDB := range advertsDB {
+			_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advDB.ID)
+			assert.NoError(t, err)
+		}
+		_, err := db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	sort.Slice(advertsDB, func(i, j int) bool {
+		if advertsDB[i].CreatedAt.Equal(advertsDB[j].CreatedAt) {
+			return advertsDB[i].ID.String() > advertsDB[j].ID.String()
+		}
+		return advertsDB[i].CreatedAt.After(advertsDB[j].CreatedAt)
+	})
+	var expected []uuid.UUID
+	for _, advDB := range advertsDB {
+		expected = append(expected, advDB.ID)
+	}
+
+	filter := advert.ListFilter{UserID: &userDB.ID, Limit: 2}
+	var ids []uuid.UUID
+	for page := 0; page < len(advertsDB); page++ {
+		adverts, err := repo.GetList(context.Background(), filter)
+		require.NoError(t, err)
+		if len(adverts) == 0 {
+			break
+		}
+		for _, adv := range adverts {
+			ids = append(ids, adv.ID)
+		}
+		cursor := advert.CursorOf(adverts[len(adverts)-1])
+		filter.After = &cursor
+	}
+	assert.Equal(t, expected, ids)
+
+	// the same order is used by offset pagination
+	adverts, err := repo.GetList(context.Background(), advert.ListFilter{UserID: &userDB.ID, Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, adverts, 2)
+	assert.Equal(t, expected[2:4], []uuid.UUID{adverts[0].ID, adverts[1].ID})
+}
+
+func TestAdvertPostgresSearch(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("search_user"))
+	advertsDB := []AdvertDB{
+		GenerateTestAdvertDB(uuid_("search_english"), uuid_("search_user")),
+		GenerateTestAdvertDB(uuid_("search_polish"), uuid_("search_user")),
+		GenerateTestAdvertDB(uuid_("search_title"), uuid_("search_user")),
+	}
+
+	englishDetails := GenerateTestAdvertDetailsDB(uuid_("search_english"), English)
+	englishDetails.Description = "We are offering rooms for two families near the station"
+	polishDetails := GenerateTestAdvertDetailsDB(uuid_("search_polish"), Polish)
+	polishDetails.Description = "Przewóz osób z granicy do Łodzi"
+	titleDetails := GenerateTestAdvertDetailsDB(uuid_("search_title"), English)
+	titleDetails.Title = "Free room"
+
+	require.NoError(t, db.Insert(&userDB))
+	for _, advDB := range advertsDB {
+		require.NoError(t, db.Insert(&advDB))
+	}
+	for _, detailsDB := range []AdvertDetailsDB{englishDetails, polishDetails, titleDetails} {
+		require.NoError(t, db.Insert(&detailsDB))
+	}
+	defer func() {
+		for _, advDB := range advertsDB {
+			_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advDB.ID)
+			assert.NoError(t, err)
+		}
+		_, err := db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	type input struct {
+		langs LanguageTags
+		query string
+	}
+
+	type testCase struct {
+		name     string
+		input    input
+		expected []uuid.UUID
+	}
+
+	testCases := []testCase{
+		{
+			name:     "english stemming, title match ranked first",
+			input:    input{langs: LanguageTags{English}, query: "room"},
+			expected: []uuid.UUID{uuid_("search_title"), uuid_("search_english")},
+		},
+		{
+			name:     "polish without diacritics",
+			input:    input{langs: LanguageTags{Polish}, query: "przewoz lodzi"},
+			expected: []uuid.UUID{uuid_("search_polish")},
+		},
+		{
+			name:     "match restricted to requested languages",
+			input:    input{langs: LanguageTags{Ukrainian}, query: "room"},
+			expected: nil,
+		},
+		{
+			name:     "no languages means all of them",
+			input:    input{query: "przewóz"},
+			expected: []uuid.UUID{uuid_("search_polish")},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			adverts, err := repo.GetList(context.Background(), advert.ListFilter{Languages: tC.input.langs, Query: tC.input.query, Limit: 10})
+			require.NoError(t, err)
+
+			var ids []uuid.UUID
+			for _, adv := range adverts {
+				ids = append(ids, adv.ID)
+			}
+			assert.Equal(t, tC.expected, ids)
+		})
+	}
+}
+
+func TestAdvertPostgresUpdate(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	type dbInput struct {
+		userDB          internalUser.UserDB
+		advertDB        AdvertDB
+		advertDetailsDB []AdvertDetailsDB
+	}
+
+	type testCase struct {
+		name        string
+		dbInput     dbInput
+		title       MultilingualString
+		description MultilingualString
+		// version the advert was loaded at
+		version     int
+		pre         func(t *testing.T, input dbInput)
+		cleanUp     func(t *testing.T, input dbInput)
+		expectedErr error
+	}
+	uuid_ := internal.HumanFriendlyUUID
+	insertAdvert := func(t *testing.T, input dbInput) {
+		err := db.Insert(&input.userDB, &input.advertDB)
+		assert.NoError(t, err)
+
+		for _, detailsDb := range input.advertDetailsDB {
+			err := db.Insert(&detailsDb)
+			assert.NoError(t, err)
+		}
+	}
+	removeAdvert := func(t *testing.T, input dbInput) {
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", input.advertDB.ID)
+		assert.NoError(t, err)
+
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", input.userDB.ID)
+		assert.NoError(t, err)
+	}
+	changedAdvert := GenerateTestAdvertDB(uuid_("update_changed_advert"), uuid_("update_changed_user"))
+	changedAdvert.Version = 3
+	testCases := []testCase{
+		{
+			name: "success",
+			dbInput: dbInput{
+				userDB:   internalUser.GenerateTestUserDB(uuid_("update_user")),
+				advertDB: GenerateTestAdvertDB(uuid_("update_advert"), uuid_("update_user")),
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("update_advert"), Ukrainian),
+					GenerateTestAdvertDetailsDB(uuid_("update_advert"), English),
+				},
+			},
+			title:       MultilingualString{Polish: "tytuł"},
+			description: MultilingualString{Polish: "opis"},
+			pre:         insertAdvert,
+			cleanUp:     removeAdvert,
+		},
+		{
+			name: "advert changed since it was loaded",
+			dbInput: dbInput{
+				userDB:   internalUser.GenerateTestUserDB(uuid_("update_changed_user")),
+				advertDB: changedAdvert,
+				advertDetailsDB: []AdvertDetailsDB{
+					GenerateTestAdvertDetailsDB(uuid_("update_changed_advert"), English),
+				},
+			},
+			title:       MultilingualString{Polish: "tytuł"},
+			description: MultilingualString{Polish: "opis"},
+			version:     2,
+			pre:         insertAdvert,
+			cleanUp:     removeAdvert,
+			expectedErr: advert.AdvertChangedErr,
+		},
+		{
+			name: "not existing advert",
+			dbInput: dbInput{
+				advertDB: GenerateTestAdvertDB(uuid.New(), uuid.New()),
+			},
+			title:       MultilingualString{Polish: "tytuł"},
+			description: MultilingualString{Polish: "opis"},
+			expectedErr: advert.AdvertNotFound,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			if tC.pre != nil {
+				tC.pre(t, tC.dbInput)
+			}
+			if tC.cleanUp != nil {
+				defer tC.cleanUp(t, tC.dbInput)
+			}
+
+			now := time.Now()
+			attributes := domain.AdvertAttributes{Job: &domain.JobAttributes{ContractType: domain.ContractTypeTemporary}}
+			location := &domain.Location{City: "Kraków", Country: "Polska", Coordinates: &domain.Coordinates{Latitude: 50.06, Longitude: 19.94}}
+			adv := &advert.Advert{
+				ID: tC.dbInput.advertDB.ID,
+				Details: domain.AdvertDetails{
+					Title:          tC.title,
+					Description:    tC.description,
+					Type:           domain.AdvertTypeJob,
+					ContactDetails: getContactDetails(),
+					Location:       location,
+					Attributes:     attributes,
+				},
+				UpdatedAt:        &now,
+				Status:           advert.StatusRejected,
+				ModerationReason: "missing salary",
+				Images:           []advert.Image{advert.NewImage(tC.dbInput.advertDB.ID, "image/jpeg", 800, 600)},
+				Version:          tC.version,
+			}
+			err := repo.Update(context.Background(), adv)
+			assert.ErrorIs(t, err, tC.expectedErr)
+			if tC.expectedErr != nil {
+				return
+			}
+			assert.Equal(t, tC.version+1, adv.Version)
+
+			updated, err := repo.Get(context.Background(), adv.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tC.title, updated.Details.Title)
+			assert.Equal(t, tC.description, updated.Details.Description)
+			assert.Equal(t, domain.AdvertTypeJob, updated.Details.Type)
+			assert.Equal(t, location, updated.Details.Location)
+			assert.Equal(t, attributes, updated.Details.Attributes)
+			assert.Equal(t, advert.StatusRejected, updated.Status)
+			assert.Equal(t, "missing salary", updated.ModerationReason)
+			// images are stored by AddImage only, so concurrent uploads aren't lost
+			assert.Empty(t, updated.Images)
+			assert.Equal(t, tC.dbInput.advertDB.Views, updated.Details.Views)
+			assert.NotNil(t, updated.UpdatedAt)
+			assert.Equal(t, adv.Version, updated.Version)
+		})
+	}
+}
+
+func TestAdvertPostgresImages(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("images_user"))
+	advertDB := GenerateTestAdvertDB(uuid_("images_advert"), uuid_("images_user"))
+	advertDetailsDB := GenerateTestAdvertDetailsDB(uuid_("images_advert"), Polish)
+	require.NoError(t, db.Insert(&userDB, &advertDB, &advertDetailsDB))
+	defer func() {
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	owner := &user.User{ID: userDB.ID}
+	_, err = db.Exec("UPDATE adverts SET status=$1 WHERE id=$2", string(advert.StatusPublished), advertDB.ID.String())
+	require.NoError(t, err)
+	loaded, err := repo.Get(ctx, advertDB.ID)
+	require.NoError(t, err)
+
+	// two uploads started from the same copy of the advert are both kept and the advert waits for their review
+	var images []advert.Image
+	for i := 0; i < 2; i++ {
+		adv := loaded
+		img := advert.NewImage(adv.ID, "image/jpeg", 800, 600)
+		require.NoError(t, adv.AttachImage(owner, img))
+		require.NoError(t, adv.ReviewChanges(advert.ModerationPolicy{}))
+		require.NoError(t, repo.AddImage(ctx, &adv, img))
+		assert.Nil(t, adv.Logs)
+		images = append(images, img)
+	}
+
+	stored, err := repo.Get(ctx, advertDB.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Images, 2)
+	assert.Equal(t, advert.StatusPending, stored.Status)
+
+	// updating the advert leaves its images untouched
+	require.NoError(t, stored.Update(owner, MultilingualString{Polish: "Pokój"}, nil, ""))
+	require.NoError(t, repo.Update(ctx, &stored))
+	stored, err = repo.Get(ctx, advertDB.ID)
+	require.NoError(t, err)
+	assert.Len(t, stored.Images, 2)
+
+	removed, err := stored.RemoveImage(owner, images[0].ID)
+	require.NoError(t, err)
+	require.NoError(t, repo.RemoveImage(ctx, &stored, removed))
+	assert.ErrorIs(t, repo.RemoveImage(ctx, &stored, removed), advert.ImageNotFound)
+
+	stored, err = repo.Get(ctx, advertDB.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Images, 1)
+	assert.Equal(t, images[1].ID, stored.Images[0].ID)
+
+	for i := len(stored.Images); i < advert.MaxImages; i++ {
+		require.NoError(t, repo.AddImage(ctx, &stored, advert.NewImage(stored.ID, "image/jpeg", 800, 600)))
+	}
+	assert.ErrorIs(t, repo.AddImage(ctx, &stored, advert.NewImage(stored.ID, "image/jpeg", 800, 600)), advert.TooManyImagesErr)
+	assert.ErrorIs(t, repo.AddImage(ctx, &advert.Advert{ID: uuid.New()}, advert.NewImage(uuid.New(), "image/jpeg", 800, 600)), advert.AdvertNotFound)
+}
+
+func TestAdvertPostgresDelete(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("delete_user"))
+	advertDB := GenerateTestAdvertDB(uuid_("delete_advert"), uuid_("delete_user"))
+	advertDetailsDB := GenerateTestAdvertDetailsDB(uuid_("delete_advert"), English)
+
+	assert.NoError(t, db.Insert(&userDB, &advertDB, &advertDetailsDB))
+	defer func() {
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	adv, err := repo.Get(context.Background(), advertDB.ID)
+	assert.NoError(t, err)
+	now := time.Now()
+	adv.DestroyedAt = &now
+
+	err = repo.Delete(context.Background(), &adv)
+	assert.NoError(t, err)
+
+	// advert is still stored, but hidden from reading methods
+	destroyedAt, err := db.SelectNullStr("SELECT destroyed_at FROM adverts WHERE id=$1", advertDB.ID)
+	assert.NoError(t, err)
+	assert.True(t, destroyedAt.Valid)
+
+	_, err = repo.Get(context.Background(), advertDB.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	adverts, err := repo.GetList(context.Background(), advert.ListFilter{Limit: 50})
+	assert.NoError(t, err)
+	for _, adv := range adverts {
+		assert.NotEqual(t, advertDB.ID, adv.ID)
+	}
+
+	err = repo.Delete(context.Background(), &adv)
+	assert.ErrorIs(t, err, advert.AdvertNotFound)
+}
+
+func TestAdvertPostgresIncrementViews(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("views_user"))
+	advertDB := GenerateTestAdvertDB(uuid_("views_advert"), uuid_("views_user"))
+	advertDetailsDB := GenerateTestAdvertDetailsDB(uuid_("views_advert"), English)
+
+	assert.NoError(t, db.Insert(&userDB, &advertDB, &advertDetailsDB))
+	defer func() {
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, repo.IncrementViews(context.Background(), advertDB.ID))
+		}()
+	}
+	wg.Wait()
+
+	adv, err := repo.Get(context.Background(), advertDB.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, advertDB.Views+10, adv.Details.Views)
+
+	err = repo.IncrementViews(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, advert.AdvertNotFound)
+}
+
+func TestAdvertPostgresIncrementContactReveals(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("reveals_user"))
+	advertDB := GenerateTestAdvertDB(uuid_("reveals_advert"), uuid_("reveals_user"))
+	advertDetailsDB := GenerateTestAdvertDetailsDB(uuid_("reveals_advert"), English)
+
+	assert.NoError(t, db.Insert(&userDB, &advertDB, &advertDetailsDB))
+	defer func() {
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, repo.IncrementContactReveals(context.Background(), advertDB.ID))
+	}
+
+	adv, err := repo.Get(context.Background(), advertDB.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, adv.Details.ContactReveals)
+	assert.Equal(t, advertDB.Views, adv.Details.Views)
+
+	// updating the advert doesn't reset the counter
+	require.NoError(t, repo.Update(context.Background(), &adv))
+	adv, err = repo.Get(context.Background(), advertDB.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, adv.Details.ContactReveals)
+
+	err = repo.IncrementContactReveals(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, advert.AdvertNotFound)
+}
+
+func TestAdvertPostgresTranslations(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("translation_user"))
+	advertDB := GenerateTestAdvertDB(uuid_("translation_advert"), uuid_("translation_user"))
+	translatedDB := GenerateTestAdvertDB(uuid_("translated_advert"), uuid_("translation_user"))
+	now := time.Now()
+	translatedDB.TranslatedAt = &now
+	advertDetailsDB := GenerateTestAdvertDetailsDB(uuid_("translation_advert"), Polish)
+	translatedDetailsDB := GenerateTestAdvertDetailsDB(uuid_("translated_advert"), Polish)
+
+	require.NoError(t, db.Insert(&userDB, &advertDB, &translatedDB, &advertDetailsDB, &translatedDetailsDB))
+	defer func() {
+		for _, id := range []uuid.UUID{advertDB.ID, translatedDB.ID} {
+			_, err := db.Exec("DELETE FROM adverts WHERE id=$1", id)
+			assert.NoError(t, err)
+		}
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	untranslated, err := repo.GetUntranslated(ctx, 1000)
+	require.NoError(t, err)
+
+	var adv *advert.Advert
+	for _, untranslatedAdv := range untranslated {
+		assert.NotEqual(t, translatedDB.ID, untranslatedAdv.ID)
+		if untranslatedAdv.ID == advertDB.ID {
+			adv = untranslatedAdv
+		}
+	}
+	require.NotNil(t, adv)
+
+	outdated := *adv
+	require.NoError(t, adv.AddMachineTranslation(English, "Room", "Free room"))
+	adv.MarkTranslated()
+	require.NoError(t, repo.SaveTranslations(ctx, adv))
+
+	// the advert translated in the meantime isn't overwritten
+	require.NoError(t, outdated.AddMachineTranslation(English, "Flat", "Free flat"))
+	outdated.MarkTranslated()
+	assert.ErrorIs(t, repo.SaveTranslations(ctx, &outdated), advert.TranslationOutdatedErr)
+
+	stored, err := repo.Get(ctx, advertDB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Room", stored.Details.Title[English])
+	assert.Equal(t, advertDetailsDB.Title, stored.Details.Title[Polish])
+	assert.Equal(t, LanguageTags{English}, stored.Details.MachineTranslated)
+	assert.NotNil(t, stored.TranslatedAt)
+
+	// updated text drops machine translations, so the advert waits for the translation again
+	owner := &user.User{ID: userDB.ID}
+	require.NoError(t, stored.Update(owner, MultilingualString{Polish: "Pokój"}, MultilingualString{Polish: "Wolny pokój"}, ""))
+	require.NoError(t, repo.Update(ctx, &stored))
+
+	stored, err = repo.Get(ctx, advertDB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, MultilingualString{Polish: "Pokój"}, stored.Details.Title)
+	assert.Nil(t, stored.Details.MachineTranslated)
+	assert.Nil(t, stored.TranslatedAt)
+}
+
+func TestAdvertPostgresFailTranslation(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("failing_translation_user"))
+	failingDB := GenerateTestAdvertDB(uuid_("failing_translation_advert"), uuid_("failing_translation_user"))
+	failingDB.CreatedAt = time.Now().Add(-time.Hour)
+	newerDB := GenerateTestAdvertDB(uuid_("newer_translation_advert"), uuid_("failing_translation_user"))
+	failingDetailsDB := GenerateTestAdvertDetailsDB(uuid_("failing_translation_advert"), Polish)
+	newerDetailsDB := GenerateTestAdvertDetailsDB(uuid_("newer_translation_advert"), Polish)
+
+	require.NoError(t, db.Insert(&userDB, &failingDB, &newerDB, &failingDetailsDB, &newerDetailsDB))
+	defer func() {
+		for _, id := range []uuid.UUID{failingDB.ID, newerDB.ID} {
+			_, err := db.Exec("DELETE FROM adverts WHERE id=$1", id)
+			assert.NoError(t, err)
+		}
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	untranslatedIDs := func() []uuid.UUID {
+		untranslated, err := repo.GetUntranslated(ctx, 1000)
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, adv := range untranslated {
+			if adv.ID == failingDB.ID || adv.ID == newerDB.ID {
+				ids = append(ids, adv.ID)
+			}
+		}
+		return ids
+	}
+	assert.Equal(t, []uuid.UUID{failingDB.ID, newerDB.ID}, untranslatedIDs())
+
+	// the failed advert goes behind the newer one
+	require.NoError(t, repo.FailTranslation(ctx, failingDB.ID, 2))
+	assert.Equal(t, []uuid.UUID{newerDB.ID, failingDB.ID}, untranslatedIDs())
+
+	// and leaves the queue once it runs out of attempts
+	require.NoError(t, repo.FailTranslation(ctx, failingDB.ID, 2))
+	assert.Equal(t, []uuid.UUID{newerDB.ID}, untranslatedIDs())
+}
+
+func TestAdvertPostgresArchiveExpired(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("expiry_user"))
+	expiredDB := GenerateTestAdvertDB(uuid_("expired_advert"), uuid_("expiry_user"))
+	expiredDB.ExpiresAt = time.Now().Add(-time.Hour)
+	activeDB := GenerateTestAdvertDB(uuid_("active_advert"), uuid_("expiry_user"))
+	expiredDetailsDB := GenerateTestAdvertDetailsDB(uuid_("expired_advert"), English)
+	activeDetailsDB := GenerateTestAdvertDetailsDB(uuid_("active_advert"), English)
+
+	require.NoError(t, db.Insert(&userDB, &expiredDB, &activeDB, &expiredDetailsDB, &activeDetailsDB))
+	defer func() {
+		for _, id := range []uuid.UUID{expiredDB.ID, activeDB.ID} {
+			_, err := db.Exec("DELETE FROM adverts WHERE id=$1", id.String())
+			assert.NoError(t, err)
+		}
+		_, err := db.Exec("DELETE FROM users WHERE id=$1", userDB.ID.String())
+		assert.NoError(t, err)
+	}()
+
+	// expired adverts are hidden even before they are archived
+	adverts, err := repo.GetList(context.Background(), advert.ListFilter{UserID: &userDB.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, adverts, 1)
+	assert.Equal(t, activeDB.ID, adverts[0].ID)
+
+	adverts, err = repo.GetList(context.Background(), advert.ListFilter{UserID: &userDB.ID, IncludeExpired: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, adverts, 2)
+
+	ids, err := repo.ArchiveExpired(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Contains(t, ids, expiredDB.ID)
+	assert.NotContains(t, ids, activeDB.ID)
+
+	adv, err := repo.Get(context.Background(), expiredDB.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, adv.ArchivedAt)
+
+	var triggers []string
+	_, err = db.Select(&triggers, "SELECT trigger FROM advert_logs WHERE advert_id=$1", expiredDB.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(advert.AdvertArchivedEvent)}, triggers)
+
+	// already archived adverts are not archived again
+	ids, err = repo.ArchiveExpired(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.NotContains(t, ids, expiredDB.ID)
+}
@@ -3,6 +3,7 @@ package advert
 import (
 	"github.com/google/uuid"
 	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
 	"github.com/ukrainian-brothers/board-backend/pkg/test_helpers"
 	"github.com/ukrainian-brothers/board-backend/pkg/translation"
 	"time"
@@ -18,6 +19,8 @@ func GenerateTestAdvertDB(advertID uuid.UUID, userID uuid.UUID) AdvertDB {
 			Mail: test_helpers.RandomMail(),
 		},
 		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(domain.AdvertTypeTransport.Lifetime()),
+		Status:    advert.StatusPublished,
 	}
 }
 func GenerateTestAdvertDetailsDB(advertID uuid.UUID, language translation.LanguageTag) AdvertDetailsDB {
@@ -20,9 +20,58 @@ type SessionConfig struct {
 	SessionKey string `json:"session_key"`
 }
 
+type ModerationConfig struct {
+	// AutoPublishTrusted skips the review of adverts added by trusted users
+	AutoPublishTrusted bool `json:"auto_publish_trusted"`
+	// ReportsHideThreshold is the number of distinct reporters after which the advert is hidden until reviewed,
+	// zero never hides adverts and the default is used when it's not set
+	ReportsHideThreshold *int `json:"reports_hide_threshold"`
+}
+
+type ImagesConfig struct {
+	// StorageDir is the directory where uploaded images are kept
+	StorageDir string `json:"storage_dir"`
+	// MaxUploadSize is the limit of a single uploaded image in bytes
+	MaxUploadSize int64 `json:"max_upload_size"`
+}
+
+type ContactsConfig struct {
+	// Protected omits contact details from advert lists and details, they have to be revealed by logged in users
+	Protected bool `json:"protected"`
+}
+
+type TranslationConfig struct {
+	// URL of LibreTranslate compatible service, missing languages of adverts aren't translated if it's empty
+	URL    string `json:"url"`
+	APIKey string `json:"api_key"`
+}
+
+type LanguagesConfig struct {
+	// Supported are BCP 47 tags of languages adverts can be written in, English, Polish and Ukrainian are used when it's empty
+	Supported []string `json:"supported"`
+	// Aliases map alternative codes to the supported languages, e.g. {"ua": "uk"} which is always present
+	Aliases map[string]string `json:"aliases"`
+	// Fallback is the order of languages served when the advert isn't available in any language accepted by the client, e.g. ["uk", "pl", "en"]
+	Fallback []string `json:"fallback"`
+}
+
+type ProxyConfig struct {
+	// TrustedProxies are IP addresses or CIDR ranges of reverse proxies in front of the server, e.g. ["127.0.0.1"].
+	// Client addresses are read from ClientIPHeader of their requests only, so anonymous viewers and reporters aren't taken for one.
+	TrustedProxies []string `json:"trusted_proxies"`
+	// ClientIPHeader is the header the proxies pass the client address in, X-Forwarded-For is used when it's empty
+	ClientIPHeader string `json:"client_ip_header"`
+}
+
 type Config struct {
-	Postgres PostgresConfig `json:"postgres_config"`
-	Session  SessionConfig  `json:"session_config"`
+	Postgres    PostgresConfig    `json:"postgres_config"`
+	Session     SessionConfig     `json:"session_config"`
+	Moderation  ModerationConfig  `json:"moderation_config"`
+	Images      ImagesConfig      `json:"images_config"`
+	Contacts    ContactsConfig    `json:"contacts_config"`
+	Translation TranslationConfig `json:"translation_config"`
+	Languages   LanguagesConfig   `json:"languages_config"`
+	Proxy       ProxyConfig       `json:"proxy_config"`
 }
 
 func NewConfigFromFile(fileName string) (*Config, error) {
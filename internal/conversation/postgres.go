@@ -0,0 +1,294 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-gorp/gorp"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"time"
+)
+
+type PostgresConversationRepository struct {
+	db *gorp.DbMap
+}
+
+func NewPostgresConversationRepository(db *gorp.DbMap) *PostgresConversationRepository {
+	db.AddTableWithName(ConversationDB{}, "conversations").SetKeys(false, "id")
+	db.AddTableWithName(MessageDB{}, "conversation_messages").SetKeys(false, "id")
+
+	return &PostgresConversationRepository{
+		db: db,
+	}
+}
+
+type ConversationDB struct {
+	ID            uuid.UUID  `db:"id"`
+	AdvertID      uuid.UUID  `db:"advert_id"`
+	OwnerID       uuid.UUID  `db:"owner_id"`
+	SeekerID      uuid.UUID  `db:"seeker_id"`
+	OwnerReadAt   *time.Time `db:"owner_read_at"`
+	SeekerReadAt  *time.Time `db:"seeker_read_at"`
+	LastMessageAt time.Time  `db:"last_message_at"`
+	BlockedAt     *time.Time `db:"blocked_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+func conversationToDB(c *conversation.Conversation) ConversationDB {
+	return ConversationDB{
+		ID:            c.ID,
+		AdvertID:      c.AdvertID,
+		OwnerID:       c.OwnerID,
+		SeekerID:      c.SeekerID,
+		OwnerReadAt:   c.OwnerReadAt,
+		SeekerReadAt:  c.SeekerReadAt,
+		LastMessageAt: c.LastMessageAt,
+		BlockedAt:     c.BlockedAt,
+		CreatedAt:     c.CreatedAt,
+	}
+}
+
+func (c ConversationDB) Conversation() conversation.Conversation {
+	return conversation.Conversation{
+		ID:            c.ID,
+		AdvertID:      c.AdvertID,
+		OwnerID:       c.OwnerID,
+		SeekerID:      c.SeekerID,
+		OwnerReadAt:   c.OwnerReadAt,
+		SeekerReadAt:  c.SeekerReadAt,
+		LastMessageAt: c.LastMessageAt,
+		BlockedAt:     c.BlockedAt,
+		CreatedAt:     c.CreatedAt,
+	}
+}
+
+type MessageDB struct {
+	ID             uuid.UUID `db:"id"`
+	ConversationID uuid.UUID `db:"conversation_id"`
+	SenderID       uuid.UUID `db:"sender_id"`
+	Body           string    `db:"body"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+func (repo PostgresConversationRepository) selectOne(ctx context.Context, query string, args ...interface{}) (conversation.Conversation, error) {
+	var conversationDB ConversationDB
+	err := repo.db.WithContext(ctx).SelectOne(&conversationDB, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return conversation.Conversation{}, conversation.ConversationNotFound
+		}
+		return conversation.Conversation{}, fmt.Errorf("getting conversation failed while selecting from db %w", err)
+	}
+	return conversationDB.Conversation(), nil
+}
+
+func (repo PostgresConversationRepository) Get(ctx context.Context, id uuid.UUID) (conversation.Conversation, error) {
+	return repo.selectOne(ctx, "SELECT * FROM conversations WHERE id=$1", id.String())
+}
+
+func (repo PostgresConversationRepository) GetByAdvert(ctx context.Context, advertID uuid.UUID, seekerID uuid.UUID) (conversation.Conversation, error) {
+	return repo.selectOne(ctx, "SELECT * FROM conversations WHERE advert_id=$1 AND seeker_id=$2", advertID.String(), seekerID.String())
+}
+
+func (repo PostgresConversationRepository) GetList(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]conversation.Conversation, error) {
+	var conversationsDB []ConversationDB
+	_, err := repo.db.WithContext(ctx).Select(&conversationsDB, `
+	SELECT * FROM conversations WHERE owner_id=$1 OR seeker_id=$1
+	ORDER BY last_message_at DESC, id DESC LIMIT $2 OFFSET $3`, userID.String(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting conversations: %w", err)
+	}
+
+	var conversations []conversation.Conversation
+	for _, conversationDB := range conversationsDB {
+		conversations = append(conversations, conversationDB.Conversation())
+	}
+	return conversations, nil
+}
+
+func (repo PostgresConversationRepository) Add(ctx context.Context, c *conversation.Conversation, message *conversation.Message) error {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for adding conversation: %w", err)
+	}
+
+	conversationDB := conversationToDB(c)
+	err = trans.WithContext(ctx).Insert(&conversationDB)
+	if err != nil {
+		_ = trans.Rollback()
+		return fmt.Errorf("adding conversation failed while performing sql %w", err)
+	}
+
+	err = insertMessage(trans.WithContext(ctx), message)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing added conversation: %w", err)
+	}
+	return nil
+}
+
+func (repo PostgresConversationRepository) Update(ctx context.Context, c *conversation.Conversation) error {
+	return updateConversation(repo.db.WithContext(ctx), c)
+}
+
+func (repo PostgresConversationRepository) UpdateBlock(ctx context.Context, c *conversation.Conversation) error {
+	result, err := repo.db.WithContext(ctx).Exec("UPDATE conversations SET blocked_at=$1 WHERE id=$2", c.BlockedAt, c.ID.String())
+	if err != nil {
+		return fmt.Errorf("updating conversation block failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("updating conversation block failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return conversation.ConversationNotFound
+	}
+	return nil
+}
+
+func (repo PostgresConversationRepository) AddMessage(ctx context.Context, c *conversation.Conversation, message *conversation.Message) error {
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for adding message: %w", err)
+	}
+
+	err = insertUnblockedMessage(trans.WithContext(ctx), message)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = updateConversation(trans.WithContext(ctx), c)
+	if err != nil {
+		_ = trans.Rollback()
+		return err
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing added message: %w", err)
+	}
+	return nil
+}
+
+func insertMessage(sqlExec gorp.SqlExecutor, message *conversation.Message) error {
+	messageDB := MessageDB{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Body:           message.Body,
+		CreatedAt:      message.CreatedAt,
+	}
+
+	err := sqlExec.Insert(&messageDB)
+	if err != nil {
+		return fmt.Errorf("adding message failed while performing sql %w", err)
+	}
+	return nil
+}
+
+// insertUnblockedMessage stores the message only if the sender isn't blocked, the block may have been set
+// after the conversation was loaded
+func insertUnblockedMessage(sqlExec gorp.SqlExecutor, message *conversation.Message) error {
+	result, err := sqlExec.Exec(`
+	INSERT INTO conversation_messages (id, conversation_id, sender_id, body, created_at)
+	SELECT $1, id, $2, $3, $4::timestamp FROM conversations WHERE id=$5 AND (blocked_at IS NULL OR owner_id=$2)`,
+		message.ID.String(), message.SenderID.String(), message.Body, message.CreatedAt, message.ConversationID.String())
+	if err != nil {
+		return fmt.Errorf("adding message failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("adding message failed while reading affected rows %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	exists, err := sqlExec.SelectInt("SELECT count(*) FROM conversations WHERE id=$1", message.ConversationID.String())
+	if err != nil {
+		return fmt.Errorf("adding message failed while checking the conversation %w", err)
+	}
+	if exists == 0 {
+		return conversation.ConversationNotFound
+	}
+	return conversation.BlockedErr
+}
+
+// updateConversation never moves the read markers and the last message back, so concurrent requests don't lose each other's changes.
+// The block is left untouched, it's written only by UpdateBlock.
+func updateConversation(sqlExec gorp.SqlExecutor, c *conversation.Conversation) error {
+	result, err := sqlExec.Exec(`
+	UPDATE conversations SET
+		owner_read_at=GREATEST(owner_read_at, $1), seeker_read_at=GREATEST(seeker_read_at, $2),
+		last_message_at=GREATEST(last_message_at, $3)
+	WHERE id=$4`, c.OwnerReadAt, c.SeekerReadAt, c.LastMessageAt, c.ID.String())
+	if err != nil {
+		return fmt.Errorf("updating conversation failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("updating conversation failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return conversation.ConversationNotFound
+	}
+	return nil
+}
+
+func (repo PostgresConversationRepository) GetMessages(ctx context.Context, conversationID uuid.UUID, limit int, offset int) ([]conversation.Message, error) {
+	var messagesDB []MessageDB
+	_, err := repo.db.WithContext(ctx).Select(&messagesDB, `
+	SELECT * FROM conversation_messages WHERE conversation_id=$1
+	ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`, conversationID.String(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting messages: %w", err)
+	}
+
+	var messages []conversation.Message
+	for _, messageDB := range messagesDB {
+		messages = append(messages, conversation.Message{
+			ID:             messageDB.ID,
+			ConversationID: messageDB.ConversationID,
+			SenderID:       messageDB.SenderID,
+			Body:           messageDB.Body,
+			CreatedAt:      messageDB.CreatedAt,
+		})
+	}
+	return messages, nil
+}
+
+type unreadDB struct {
+	ConversationID uuid.UUID `db:"conversation_id"`
+	Unread         int       `db:"unread"`
+}
+
+func (repo PostgresConversationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]int, error) {
+	var unreadsDB []unreadDB
+	_, err := repo.db.WithContext(ctx).Select(&unreadsDB, `
+	SELECT conversations.id AS conversation_id, count(*) AS unread
+	FROM conversations JOIN conversation_messages ON conversation_messages.conversation_id = conversations.id
+	WHERE (conversations.owner_id=$1 OR conversations.seeker_id=$1) AND conversation_messages.sender_id <> $1
+	  AND conversation_messages.created_at > COALESCE(
+		CASE WHEN conversations.owner_id=$1 THEN conversations.owner_read_at ELSE conversations.seeker_read_at END, '-infinity')
+	GROUP BY conversations.id`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed counting unread messages: %w", err)
+	}
+
+	unread := map[uuid.UUID]int{}
+	for _, unreadDB := range unreadsDB {
+		unread[unreadDB.ConversationID] = unreadDB.Unread
+	}
+	return unread, nil
+}
@@ -0,0 +1,116 @@
+package conversation
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/conversation"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal"
+	internalAdvert "github.com/ukrainian-brothers/board-backend/internal/advert"
+	"github.com/ukrainian-brothers/board-backend/internal/common"
+	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
+	"testing"
+)
+
+func TestConversationPostgres(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresConversationRepository(db)
+	internalAdvert.NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	ownerDB := internalUser.GenerateTestUserDB(uuid_("conversation_owner"))
+	seekerDB := internalUser.GenerateTestUserDB(uuid_("conversation_seeker"))
+	advertDB := internalAdvert.GenerateTestAdvertDB(uuid_("conversation_advert"), ownerDB.ID)
+	require.NoError(t, db.Insert(&ownerDB, &seekerDB, &advertDB))
+	defer func() {
+		// conversations and messages should be removed due to fk policy
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id IN ($1, $2)", ownerDB.ID, seekerDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	owner := &user.User{ID: ownerDB.ID}
+	seeker := &user.User{ID: seekerDB.ID}
+	adv := &advert.Advert{ID: advertDB.ID, User: owner}
+
+	conv, err := conversation.NewConversation(adv, seeker)
+	require.NoError(t, err)
+	question, err := conv.AddMessage(seeker, "Is it available?")
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, conv, question))
+
+	stored, err := repo.GetByAdvert(ctx, advertDB.ID, seekerDB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, conv.ID, stored.ID)
+	assert.NotNil(t, stored.SeekerReadAt)
+	assert.Nil(t, stored.OwnerReadAt)
+
+	_, err = repo.GetByAdvert(ctx, advertDB.ID, ownerDB.ID)
+	assert.ErrorIs(t, err, conversation.ConversationNotFound)
+
+	unread, err := repo.CountUnread(ctx, owner.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, unread[conv.ID])
+
+	unread, err = repo.CountUnread(ctx, seeker.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, unread)
+
+	answer, err := stored.AddMessage(owner, "Yes, it is")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMessage(ctx, &stored, answer))
+
+	unread, err = repo.CountUnread(ctx, owner.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, unread)
+
+	unread, err = repo.CountUnread(ctx, seeker.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, unread[conv.ID])
+
+	// the seeker loaded the conversation before the owner blocked it
+	loadedBySeeker := stored
+	require.NoError(t, stored.Block(owner))
+	require.NoError(t, repo.UpdateBlock(ctx, &stored))
+
+	late, err := loadedBySeeker.AddMessage(seeker, "Hello?")
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.AddMessage(ctx, &loadedBySeeker, late), conversation.BlockedErr)
+	require.NoError(t, loadedBySeeker.MarkRead(seeker))
+	require.NoError(t, repo.Update(ctx, &loadedBySeeker))
+
+	stored, err = repo.Get(ctx, conv.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsBlocked())
+	assert.NotNil(t, stored.OwnerReadAt)
+
+	ownerReply, err := stored.AddMessage(owner, "Bye")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMessage(ctx, &stored, ownerReply))
+
+	conversations, err := repo.GetList(ctx, seeker.ID, 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, conversations, 1)
+	assert.Equal(t, conv.ID, conversations[0].ID)
+
+	messages, err := repo.GetMessages(ctx, conv.ID, 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, ownerReply.ID, messages[0].ID)
+	assert.Equal(t, answer.ID, messages[1].ID)
+	assert.Equal(t, question.ID, messages[2].ID)
+
+	messages, err = repo.GetMessages(ctx, conv.ID, 1, 2)
+	assert.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, question.ID, messages[0].ID)
+}
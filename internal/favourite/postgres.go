@@ -0,0 +1,103 @@
+package favourite
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-gorp/gorp"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+	"time"
+)
+
+type PostgresFavouriteRepository struct {
+	db *gorp.DbMap
+}
+
+func NewPostgresFavouriteRepository(db *gorp.DbMap) *PostgresFavouriteRepository {
+	db.AddTableWithName(FavouriteDB{}, "advert_favourites").SetKeys(false, "user_id", "advert_id")
+
+	return &PostgresFavouriteRepository{
+		db: db,
+	}
+}
+
+type FavouriteDB struct {
+	UserID    uuid.UUID `db:"user_id"`
+	AdvertID  uuid.UUID `db:"advert_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (repo PostgresFavouriteRepository) Add(ctx context.Context, fav *favourite.Favourite) error {
+	_, err := repo.db.WithContext(ctx).Exec(`
+	INSERT INTO advert_favourites (user_id, advert_id, created_at) VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, advert_id) DO NOTHING`, fav.UserID.String(), fav.AdvertID.String(), fav.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("adding favourite failed while performing sql %w", err)
+	}
+	return nil
+}
+
+func (repo PostgresFavouriteRepository) Remove(ctx context.Context, userID uuid.UUID, advertID uuid.UUID) error {
+	_, err := repo.db.WithContext(ctx).Exec("DELETE FROM advert_favourites WHERE user_id=$1 AND advert_id=$2", userID.String(), advertID.String())
+	if err != nil {
+		return fmt.Errorf("removing favourite failed while performing sql %w", err)
+	}
+	return nil
+}
+
+func (repo PostgresFavouriteRepository) GetList(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]favourite.Favourite, error) {
+	var favouritesDB []FavouriteDB
+	_, err := repo.db.WithContext(ctx).Select(&favouritesDB, `
+	SELECT * FROM advert_favourites WHERE user_id=$1
+	ORDER BY created_at DESC, advert_id DESC LIMIT $2 OFFSET $3`, userID.String(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting favourites: %w", err)
+	}
+
+	var favourites []favourite.Favourite
+	for _, favDB := range favouritesDB {
+		favourites = append(favourites, favourite.Favourite{
+			UserID:    favDB.UserID,
+			AdvertID:  favDB.AdvertID,
+			CreatedAt: favDB.CreatedAt,
+		})
+	}
+	return favourites, nil
+}
+
+func (repo PostgresFavouriteRepository) Count(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := repo.db.WithContext(ctx).SelectInt("SELECT count(*) FROM advert_favourites WHERE user_id=$1", userID.String())
+	if err != nil {
+		return 0, fmt.Errorf("counting favourites failed %w", err)
+	}
+	return int(count), nil
+}
+
+func (repo PostgresFavouriteRepository) AreFavourite(ctx context.Context, userID uuid.UUID, advertIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	favourites := map[uuid.UUID]bool{}
+	if len(advertIDs) == 0 {
+		return favourites, nil
+	}
+
+	var ids []string
+	for _, id := range advertIDs {
+		ids = append(ids, id.String())
+	}
+
+	var favouriteIDs []string
+	_, err := repo.db.WithContext(ctx).Select(&favouriteIDs, "SELECT advert_id FROM advert_favourites WHERE user_id=$1 AND advert_id = ANY($2::varchar[])",
+		userID.String(), pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting favourite adverts: %w", err)
+	}
+
+	for _, favouriteID := range favouriteIDs {
+		id, err := uuid.Parse(favouriteID)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing favourite advert id: %w", err)
+		}
+		favourites[id] = true
+	}
+	return favourites, nil
+}
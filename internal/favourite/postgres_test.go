@@ -0,0 +1,81 @@
+package favourite
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain/favourite"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal"
+	internalAdvert "github.com/ukrainian-brothers/board-backend/internal/advert"
+	"github.com/ukrainian-brothers/board-backend/internal/common"
+	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
+	"testing"
+	"time"
+)
+
+func TestFavouritePostgres(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresFavouriteRepository(db)
+	internalAdvert.NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("favourite_user"))
+	firstDB := internalAdvert.GenerateTestAdvertDB(uuid_("favourite_first"), uuid_("favourite_user"))
+	secondDB := internalAdvert.GenerateTestAdvertDB(uuid_("favourite_second"), uuid_("favourite_user"))
+	require.NoError(t, db.Insert(&userDB, &firstDB, &secondDB))
+	defer func() {
+		// favourites should be removed due to fk policy
+		_, err := db.Exec("DELETE FROM adverts WHERE id IN ($1, $2)", firstDB.ID, secondDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	usr := &user.User{ID: userDB.ID}
+
+	first, err := favourite.NewFavourite(usr, firstDB.ID)
+	require.NoError(t, err)
+	first.CreatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Add(ctx, first))
+
+	second, err := favourite.NewFavourite(usr, secondDB.ID)
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, second))
+
+	// adding again is ignored
+	require.NoError(t, repo.Add(ctx, first))
+
+	count, err := repo.Count(ctx, usr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	favourites, err := repo.GetList(ctx, usr.ID, 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, favourites, 2)
+	assert.Equal(t, secondDB.ID, favourites[0].AdvertID)
+	assert.Equal(t, firstDB.ID, favourites[1].AdvertID)
+
+	favourites, err = repo.GetList(ctx, usr.ID, 1, 1)
+	assert.NoError(t, err)
+	require.Len(t, favourites, 1)
+	assert.Equal(t, firstDB.ID, favourites[0].AdvertID)
+
+	areFavourite, err := repo.AreFavourite(ctx, usr.ID, []uuid.UUID{firstDB.ID, uuid.New()})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]bool{firstDB.ID: true}, areFavourite)
+
+	require.NoError(t, repo.Remove(ctx, usr.ID, firstDB.ID))
+	require.NoError(t, repo.Remove(ctx, usr.ID, firstDB.ID))
+
+	count, err = repo.Count(ctx, usr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-gorp/gorp"
+	"github.com/google/uuid"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+	"strings"
+	"time"
+)
+
+type PostgresReportRepository struct {
+	db *gorp.DbMap
+}
+
+func NewPostgresReportRepository(db *gorp.DbMap) *PostgresReportRepository {
+	db.AddTableWithName(ReportDB{}, "advert_reports").SetKeys(false, "id")
+
+	return &PostgresReportRepository{
+		db: db,
+	}
+}
+
+type ReportDB struct {
+	ID         uuid.UUID     `db:"id"`
+	AdvertID   uuid.UUID     `db:"advert_id"`
+	ReporterID *string       `db:"reporter_id"`
+	ReporterIP *string       `db:"reporter_ip"`
+	Reason     report.Reason `db:"reason"`
+	Details    string        `db:"details"`
+	CreatedAt  time.Time     `db:"created_at"`
+	ResolvedAt *time.Time    `db:"resolved_at"`
+	ResolvedBy *string       `db:"resolved_by"`
+	Resolution *string       `db:"resolution"`
+}
+
+func uuidPtrToDB(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
+// reporterIPToDB stores the address of anonymous reporters only, reports of users are told apart by the user
+func reporterIPToDB(rep *report.Report) *string {
+	if rep.ReporterID != nil || rep.ReporterIP == "" {
+		return nil
+	}
+	return &rep.ReporterIP
+}
+
+func uuidPtrFromDB(id *string) (*uuid.UUID, error) {
+	if id == nil {
+		return nil, nil
+	}
+	parsed, err := uuid.Parse(*id)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func (r ReportDB) Report() (report.Report, error) {
+	reporterID, err := uuidPtrFromDB(r.ReporterID)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("failed parsing reporter id: %w", err)
+	}
+
+	resolvedBy, err := uuidPtrFromDB(r.ResolvedBy)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("failed parsing resolver id: %w", err)
+	}
+
+	rep := report.Report{
+		ID:         r.ID,
+		AdvertID:   r.AdvertID,
+		ReporterID: reporterID,
+		Reason:     r.Reason,
+		Details:    r.Details,
+		CreatedAt:  r.CreatedAt,
+		ResolvedAt: r.ResolvedAt,
+		ResolvedBy: resolvedBy,
+	}
+	if r.ReporterIP != nil {
+		rep.ReporterIP = *r.ReporterIP
+	}
+	if r.Resolution != nil {
+		rep.Resolution = report.Resolution(*r.Resolution)
+	}
+	return rep, nil
+}
+
+func (repo PostgresReportRepository) Get(ctx context.Context, id uuid.UUID) (report.Report, error) {
+	sqlExec := repo.db.WithContext(ctx)
+
+	var reportDB ReportDB
+	err := sqlExec.SelectOne(&reportDB, "SELECT * FROM advert_reports WHERE id=$1", id.String())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return report.Report{}, report.ReportNotFound
+		}
+		return report.Report{}, fmt.Errorf("getting report failed while selecting from db %w", err)
+	}
+	return reportDB.Report()
+}
+
+func (repo PostgresReportRepository) GetList(ctx context.Context, filter report.ListFilter) ([]report.Report, error) {
+	sqlExec := repo.db.WithContext(ctx)
+
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	if filter.AdvertID != nil {
+		args = append(args, filter.AdvertID.String())
+		conditions = append(conditions, fmt.Sprintf("advert_id = $%d", len(args)))
+	}
+	if filter.OnlyOpen {
+		conditions = append(conditions, "resolved_at IS NULL")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	var reportsDB []ReportDB
+	_, err := sqlExec.Select(&reportsDB, fmt.Sprintf("SELECT * FROM advert_reports WHERE %s ORDER BY created_at, id LIMIT $%d OFFSET $%d",
+		strings.Join(conditions, " AND "), len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting reports: %w", err)
+	}
+
+	var reports []report.Report
+	for _, reportDB := range reportsDB {
+		rep, err := reportDB.Report()
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+	return reports, nil
+}
+
+func (repo PostgresReportRepository) Add(ctx context.Context, report *report.Report) error {
+	reportDB := ReportDB{
+		ID:         report.ID,
+		AdvertID:   report.AdvertID,
+		ReporterID: uuidPtrToDB(report.ReporterID),
+		ReporterIP: reporterIPToDB(report),
+		Reason:     report.Reason,
+		Details:    report.Details,
+		CreatedAt:  report.CreatedAt,
+	}
+
+	err := repo.db.WithContext(ctx).Insert(&reportDB)
+	if err != nil {
+		return fmt.Errorf("adding report failed while performing sql %w", err)
+	}
+	return nil
+}
+
+// Resolve stores the resolution only if the report hasn't been resolved in the meantime
+func (repo PostgresReportRepository) Resolve(ctx context.Context, rep *report.Report) error {
+	sqlExec := repo.db.WithContext(ctx)
+
+	result, err := sqlExec.Exec("UPDATE advert_reports SET resolved_at=$1, resolved_by=$2, resolution=$3 WHERE id=$4 AND resolved_at IS NULL",
+		rep.ResolvedAt, uuidPtrToDB(rep.ResolvedBy), string(rep.Resolution), rep.ID.String())
+	if err != nil {
+		return fmt.Errorf("resolving report failed while performing sql %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("resolving report failed while reading affected rows %w", err)
+	}
+	if affected == 0 {
+		return report.AlreadyResolvedErr
+	}
+	return nil
+}
+
+// CountOpenReporters counts every user and every address of anonymous reporters once, anonymous reports without
+// the address are counted one by one
+func (repo PostgresReportRepository) CountOpenReporters(ctx context.Context, advertID uuid.UUID) (int, error) {
+	count, err := repo.db.WithContext(ctx).SelectInt(`SELECT count(DISTINCT coalesce(reporter_id, 'ip:' || reporter_ip, id)) FROM advert_reports
+		WHERE advert_id=$1 AND resolved_at IS NULL`, advertID.String())
+	if err != nil {
+		return 0, fmt.Errorf("counting open reporters failed %w", err)
+	}
+	return int(count), nil
+}
+
+func (repo PostgresReportRepository) HasOpen(ctx context.Context, rep report.Report) (bool, error) {
+	exists, err := repo.db.WithContext(ctx).SelectStr(`select exists(select 1 from advert_reports where advert_id=$1 and resolved_at is null
+		and (reporter_id=$2 or (reporter_id is null and reporter_ip=$3)))`,
+		rep.AdvertID.String(), uuidPtrToDB(rep.ReporterID), reporterIPToDB(&rep))
+	if err != nil {
+		return false, fmt.Errorf("checking if report exists failed %w", err)
+	}
+	return exists == "true", nil
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain/report"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal"
+	internalAdvert "github.com/ukrainian-brothers/board-backend/internal/advert"
+	"github.com/ukrainian-brothers/board-backend/internal/common"
+	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
+	"testing"
+)
+
+func TestReportPostgres(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresReportRepository(db)
+	internalAdvert.NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("reporter"))
+	advertDB := internalAdvert.GenerateTestAdvertDB(uuid_("reported_advert"), uuid_("reporter"))
+	require.NoError(t, db.Insert(&userDB, &advertDB))
+	defer func() {
+		// reports should be removed due to fk policy
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	reporter := &user.User{ID: userDB.ID}
+	moderator := &user.User{ID: uuid.New(), Role: user.RoleModerator}
+
+	anonymous, err := report.NewReport(advertDB.ID, nil, "203.0.113.7", report.ReasonScam, "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, anonymous))
+
+	byUser, err := report.NewReport(advertDB.ID, reporter, "203.0.113.7", report.ReasonOther, "fake phone number")
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, byUser))
+
+	reported, err := repo.HasOpen(ctx, *byUser)
+	assert.NoError(t, err)
+	assert.True(t, reported)
+
+	sameAddress, err := report.NewReport(advertDB.ID, nil, "203.0.113.7", report.ReasonSpam, "")
+	require.NoError(t, err)
+	reported, err = repo.HasOpen(ctx, *sameAddress)
+	assert.NoError(t, err)
+	assert.True(t, reported)
+
+	otherAddress, err := report.NewReport(advertDB.ID, nil, "198.51.100.1", report.ReasonSpam, "")
+	require.NoError(t, err)
+	reported, err = repo.HasOpen(ctx, *otherAddress)
+	assert.NoError(t, err)
+	assert.False(t, reported)
+
+	// the duplicate stored before the check e.g. by concurrent requests doesn't count as another reporter
+	require.NoError(t, repo.Add(ctx, sameAddress))
+	count, err := repo.CountOpenReporters(ctx, advertDB.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.NoError(t, sameAddress.Resolve(moderator, report.ResolutionDismissed))
+	require.NoError(t, repo.Resolve(ctx, sameAddress))
+
+	stored, err := repo.Get(ctx, byUser.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, byUser.ReporterID, stored.ReporterID)
+	assert.Equal(t, "fake phone number", stored.Details)
+
+	_, err = repo.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, report.ReportNotFound)
+
+	require.NoError(t, stored.Resolve(moderator, report.ResolutionUpheld))
+	assert.NoError(t, repo.Resolve(ctx, &stored))
+	assert.ErrorIs(t, repo.Resolve(ctx, &stored), report.AlreadyResolvedErr)
+
+	reported, err = repo.HasOpen(ctx, *byUser)
+	assert.NoError(t, err)
+	assert.False(t, reported)
+
+	count, err = repo.CountOpenReporters(ctx, advertDB.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	open, err := repo.GetList(ctx, report.ListFilter{AdvertID: &advertDB.ID, OnlyOpen: true, Limit: 10})
+	assert.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, anonymous.ID, open[0].ID)
+	assert.Nil(t, open[0].ReporterID)
+	assert.Equal(t, "203.0.113.7", open[0].ReporterIP)
+
+	all, err := repo.GetList(ctx, report.ListFilter{AdvertID: &advertDB.ID, Limit: 10})
+	assert.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Empty(t, all[1].ReporterIP)
+	assert.Equal(t, report.ResolutionUpheld, all[1].Resolution)
+	assert.Equal(t, &moderator.ID, all[1].ResolvedBy)
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	"sync"
+)
+
+// LogNotifier writes digests to the log, it's used until users can choose how they want to be notified
+type LogNotifier struct {
+	log *logrus.Entry
+}
+
+func NewLogNotifier(log *logrus.Entry) *LogNotifier {
+	return &LogNotifier{log: log}
+}
+
+func (n LogNotifier) Notify(ctx context.Context, digest search.Digest) error {
+	n.log.WithFields(logrus.Fields{
+		"user_id": digest.UserID,
+		"adverts": digest.AdvertIDs(),
+	}).Info("new adverts matching saved searches")
+	return nil
+}
+
+// MemoryNotifier keeps sent digests, so tests can check what would be delivered
+type MemoryNotifier struct {
+	mu      sync.Mutex
+	digests []search.Digest
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (n *MemoryNotifier) Notify(ctx context.Context, digest search.Digest) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.digests = append(n.digests, digest)
+	return nil
+}
+
+func (n *MemoryNotifier) Digests() []search.Digest {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]search.Digest{}, n.digests...)
+}
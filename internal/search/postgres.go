@@ -0,0 +1,236 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-gorp/gorp"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"time"
+)
+
+type PostgresSearchRepository struct {
+	db *gorp.DbMap
+}
+
+func NewPostgresSearchRepository(db *gorp.DbMap) *PostgresSearchRepository {
+	db.AddTableWithName(SavedSearchDB{}, "saved_searches").SetKeys(false, "id")
+	db.AddTableWithName(MatchDB{}, "saved_search_matches").SetKeys(false, "search_id", "advert_id")
+
+	return &PostgresSearchRepository{
+		db: db,
+	}
+}
+
+type SavedSearchDB struct {
+	ID        uuid.UUID      `db:"id"`
+	UserID    uuid.UUID      `db:"user_id"`
+	Types     pq.StringArray `db:"types"`
+	Languages pq.StringArray `db:"languages"`
+	City      string         `db:"city"`
+	Region    string         `db:"region"`
+	Latitude  *float64       `db:"latitude"`
+	Longitude *float64       `db:"longitude"`
+	RadiusKm  float64        `db:"radius_km"`
+	Query     string         `db:"query"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func savedSearchToDB(s *search.SavedSearch) SavedSearchDB {
+	searchDB := SavedSearchDB{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		Types:     pq.StringArray{},
+		Languages: pq.StringArray(s.Criteria.Languages.Strings()),
+		City:      s.Criteria.City,
+		Region:    s.Criteria.Region,
+		RadiusKm:  s.Criteria.RadiusKm,
+		Query:     s.Criteria.Query,
+		CreatedAt: s.CreatedAt,
+	}
+	if searchDB.Languages == nil {
+		searchDB.Languages = pq.StringArray{}
+	}
+	for _, advertType := range s.Criteria.Types {
+		searchDB.Types = append(searchDB.Types, string(advertType))
+	}
+	if s.Criteria.Near != nil {
+		searchDB.Latitude = &s.Criteria.Near.Latitude
+		searchDB.Longitude = &s.Criteria.Near.Longitude
+	}
+	return searchDB
+}
+
+func (s SavedSearchDB) SavedSearch() search.SavedSearch {
+	savedSearch := search.SavedSearch{
+		ID:     s.ID,
+		UserID: s.UserID,
+		Criteria: search.Criteria{
+			Languages: LanguageTags{}.FromStrings(s.Languages),
+			City:      s.City,
+			Region:    s.Region,
+			RadiusKm:  s.RadiusKm,
+			Query:     s.Query,
+		},
+		CreatedAt: s.CreatedAt,
+	}
+	for _, advertType := range s.Types {
+		savedSearch.Criteria.Types = append(savedSearch.Criteria.Types, domain.AdvertType(advertType))
+	}
+	if s.Latitude != nil && s.Longitude != nil {
+		savedSearch.Criteria.Near = &domain.Coordinates{Latitude: *s.Latitude, Longitude: *s.Longitude}
+	}
+	return savedSearch
+}
+
+type MatchDB struct {
+	SearchID   uuid.UUID  `db:"search_id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	AdvertID   uuid.UUID  `db:"advert_id"`
+	CreatedAt  time.Time  `db:"created_at"`
+	NotifiedAt *time.Time `db:"notified_at"`
+}
+
+func (repo PostgresSearchRepository) Get(ctx context.Context, id uuid.UUID) (search.SavedSearch, error) {
+	var searchDB SavedSearchDB
+	err := repo.db.WithContext(ctx).SelectOne(&searchDB, "SELECT * FROM saved_searches WHERE id=$1", id.String())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return search.SavedSearch{}, search.SearchNotFound
+		}
+		return search.SavedSearch{}, fmt.Errorf("getting saved search failed while selecting from db %w", err)
+	}
+	return searchDB.SavedSearch(), nil
+}
+
+func (repo PostgresSearchRepository) GetList(ctx context.Context, userID uuid.UUID) ([]search.SavedSearch, error) {
+	return repo.selectSearches(ctx, "SELECT * FROM saved_searches WHERE user_id=$1 ORDER BY created_at, id", userID.String())
+}
+
+func (repo PostgresSearchRepository) GetCandidates(ctx context.Context, advertType domain.AdvertType, languages LanguageTags) ([]search.SavedSearch, error) {
+	return repo.selectSearches(ctx, `
+	SELECT * FROM saved_searches
+	WHERE (cardinality(types) = 0 OR $1 = ANY(types)) AND (cardinality(languages) = 0 OR languages && $2::varchar[])`,
+		string(advertType), pq.Array(languages.Strings()))
+}
+
+func (repo PostgresSearchRepository) selectSearches(ctx context.Context, query string, args ...interface{}) ([]search.SavedSearch, error) {
+	var searchesDB []SavedSearchDB
+	_, err := repo.db.WithContext(ctx).Select(&searchesDB, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting saved searches: %w", err)
+	}
+
+	var searches []search.SavedSearch
+	for _, searchDB := range searchesDB {
+		searches = append(searches, searchDB.SavedSearch())
+	}
+	return searches, nil
+}
+
+func (repo PostgresSearchRepository) Add(ctx context.Context, s *search.SavedSearch) error {
+	searchDB := savedSearchToDB(s)
+	err := repo.db.WithContext(ctx).Insert(&searchDB)
+	if err != nil {
+		return fmt.Errorf("adding saved search failed while performing sql %w", err)
+	}
+	return nil
+}
+
+// Delete removes the search with its pending matches due to fk policy
+func (repo PostgresSearchRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := repo.db.WithContext(ctx).Exec("DELETE FROM saved_searches WHERE id=$1", id.String())
+	if err != nil {
+		return fmt.Errorf("deleting saved search failed while performing sql %w", err)
+	}
+	return nil
+}
+
+func (repo PostgresSearchRepository) Count(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := repo.db.WithContext(ctx).SelectInt("SELECT count(*) FROM saved_searches WHERE user_id=$1", userID.String())
+	if err != nil {
+		return 0, fmt.Errorf("counting saved searches failed %w", err)
+	}
+	return int(count), nil
+}
+
+func (repo PostgresSearchRepository) AddMatches(ctx context.Context, matches []search.Match) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	trans, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed creating transaction for adding matches: %w", err)
+	}
+
+	sqlExec := trans.WithContext(ctx)
+	for _, match := range matches {
+		_, err = sqlExec.Exec(`
+		INSERT INTO saved_search_matches (search_id, user_id, advert_id, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (search_id, advert_id) DO NOTHING`,
+			match.SearchID.String(), match.UserID.String(), match.AdvertID.String(), match.CreatedAt)
+		if err != nil {
+			_ = trans.Rollback()
+			return fmt.Errorf("adding match failed while performing sql %w", err)
+		}
+	}
+
+	err = trans.Commit()
+	if err != nil {
+		return fmt.Errorf("failed committing added matches: %w", err)
+	}
+	return nil
+}
+
+func (repo PostgresSearchRepository) GetPendingMatches(ctx context.Context, limit int) ([]search.Match, error) {
+	var matchesDB []MatchDB
+	_, err := repo.db.WithContext(ctx).Select(&matchesDB, `
+	SELECT saved_search_matches.* FROM saved_search_matches
+	JOIN adverts ON adverts.id = saved_search_matches.advert_id
+	WHERE saved_search_matches.notified_at IS NULL
+	AND adverts.status = 'published' AND adverts.destroyed_at IS NULL AND adverts.archived_at IS NULL AND adverts.expires_at > now()
+	ORDER BY saved_search_matches.created_at, saved_search_matches.search_id, saved_search_matches.advert_id
+	LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed selecting pending matches: %w", err)
+	}
+
+	var matches []search.Match
+	for _, matchDB := range matchesDB {
+		matches = append(matches, search.Match{
+			SearchID:  matchDB.SearchID,
+			UserID:    matchDB.UserID,
+			AdvertID:  matchDB.AdvertID,
+			CreatedAt: matchDB.CreatedAt,
+		})
+	}
+	return matches, nil
+}
+
+func (repo PostgresSearchRepository) MarkNotified(ctx context.Context, matches []search.Match, notifiedAt time.Time) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var searchIDs, advertIDs []string
+	for _, match := range matches {
+		searchIDs = append(searchIDs, match.SearchID.String())
+		advertIDs = append(advertIDs, match.AdvertID.String())
+	}
+
+	_, err := repo.db.WithContext(ctx).Exec(`
+	UPDATE saved_search_matches SET notified_at=$1
+	FROM unnest($2::varchar[], $3::varchar[]) AS notified (search_id, advert_id)
+	WHERE saved_search_matches.search_id = notified.search_id AND saved_search_matches.advert_id = notified.advert_id`,
+		notifiedAt, pq.Array(searchIDs), pq.Array(advertIDs))
+	if err != nil {
+		return fmt.Errorf("marking matches as notified failed while performing sql %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukrainian-brothers/board-backend/domain"
+	"github.com/ukrainian-brothers/board-backend/domain/advert"
+	"github.com/ukrainian-brothers/board-backend/domain/search"
+	"github.com/ukrainian-brothers/board-backend/domain/user"
+	"github.com/ukrainian-brothers/board-backend/internal"
+	internalAdvert "github.com/ukrainian-brothers/board-backend/internal/advert"
+	"github.com/ukrainian-brothers/board-backend/internal/common"
+	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
+	"testing"
+	"time"
+)
+
+func TestSearchPostgres(t *testing.T) {
+	cfg := internal.GetTestConfig(t)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := NewPostgresSearchRepository(db)
+	internalAdvert.NewPostgresAdvertRepository(db)
+	db.AddTableWithName(internalUser.UserDB{}, "users").SetKeys(false, "id")
+
+	uuid_ := internal.HumanFriendlyUUID
+	userDB := internalUser.GenerateTestUserDB(uuid_("search_user"))
+	advertDB := internalAdvert.GenerateTestAdvertDB(uuid_("search_advert"), uuid_("search_user"))
+	require.NoError(t, db.Insert(&userDB, &advertDB))
+	defer func() {
+		// searches and matches should be removed due to fk policy
+		_, err := db.Exec("DELETE FROM adverts WHERE id=$1", advertDB.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("DELETE FROM users WHERE id=$1", userDB.ID)
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	usr := &user.User{ID: userDB.ID}
+
+	anyType, err := search.NewSavedSearch(usr, search.Criteria{
+		Languages: LanguageTags{Polish},
+		Near:      &domain.Coordinates{Latitude: 50.45, Longitude: 30.52},
+		RadiusKm:  25,
+		Query:     "flat",
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Add(ctx, anyType))
+
+	jobs, err := search.NewSavedSearch(usr, search.Criteria{Types: []domain.AdvertType{domain.AdvertTypeJob}, City: "Kyiv"})
+	require.NoError(t, err)
+	jobs.CreatedAt = anyType.CreatedAt.Add(time.Second)
+	require.NoError(t, repo.Add(ctx, jobs))
+
+	stored, err := repo.Get(ctx, anyType.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, anyType.Criteria, stored.Criteria)
+
+	_, err = repo.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, search.SearchNotFound)
+
+	searches, err := repo.GetList(ctx, usr.ID)
+	assert.NoError(t, err)
+	require.Len(t, searches, 2)
+	assert.Equal(t, jobs.Criteria, searches[1].Criteria)
+
+	count, err := repo.Count(ctx, usr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	candidates, err := repo.GetCandidates(ctx, domain.AdvertTypeTransport, LanguageTags{Polish, English})
+	assert.NoError(t, err)
+	assert.Contains(t, searchIDs(candidates), anyType.ID)
+	assert.NotContains(t, searchIDs(candidates), jobs.ID)
+
+	candidates, err = repo.GetCandidates(ctx, domain.AdvertTypeJob, LanguageTags{English})
+	assert.NoError(t, err)
+	assert.NotContains(t, searchIDs(candidates), anyType.ID)
+	assert.Contains(t, searchIDs(candidates), jobs.ID)
+
+	adv := &advert.Advert{ID: advertDB.ID}
+	match := search.NewMatch(*anyType, adv)
+	require.NoError(t, repo.AddMatches(ctx, []search.Match{match, search.NewMatch(*jobs, adv)}))
+	// matching the same advert again is ignored
+	require.NoError(t, repo.AddMatches(ctx, []search.Match{match}))
+
+	pending, err := repo.GetPendingMatches(ctx, 1000)
+	assert.NoError(t, err)
+	assert.Len(t, userMatches(pending, usr.ID), 2)
+
+	// matches of the suspended advert wait until it's published again
+	_, err = db.Exec("UPDATE adverts SET status=$1 WHERE id=$2", advert.StatusSuspended, advertDB.ID)
+	require.NoError(t, err)
+	pending, err = repo.GetPendingMatches(ctx, 1000)
+	assert.NoError(t, err)
+	assert.Empty(t, userMatches(pending, usr.ID))
+
+	_, err = db.Exec("UPDATE adverts SET status=$1 WHERE id=$2", advert.StatusPublished, advertDB.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.MarkNotified(ctx, []search.Match{match}, time.Now()))
+	pending, err = repo.GetPendingMatches(ctx, 1000)
+	assert.NoError(t, err)
+	require.Len(t, userMatches(pending, usr.ID), 1)
+	assert.Equal(t, jobs.ID, userMatches(pending, usr.ID)[0].SearchID)
+
+	require.NoError(t, repo.Delete(ctx, jobs.ID))
+	pending, err = repo.GetPendingMatches(ctx, 1000)
+	assert.NoError(t, err)
+	assert.Empty(t, userMatches(pending, usr.ID))
+}
+
+func searchIDs(searches []search.SavedSearch) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, s := range searches {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// userMatches skips matches left by other tests sharing the database
+func userMatches(matches []search.Match, userID uuid.UUID) []search.Match {
+	var filtered []search.Match
+	for _, match := range matches {
+		if match.UserID == userID {
+			filtered = append(filtered, match)
+		}
+	}
+	return filtered
+}
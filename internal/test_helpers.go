@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func GetTestConfig(t *testing.T) *common.Config {
+func GetTestConfig(t testing.TB) *common.Config {
 	cfg, err := common.NewConfigFromFile("../../config/configuration.test.local.json")
 	assert.NoError(t, err)
 	return cfg
@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+	translation "github.com/ukrainian-brothers/board-backend/pkg/translation"
+
 	user "github.com/ukrainian-brothers/board-backend/domain/user"
 
 	uuid "github.com/google/uuid"
@@ -110,3 +112,17 @@ func (_m *RepositoryMock) GetByLogin(ctx context.Context, login string) (*user.U
 
 	return r0, r1
 }
+
+// UpdateLanguage provides a mock function with given fields: ctx, id, language
+func (_m *RepositoryMock) UpdateLanguage(ctx context.Context, id uuid.UUID, language translation.LanguageTag) error {
+	ret := _m.Called(ctx, id, language)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, translation.LanguageTag) error); ok {
+		r0 = rf(ctx, id, language)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ukrainian-brothers/board-backend/domain"
 	"github.com/ukrainian-brothers/board-backend/domain/user"
+	. "github.com/ukrainian-brothers/board-backend/pkg/translation"
 )
 
 type PostgresUserRepository struct {
@@ -14,13 +15,16 @@ type PostgresUserRepository struct {
 }
 
 type UserDB struct {
-	ID          uuid.UUID `db:"id"`
-	Login       string    `db:"login"`
-	Password    *string   `db:"password"`
-	FirstName   string    `db:"name"`
-	Surname     string    `db:"surname"`
-	Mail        *string   `db:"mail"`
-	PhoneNumber *string   `db:"phone_number"`
+	ID          uuid.UUID   `db:"id"`
+	Login       string      `db:"login"`
+	Password    *string     `db:"password"`
+	FirstName   string      `db:"name"`
+	Surname     string      `db:"surname"`
+	Mail        *string     `db:"mail"`
+	PhoneNumber *string     `db:"phone_number"`
+	Role        user.Role   `db:"role"`
+	Trusted     bool        `db:"trusted"`
+	Language    LanguageTag `db:"language"`
 }
 
 func (usrDB *UserDB) LoadUser(usr *user.User) {
@@ -31,6 +35,9 @@ func (usrDB *UserDB) LoadUser(usr *user.User) {
 	usrDB.Surname = usr.Person.Surname
 	usrDB.Mail = usr.ContactDetails.Mail
 	usrDB.PhoneNumber = usr.ContactDetails.PhoneNumber
+	usrDB.Role = usr.Role
+	usrDB.Trusted = usr.Trusted
+	usrDB.Language = usr.Language
 }
 
 func NewPostgresUserRepository(db *gorp.DbMap) *PostgresUserRepository {
@@ -43,7 +50,7 @@ func (repo PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 
 	var usr UserDB
 	err := sqlExecutor.SelectOne(&usr, `
-	SELECT  login, id, password, name, surname, mail, phone_number FROM users
+	SELECT  login, id, password, name, surname, mail, phone_number, role, trusted, language FROM users
 	WHERE id=$1`, id)
 	if err != nil {
 		return nil, fmt.Errorf("GetByID failed while selecting user %w", err)
@@ -61,6 +68,9 @@ func (repo PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 			Mail:        usr.Mail,
 			PhoneNumber: usr.PhoneNumber,
 		},
+		Role:     usr.Role,
+		Trusted:  usr.Trusted,
+		Language: usr.Language,
 	}, err
 }
 
@@ -85,6 +95,9 @@ func (repo PostgresUserRepository) GetByLogin(ctx context.Context, login string)
 			Mail:        usr.Mail,
 			PhoneNumber: usr.PhoneNumber,
 		},
+		Role:     usr.Role,
+		Trusted:  usr.Trusted,
+		Language: usr.Language,
 	}, nil
 }
 
@@ -97,6 +110,9 @@ func (repo PostgresUserRepository) Add(ctx context.Context, user *user.User) err
 		Surname:     user.Person.Surname,
 		Mail:        user.ContactDetails.Mail,
 		PhoneNumber: user.ContactDetails.PhoneNumber,
+		Role:        user.Role,
+		Trusted:     user.Trusted,
+		Language:    user.Language,
 	}
 	repo.db.WithContext(ctx)
 	err := repo.db.Insert(&userDB)
@@ -123,3 +139,12 @@ func (repo PostgresUserRepository) Delete(ctx context.Context, id uuid.UUID) err
 	}
 	return nil
 }
+
+func (repo PostgresUserRepository) UpdateLanguage(ctx context.Context, id uuid.UUID, language LanguageTag) error {
+	sqlExecutor := repo.db.WithContext(ctx)
+	_, err := sqlExecutor.Exec(`UPDATE users SET language=$1 WHERE id=$2`, language, id)
+	if err != nil {
+		return fmt.Errorf("updating user language failed %w", err)
+	}
+	return nil
+}
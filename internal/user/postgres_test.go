@@ -10,6 +10,7 @@ import (
 	"github.com/ukrainian-brothers/board-backend/domain/user"
 	"github.com/ukrainian-brothers/board-backend/internal/common"
 	internalUser "github.com/ukrainian-brothers/board-backend/internal/user"
+	"github.com/ukrainian-brothers/board-backend/pkg/translation"
 	"testing"
 )
 
@@ -273,3 +274,27 @@ func TestUserExists(t *testing.T) {
 		})
 	}
 }
+
+func TestUserPostgresUpdateLanguage(t *testing.T) {
+	cfg, err := common.NewConfigFromFile("../../config/configuration.test.local.json")
+	assert.NoError(t, err)
+
+	db, err := common.InitPostgres(&cfg.Postgres)
+	require.NoError(t, err)
+
+	repo := internalUser.NewPostgresUserRepository(db)
+
+	usr := internalUser.CreateTestUser(t, "language_login", repo)
+	defer internalUser.RemoveTestUser(t, usr.ID, repo)
+
+	err = repo.UpdateLanguage(context.Background(), usr.ID, translation.Ukrainian)
+	require.NoError(t, err)
+
+	updated, err := repo.GetByLogin(context.Background(), usr.Login)
+	require.NoError(t, err)
+	assert.Equal(t, translation.Ukrainian, updated.Language)
+
+	updated, err = repo.GetByID(context.Background(), usr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, translation.Ukrainian, updated.Language)
+}
@@ -48,6 +48,9 @@ func CreateTestSession(t *testing.T, usr *user.User, store sessions.Store) []*ht
 	assert.NoError(t, err)
 
 	session.Values["user_login"] = usr.Login
+	if usr.Language != "" {
+		session.Values["user_language"] = string(usr.Language)
+	}
 	w := httptest.NewRecorder()
 	err = session.Save(r, w)
 	assert.NoError(t, err)
@@ -0,0 +1,21 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	NotFoundErr   = errors.New("blob not found")
+	InvalidKeyErr = errors.New("invalid blob key")
+)
+
+// Storage keeps binary objects like images under slash separated keys, e.g. "adverts/<advert_id>/<image_id>"
+type Storage interface {
+	Put(ctx context.Context, key string, content io.Reader) error
+	// Get returns NotFoundErr if there is no blob under the key, the reader has to be closed by the caller
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob, missing blobs are ignored
+	Delete(ctx context.Context, key string) error
+}
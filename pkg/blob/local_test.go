@@ -0,0 +1,34 @@
+package blob
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Put(ctx, "adverts/a/b", strings.NewReader("content")))
+
+	reader, err := storage.Get(ctx, "adverts/a/b")
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, "content", string(content))
+
+	assert.NoError(t, storage.Delete(ctx, "adverts/a/b"))
+	assert.NoError(t, storage.Delete(ctx, "adverts/a/b"))
+	_, err = storage.Get(ctx, "adverts/a/b")
+	assert.ErrorIs(t, err, NotFoundErr)
+
+	for _, key := range []string{"", "../secret", "adverts/../../secret", "/adverts", "adverts/", "adverts\\a"} {
+		assert.ErrorIs(t, storage.Put(ctx, key, strings.NewReader("x")), InvalidKeyErr, key)
+	}
+}
@@ -0,0 +1,95 @@
+package clientip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var InvalidProxyErr = errors.New("invalid trusted proxy")
+
+// DefaultHeader is used when no header is configured, proxies append the address of the peer they got the request from
+const DefaultHeader = "X-Forwarded-For"
+
+// Resolver finds the address of the client behind reverse proxies. The header is read only for requests coming from
+// trusted proxies, otherwise anybody could pick the address by sending the header on their own.
+type Resolver struct {
+	trusted []*net.IPNet
+	header  string
+}
+
+// NewResolver creates resolver trusting the proxies given as IP addresses or CIDR ranges, without any proxy
+// the client is always the peer of the connection.
+func NewResolver(trustedProxies []string, header string) (Resolver, error) {
+	if header == "" {
+		header = DefaultHeader
+	}
+
+	resolver := Resolver{header: header}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return Resolver{}, fmt.Errorf("%w: %q", InvalidProxyErr, proxy)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 8 * net.IPv6len
+			}
+			proxy = fmt.Sprintf("%s/%d", ip, bits)
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return Resolver{}, fmt.Errorf("%w: %q", InvalidProxyErr, proxy)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+// ClientIP returns the address of the client. The addresses in the header are walked from the closest one,
+// the first one which isn't a trusted proxy is the client, so the client can't spoof the address by prepending one.
+func (r Resolver) ClientIP(req *http.Request) string {
+	peer, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		peer = req.RemoteAddr
+	}
+	if !r.isTrusted(peer) {
+		return peer
+	}
+
+	var forwarded []string
+	for _, value := range req.Header.Values(r.header) {
+		forwarded = append(forwarded, strings.Split(value, ",")...)
+	}
+
+	client := peer
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(address) == nil {
+			// the proxy is trusted but the chain is broken, the last valid address is the best guess
+			break
+		}
+		client = address
+		if !r.isTrusted(address) {
+			break
+		}
+	}
+	return client
+}
+
+func (r Resolver) isTrusted(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package clientip
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewResolver(t *testing.T) {
+	_, err := NewResolver([]string{"10.0.0.1", "192.168.0.0/16", "::1"}, "")
+	assert.NoError(t, err)
+
+	_, err = NewResolver([]string{"proxy.local"}, "")
+	assert.ErrorIs(t, err, InvalidProxyErr)
+
+	_, err = NewResolver([]string{"10.0.0.0/33"}, "")
+	assert.ErrorIs(t, err, InvalidProxyErr)
+}
+
+func TestResolverClientIP(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.1", "192.168.0.0/16"}, "")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		resolver   Resolver
+		remoteAddr string
+		headers    []string
+		expected   string
+	}{
+		{name: "no proxy", resolver: resolver, remoteAddr: "203.0.113.7:4000", expected: "203.0.113.7"},
+		{name: "header from untrusted peer", resolver: resolver, remoteAddr: "203.0.113.7:4000", headers: []string{"198.51.100.1"}, expected: "203.0.113.7"},
+		{name: "trusted proxy", resolver: resolver, remoteAddr: "10.0.0.1:4000", headers: []string{"198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "chain of trusted proxies", resolver: resolver, remoteAddr: "10.0.0.1:4000", headers: []string{"198.51.100.1, 192.168.1.5"}, expected: "198.51.100.1"},
+		{name: "spoofed address prepended by the client", resolver: resolver, remoteAddr: "10.0.0.1:4000", headers: []string{"1.2.3.4, 198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "repeated header", resolver: resolver, remoteAddr: "10.0.0.1:4000", headers: []string{"1.2.3.4", "198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "trusted proxy without header", resolver: resolver, remoteAddr: "10.0.0.1:4000", expected: "10.0.0.1"},
+		{name: "broken chain", resolver: resolver, remoteAddr: "10.0.0.1:4000", headers: []string{"unknown, 192.168.1.5"}, expected: "192.168.1.5"},
+		{name: "nothing trusted", resolver: Resolver{header: DefaultHeader}, remoteAddr: "10.0.0.1:4000", headers: []string{"198.51.100.1"}, expected: "10.0.0.1"},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tC.remoteAddr
+			for _, header := range tC.headers {
+				req.Header.Add(DefaultHeader, header)
+			}
+			assert.Equal(t, tC.expected, tC.resolver.ClientIP(req))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+var (
+	UnsupportedFormatErr = errors.New("unsupported image format")
+	TooManyPixelsErr     = errors.New("image dimensions are too large")
+)
+
+const (
+	// MaxPixels protects against decompression bombs, small files can declare huge dimensions
+	MaxPixels = 40_000_000
+	// ThumbnailSize is the maximal width and height of the thumbnail
+	ThumbnailSize = 400
+	jpegQuality   = 85
+)
+
+// supportedFormats are names under which the decoders are registered in the image package
+var supportedFormats = map[string]bool{"jpeg": true, "png": true, "webp": true}
+
+type Processed struct {
+	ContentType string
+	Width       int
+	Height      int
+	Image       []byte
+	Thumbnail   []byte
+}
+
+type opaquer interface {
+	Opaque() bool
+}
+
+// Process decodes the uploaded JPEG, PNG or WebP image and encodes it again together with its thumbnail.
+// Encoders don't write any metadata, so EXIF data like GPS position and camera serial number is dropped,
+// the EXIF orientation is applied to the pixels before that. Images with transparency are stored as PNG, the rest as JPEG.
+func Process(content []byte) (Processed, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || !supportedFormats[format] {
+		return Processed{}, UnsupportedFormatErr
+	}
+
+	if cfg.Width*cfg.Height > MaxPixels {
+		return Processed{}, TooManyPixelsErr
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return Processed{}, fmt.Errorf("%w: %s", UnsupportedFormatErr, err)
+	}
+
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(content))
+	}
+
+	encode := encodeJPEG
+	contentType := "image/jpeg"
+	if o, ok := img.(opaquer); format == "png" || (ok && !o.Opaque()) {
+		encode = png.Encode
+		contentType = "image/png"
+	}
+
+	processed := Processed{
+		ContentType: contentType,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+
+	processed.Image, err = encodeToBytes(encode, img)
+	if err != nil {
+		return Processed{}, err
+	}
+
+	processed.Thumbnail, err = encodeToBytes(encode, thumbnail(img))
+	if err != nil {
+		return Processed{}, err
+	}
+	return processed, nil
+}
+
+func encodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+func encodeToBytes(encode func(w io.Writer, img image.Image) error, img image.Image) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := encode(&buf, img)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// thumbnail scales the image down to fit within ThumbnailSize keeping its aspect ratio, smaller images are not enlarged
+func thumbnail(img image.Image) image.Image {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width <= ThumbnailSize && height <= ThumbnailSize {
+		return img
+	}
+
+	if width > height {
+		height = max(1, height*ThumbnailSize/width)
+		width = ThumbnailSize
+	} else {
+		width = max(1, width*ThumbnailSize/height)
+		height = ThumbnailSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,153 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// twoColorImage is red on the left half and blue on the right one
+func twoColorImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withExif inserts EXIF segment with the orientation and GPS position right after the start of the JPEG
+func withExif(t *testing.T, content []byte, orientation uint16) []byte {
+	tiff := bytes.Buffer{}
+	order := binary.LittleEndian
+	tiff.WriteString("II")
+	for _, value := range []interface{}{
+		uint16(42), uint32(8), // header pointing to the first IFD
+		uint16(2),                                                         // number of entries
+		uint16(orientationTag), uint16(3), uint32(1), uint32(orientation), // orientation as short
+		uint16(0x8825), uint16(4), uint32(1), uint32(38), // pointer to GPS IFD
+		uint32(0), // no next IFD
+	} {
+		assert.NoError(t, binary.Write(&tiff, order, value))
+	}
+	tiff.WriteString("GPS 50.4501N 30.5234E")
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+
+	result := append([]byte{}, content[:2]...)
+	result = append(result, app1...)
+	result = append(result, segment...)
+	return append(result, content[2:]...)
+}
+
+func assertColor(t *testing.T, expected color.RGBA, actual color.Color) {
+	r, g, b, _ := actual.RGBA()
+	assert.InDelta(t, expected.R, r>>8, 40)
+	assert.InDelta(t, expected.G, g>>8, 40)
+	assert.InDelta(t, expected.B, b>>8, 40)
+}
+
+func TestProcessJPEG(t *testing.T) {
+	buf := bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(&buf, twoColorImage(800, 400), nil))
+	content := withExif(t, buf.Bytes(), 6)
+	assert.Equal(t, 6, exifOrientation(content))
+
+	processed, err := Process(content)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", processed.ContentType)
+	assert.NotContains(t, string(processed.Image), "Exif")
+	assert.NotContains(t, string(processed.Image), "GPS")
+	assert.Equal(t, 1, exifOrientation(processed.Image))
+
+	// rotated clockwise, so the red half is on the top now
+	img, err := jpeg.Decode(bytes.NewReader(processed.Image))
+	require.NoError(t, err)
+	assert.Equal(t, 400, processed.Width)
+	assert.Equal(t, 800, processed.Height)
+	assert.Equal(t, image.Rect(0, 0, 400, 800), img.Bounds())
+	assertColor(t, color.RGBA{R: 255}, img.At(200, 100))
+	assertColor(t, color.RGBA{B: 255}, img.At(200, 700))
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, ThumbnailSize/2, ThumbnailSize), thumbnail.Bounds())
+}
+
+func TestProcessPNG(t *testing.T) {
+	buf := bytes.Buffer{}
+	require.NoError(t, png.Encode(&buf, twoColorImage(100, 50)))
+
+	processed, err := Process(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", processed.ContentType)
+	assert.Equal(t, 100, processed.Width)
+	assert.Equal(t, 50, processed.Height)
+
+	// small images are not enlarged
+	thumbnail, err := png.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), thumbnail.Bounds())
+}
+
+func TestProcessInvalid(t *testing.T) {
+	_, err := Process([]byte("definitely not an image"))
+	assert.ErrorIs(t, err, UnsupportedFormatErr)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, gif.Encode(&buf, twoColorImage(10, 10), nil))
+	_, err = Process(buf.Bytes())
+	assert.ErrorIs(t, err, UnsupportedFormatErr)
+
+	// only the header is needed to reject the image, the pixels are never decoded
+	buf.Reset()
+	require.NoError(t, png.Encode(&buf, twoColorImage(10, 10)))
+	header := buf.Bytes()
+	binary.BigEndian.PutUint32(header[16:], 10000)
+	binary.BigEndian.PutUint32(header[20:], 10000)
+	binary.BigEndian.PutUint32(header[29:], crc32.ChecksumIEEE(header[12:29]))
+	_, err = Process(header)
+	assert.ErrorIs(t, err, TooManyPixelsErr)
+}
+
+func TestOrient(t *testing.T) {
+	img := twoColorImage(4, 2)
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+
+	testCases := []struct {
+		orientation int
+		bounds      image.Rectangle
+		// topLeft is the color of the top left pixel after the transformation
+		topLeft color.RGBA
+	}{
+		{orientation: 1, bounds: image.Rect(0, 0, 4, 2), topLeft: red},
+		{orientation: 2, bounds: image.Rect(0, 0, 4, 2), topLeft: blue},
+		{orientation: 3, bounds: image.Rect(0, 0, 4, 2), topLeft: blue},
+		{orientation: 4, bounds: image.Rect(0, 0, 4, 2), topLeft: red},
+		{orientation: 5, bounds: image.Rect(0, 0, 2, 4), topLeft: red},
+		{orientation: 6, bounds: image.Rect(0, 0, 2, 4), topLeft: red},
+		{orientation: 7, bounds: image.Rect(0, 0, 2, 4), topLeft: blue},
+		{orientation: 8, bounds: image.Rect(0, 0, 2, 4), topLeft: blue},
+	}
+
+	for _, tC := range testCases {
+		oriented := orient(img, tC.orientation)
+		assert.Equal(t, tC.bounds, oriented.Bounds(), tC.orientation)
+		assert.Equal(t, tC.topLeft, color.RGBAModel.Convert(oriented.At(0, 0)), tC.orientation)
+	}
+}
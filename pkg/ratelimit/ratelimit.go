@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type window struct {
+	start time.Time
+	hits  int
+}
+
+// Limiter is an in-memory fixed window limiter, it allows up to limit events for every key within the window.
+// It is meant for a single instance deployment, every instance keeps its own counters.
+type Limiter struct {
+	limit     int
+	window    time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+func NewLimiter(limit int, windowLength time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  windowLength,
+		now:     time.Now,
+		windows: map[string]*window{},
+	}
+}
+
+// Allow registers an event for the key and reports whether it fits within the limit
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.windows[key] = &window{start: now, hits: 1}
+		return true
+	}
+
+	if w.hits >= l.limit {
+		return false
+	}
+	w.hits++
+	return true
+}
+
+// sweep removes expired windows, so the map doesn't grow with every key ever seen
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
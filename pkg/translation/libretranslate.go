@@ -0,0 +1,80 @@
+package translation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// LibreTranslate is the Translator using self-hosted LibreTranslate or any service compatible with its /translate endpoint
+type LibreTranslate struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewLibreTranslate creates the translator for the service available at url, apiKey is optional
+func NewLibreTranslate(url string, apiKey string, client *http.Client) *LibreTranslate {
+	return &LibreTranslate{url: strings.TrimSuffix(url, "/"), apiKey: apiKey, client: client}
+}
+
+type libreTranslateRequest struct {
+	Text   string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText string `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+func (t LibreTranslate) Translate(ctx context.Context, text string, source LanguageTag, target LanguageTag) (string, error) {
+	supported := SupportedLanguages()
+	if !supported.Contains(source) || !supported.Contains(target) {
+		return "", UnsupportedLanguageErr
+	}
+
+	body, err := json.Marshal(libreTranslateRequest{
+		Text:   text,
+		Source: string(source),
+		Target: string(target),
+		Format: "text",
+		APIKey: t.apiKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed marshaling translation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed creating translation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed sending translation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	response := libreTranslateResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return "", fmt.Errorf("failed decoding translation response with status %d: %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// LibreTranslate responds with 400 to the language pair it doesn't support
+		if resp.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(response.Error), "language") {
+			return "", fmt.Errorf("%w: %s", UnsupportedLanguageErr, response.Error)
+		}
+		return "", fmt.Errorf("translation failed with status %d: %s", resp.StatusCode, response.Error)
+	}
+	return response.TranslatedText, nil
+}
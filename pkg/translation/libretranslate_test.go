@@ -0,0 +1,55 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLibreTranslate(t *testing.T) {
+	var requests []libreTranslateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/translate", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		request := libreTranslateRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+
+		switch request.Text {
+		case "crash":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error": "out of memory"}`))
+		case "bad pair":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "pl (Polish) is not available as a source language"}`))
+		default:
+			_, _ = w.Write([]byte(`{"translatedText": "кімната"}`))
+		}
+	}))
+	defer server.Close()
+
+	translator := NewLibreTranslate(server.URL+"/", "secret", server.Client())
+	ctx := context.Background()
+
+	translated, err := translator.Translate(ctx, "pokój", Polish, Ukrainian)
+	assert.NoError(t, err)
+	assert.Equal(t, "кімната", translated)
+	require.Len(t, requests, 1)
+	assert.Equal(t, libreTranslateRequest{Text: "pokój", Source: "pl", Target: "uk", Format: "text", APIKey: "secret"}, requests[0])
+
+	_, err = translator.Translate(ctx, "crash", Polish, English)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, UnsupportedLanguageErr)
+
+	_, err = translator.Translate(ctx, "bad pair", Polish, English)
+	assert.ErrorIs(t, err, UnsupportedLanguageErr)
+
+	_, err = translator.Translate(ctx, "pokój", Polish, "de")
+	assert.ErrorIs(t, err, UnsupportedLanguageErr)
+	assert.Len(t, requests, 3)
+}
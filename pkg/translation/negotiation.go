@@ -0,0 +1,71 @@
+package translation
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type weightedTag struct {
+	tag     LanguageTag
+	quality float64
+}
+
+// ParseAcceptLanguage returns the languages of Accept-Language header from the most preferred one.
+// Tags are read by ParseLanguageTag, e.g. pl-PL is read as pl, the wildcard, invalid tags and languages with q=0 are skipped.
+func ParseAcceptLanguage(header string) LanguageTags {
+	var weighted []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag, err := ParseLanguageTag(params[0])
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			nameValue := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(nameValue) != 2 || strings.TrimSpace(nameValue[0]) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(nameValue[1]), 64)
+			if err != nil {
+				// malformed weight makes the language the least preferred one rather than the most preferred
+				parsed = 0.001
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		weighted = append(weighted, weightedTag{tag: tag, quality: quality})
+	}
+
+	// languages of the same weight keep the order they were sent in
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+
+	var tags LanguageTags
+	for _, w := range weighted {
+		if !tags.Contains(w.tag) {
+			tags = append(tags, w.tag)
+		}
+	}
+	return tags
+}
+
+// Negotiate returns the supported languages from the preferred ones, followed by the fallback chain and the rest of supported languages,
+// so the text available in any supported language can be served
+func Negotiate(preferred LanguageTags, fallback LanguageTags) LanguageTags {
+	var chain LanguageTags
+	for _, tags := range []LanguageTags{preferred, fallback, SupportedLanguages()} {
+		for _, tag := range tags.Supported() {
+			if !chain.Contains(tag) {
+				chain = append(chain, tag)
+			}
+		}
+	}
+	return chain
+}
@@ -0,0 +1,43 @@
+package translation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected LanguageTags
+	}{
+		{header: "", expected: nil},
+		{header: "pl", expected: LanguageTags{Polish}},
+		{header: "en-US,en;q=0.9,pl;q=0.8", expected: LanguageTags{English, Polish}},
+		{header: "pl;q=0.5, ua;q=0.9, en", expected: LanguageTags{English, Ukrainian, Polish}},
+		{header: "de, PL-pl;q=0.7, *;q=0.5", expected: LanguageTags{"de", Polish}},
+		{header: "en;q=0, pl", expected: LanguageTags{Polish}},
+		{header: "en;q=abc, pl;q=0.1", expected: LanguageTags{Polish, English}},
+		{header: "ua; q=0.8, en; q=0.8", expected: LanguageTags{Ukrainian, English}},
+		{header: "uk-UA,uk;q=0.9,ua;q=0.8,en-US;q=0.7", expected: LanguageTags{Ukrainian, English}},
+		{header: "english, 12, pl", expected: LanguageTags{Polish}},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.header, func(t *testing.T) {
+			assert.Equal(t, tC.expected, ParseAcceptLanguage(tC.header))
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, LanguageTags{English, Ukrainian, Polish}, Negotiate(LanguageTags{"de", English}, DefaultFallback()))
+	assert.Equal(t, LanguageTags{Polish, English, Ukrainian}, Negotiate(nil, LanguageTags{Polish}))
+
+	chain := Negotiate(LanguageTags{Ukrainian}, LanguageTags{Polish, English})
+	lang, ok := MultilingualString{English: "room", Polish: "pokój"}.Best(chain)
+	assert.True(t, ok)
+	assert.Equal(t, Polish, lang)
+
+	_, ok = MultilingualString{English: ""}.Best(chain)
+	assert.False(t, ok)
+}
@@ -0,0 +1,130 @@
+package translation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var InvalidLanguageTagErr = errors.New("invalid language tag")
+
+// defaultAliases keeps the legacy codes working, "ua" was used for Ukrainian before the ISO 639-1 code was adopted
+var defaultAliases = map[LanguageTag]LanguageTag{
+	"ua": Ukrainian,
+}
+
+// Registry holds the languages adverts can be written in and the aliases of their codes
+type Registry struct {
+	languages LanguageTags
+	aliases   map[LanguageTag]LanguageTag
+}
+
+// NewRegistry creates the registry of languages given as BCP 47 tags, English, Polish and Ukrainian are used when no language is given.
+// Aliases are added to the default ones and have to point to the registered languages.
+func NewRegistry(languages []string, aliases map[string]string) (*Registry, error) {
+	registry := &Registry{aliases: map[LanguageTag]LanguageTag{}}
+	for alias, tag := range defaultAliases {
+		registry.aliases[alias] = tag
+	}
+	for alias, tag := range aliases {
+		aliasTag, err := primarySubtag(alias)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q: %w", alias, err)
+		}
+		canonical, err := primarySubtag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q of %q: %w", alias, tag, err)
+		}
+		registry.aliases[aliasTag] = canonical
+	}
+
+	if len(languages) == 0 {
+		languages = LanguageTags{English, Polish, Ukrainian}.Strings()
+	}
+	// languages are parsed like any other tag, so an alias registers the language it points to
+	for _, language := range languages {
+		tag, err := registry.Parse(language)
+		if err != nil {
+			return nil, fmt.Errorf("language %q: %w", language, err)
+		}
+		if !registry.languages.Contains(tag) {
+			registry.languages = append(registry.languages, tag)
+		}
+	}
+
+	for alias, tag := range registry.aliases {
+		// default aliases of not registered languages are simply unused
+		if _, isDefault := defaultAliases[alias]; !isDefault && !registry.languages.Contains(tag) {
+			return nil, fmt.Errorf("alias %q points to not registered language %q: %w", alias, tag, InvalidLanguageTagErr)
+		}
+	}
+	return registry, nil
+}
+
+// primarySubtag reads the language of BCP 47 tag, e.g. pl for pl-PL, the region, script and other subtags are dropped
+func primarySubtag(tag string) (LanguageTag, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	primary := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' })
+	if len(primary) == 0 || len(primary[0]) < 2 || len(primary[0]) > 3 {
+		return "", InvalidLanguageTagErr
+	}
+	for _, r := range primary[0] {
+		if r < 'a' || r > 'z' {
+			return "", InvalidLanguageTagErr
+		}
+	}
+	return LanguageTag(primary[0]), nil
+}
+
+// Parse returns the canonical code of BCP 47 tag, aliases are replaced by the languages they point to.
+// The language doesn't have to be registered, use Supported to check it.
+func (r *Registry) Parse(tag string) (LanguageTag, error) {
+	primary, err := primarySubtag(tag)
+	if err != nil {
+		return "", err
+	}
+	if canonical, ok := r.aliases[primary]; ok {
+		return canonical, nil
+	}
+	return primary, nil
+}
+
+func (r *Registry) Languages() LanguageTags {
+	return append(LanguageTags{}, r.languages...)
+}
+
+func (r *Registry) Supported(tag LanguageTag) bool {
+	return r.languages.Contains(tag)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = mustDefaultRegistry()
+)
+
+func mustDefaultRegistry() *Registry {
+	r, err := NewRegistry(nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Configure replaces the registry used by the package, it's meant to be called once at startup
+func Configure(r *Registry) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = r
+}
+
+func currentRegistry() *Registry {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry
+}
+
+// ParseLanguageTag returns the canonical code of BCP 47 tag using the configured aliases, e.g. uk for ua or uk-UA
+func ParseLanguageTag(tag string) (LanguageTag, error) {
+	return currentRegistry().Parse(tag)
+}
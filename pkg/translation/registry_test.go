@@ -0,0 +1,83 @@
+package translation
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	registry, err := NewRegistry([]string{"en", "pl-PL", "UK", "de", "cs_CZ", "ro", "ru"}, map[string]string{"cz": "cs"})
+	require.NoError(t, err)
+	assert.Equal(t, LanguageTags{English, Polish, Ukrainian, "de", "cs", "ro", "ru"}, registry.Languages())
+
+	testCases := []struct {
+		tag      string
+		expected LanguageTag
+		err      error
+	}{
+		{tag: "pl", expected: Polish},
+		{tag: " pl-PL ", expected: Polish},
+		{tag: "ua", expected: Ukrainian},
+		{tag: "uk-UA", expected: Ukrainian},
+		{tag: "cz", expected: "cs"},
+		{tag: "zh-Hant-TW", expected: "zh"},
+		{tag: "", err: InvalidLanguageTagErr},
+		{tag: "*", err: InvalidLanguageTagErr},
+		{tag: "english", err: InvalidLanguageTagErr},
+		{tag: "p1", err: InvalidLanguageTagErr},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.tag, func(t *testing.T) {
+			tag, err := registry.Parse(tC.tag)
+			assert.ErrorIs(t, err, tC.err)
+			assert.Equal(t, tC.expected, tag)
+		})
+	}
+
+	assert.True(t, registry.Supported("de"))
+	assert.False(t, registry.Supported("zh"))
+}
+
+func TestNewRegistryErrors(t *testing.T) {
+	_, err := NewRegistry([]string{"en", "x"}, nil)
+	assert.ErrorIs(t, err, InvalidLanguageTagErr)
+
+	_, err = NewRegistry(nil, map[string]string{"de-AT": "de"})
+	assert.ErrorIs(t, err, InvalidLanguageTagErr, "alias of not registered language")
+
+	registry, err := NewRegistry([]string{"en", "ua", "uk"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, LanguageTags{English, Ukrainian}, registry.Languages())
+
+	registry, err = NewRegistry(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, LanguageTags{English, Polish, Ukrainian}, registry.Languages())
+}
+
+func TestConfigure(t *testing.T) {
+	registry, err := NewRegistry([]string{"en", "de"}, nil)
+	require.NoError(t, err)
+	Configure(registry)
+	defer Configure(mustDefaultRegistry())
+
+	assert.Equal(t, LanguageTags{English, "de"}, SupportedLanguages())
+	assert.Equal(t, LanguageTags{"de"}, LanguageTags{"de", Polish}.Supported())
+
+	title := MultilingualString{"de": "Titel", Polish: "tytuł"}
+	title.RemoveUnsupported()
+	assert.Equal(t, MultilingualString{"de": "Titel"}, title)
+}
+
+func TestMultilingualStringUnmarshal(t *testing.T) {
+	title := MultilingualString{}
+	require.NoError(t, json.Unmarshal([]byte(`{"ua": "титул", "pl-PL": "tytuł", "en": "title", "not a tag": "x"}`), &title))
+	assert.Equal(t, MultilingualString{Ukrainian: "титул", Polish: "tytuł", English: "title"}, title)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"ua": "alias", "uk": "canonical"}`), &title))
+	assert.Equal(t, MultilingualString{Ukrainian: "canonical"}, title)
+
+	assert.Error(t, json.Unmarshal([]byte(`["en"]`), &title))
+	assert.Equal(t, LanguageTags{Ukrainian, Polish, ""}, LanguageTags{}.FromStrings([]string{"ua", "pl-PL", ""}))
+}
@@ -0,0 +1,81 @@
+package translation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var InvalidTextErr = errors.New("invalid multilingual text")
+
+// DecodeStrict decodes the text of the field sent by the client, unlike UnmarshalJSON it doesn't drop anything silently.
+// Texts are trimmed, invalid, unsupported and repeated languages, empty texts and texts longer than maxLength runes
+// are all reported at once as validation errors of field.language paths. Missing field or null gives empty text.
+func DecodeStrict(data []byte, field string, maxLength int) (MultilingualString, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return MultilingualString{}, nil
+	}
+
+	var raw map[string]json.RawMessage
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, validation.Errors{validation.NewFieldError(field, validation.InvalidFormatCode, InvalidTextErr)}
+	}
+
+	// tags are checked in order, so the errors and the choice between duplicates don't depend on the map order
+	var tags []string
+	for tag := range raw {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	registry := currentRegistry()
+	decoded := MultilingualString{}
+	var textErrors validation.Errors
+	for _, tag := range tags {
+		reject := func(code string) {
+			textErrors.Add(validation.NewFieldError(field+"."+tag, code, InvalidTextErr).With("language", tag))
+		}
+
+		lang, err := registry.Parse(tag)
+		if err != nil {
+			reject(validation.InvalidLanguageCode)
+			continue
+		}
+		if !registry.Supported(lang) {
+			reject(validation.UnsupportedLanguageCode)
+			continue
+		}
+		if _, ok := decoded[lang]; ok {
+			reject(validation.DuplicateLanguageCode)
+			continue
+		}
+
+		var text string
+		err = json.Unmarshal(raw[tag], &text)
+		if err != nil {
+			reject(validation.InvalidFormatCode)
+			continue
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			reject(validation.RequiredCode)
+			continue
+		}
+		if utf8.RuneCountInString(text) > maxLength {
+			textErrors.Add(validation.NewFieldError(field+"."+tag, validation.TooLongCode, InvalidTextErr).With("language", tag).With("max_length", maxLength))
+			continue
+		}
+		decoded[lang] = text
+	}
+
+	if err := textErrors.Err(); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
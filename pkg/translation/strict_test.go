@@ -0,0 +1,63 @@
+package translation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/ukrainian-brothers/board-backend/pkg/validation"
+	"strings"
+	"testing"
+)
+
+func rejectedLanguage(tag string, code string) validation.FieldError {
+	return validation.NewFieldError("title."+tag, code, InvalidTextErr).With("language", tag)
+}
+
+func TestDecodeStrict(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected MultilingualString
+		errors   validation.Errors
+	}{
+		{name: "missing", data: "", expected: MultilingualString{}},
+		{name: "null", data: "null", expected: MultilingualString{}},
+		{name: "trimmed", data: `{"en": " title ", "uk-UA": "титул"}`, expected: MultilingualString{English: "title", Ukrainian: "титул"}},
+		{name: "alias", data: `{"ua": "титул"}`, expected: MultilingualString{Ukrainian: "титул"}},
+		{name: "max length in runes", data: `{"uk": "` + strings.Repeat("ї", 5) + `"}`, expected: MultilingualString{Ukrainian: strings.Repeat("ї", 5)}},
+		{name: "not an object", data: `"title"`, errors: validation.Errors{validation.NewFieldError("title", validation.InvalidFormatCode, InvalidTextErr)}},
+		{
+			name: "all problems at once",
+			data: `{"en": "   ", "pl": "too long", "de": "Titel", "not a tag": "x", "ua": "титул", "uk": "титул", "pl-PL": 1}`,
+			errors: validation.Errors{
+				rejectedLanguage("de", validation.UnsupportedLanguageCode),
+				rejectedLanguage("en", validation.RequiredCode),
+				rejectedLanguage("not a tag", validation.InvalidLanguageCode),
+				rejectedLanguage("pl", validation.TooLongCode).With("max_length", 5),
+				rejectedLanguage("pl-PL", validation.InvalidFormatCode),
+				rejectedLanguage("uk", validation.DuplicateLanguageCode),
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			decoded, err := DecodeStrict([]byte(tC.data), "title", 5)
+			if tC.errors != nil {
+				assert.ErrorIs(t, err, InvalidTextErr)
+				assert.ErrorIs(t, err, validation.InvalidErr)
+				assert.Equal(t, tC.errors, err)
+				assert.Nil(t, decoded)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tC.expected, decoded)
+		})
+	}
+}
+
+func TestMarshalKeepsText(t *testing.T) {
+	title := MultilingualString{English: "title", "xx": "x"}
+	by, err := title.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"en": "title"}`, string(by))
+	assert.Len(t, title, 2)
+}
@@ -8,14 +8,27 @@ type LanguageTag string // ISO 639-1
 
 type LanguageTags []LanguageTag
 
+// FromStrings appends the canonical codes of the tags, e.g. uk for ua or uk-UA, invalid tags are kept as they are so Supported drops them
 func (l LanguageTags) FromStrings(tags []string) LanguageTags {
 	langTags := l
 	for _, tag := range tags {
-		langTags = append(langTags, LanguageTag(tag))
+		canonical, err := ParseLanguageTag(tag)
+		if err != nil {
+			canonical = LanguageTag(tag)
+		}
+		langTags = append(langTags, canonical)
 	}
 	return langTags
 }
 
+func (l LanguageTags) Strings() []string {
+	var tags []string
+	for _, tag := range l {
+		tags = append(tags, string(tag))
+	}
+	return tags
+}
+
 func (l LanguageTags) Empty() bool {
 	if l == nil {
 		return true
@@ -30,11 +43,42 @@ func (l LanguageTags) Empty() bool {
 const (
 	English   LanguageTag = "en"
 	Polish    LanguageTag = "pl"
-	Ukrainian LanguageTag = "ua"
+	Ukrainian LanguageTag = "uk"
 )
 
-var supportedLanguages = []LanguageTag{
-	English, Polish, Ukrainian,
+// defaultFallback is the order of languages served when none of the accepted ones is available
+var defaultFallback = []LanguageTag{
+	Ukrainian, Polish, English,
+}
+
+// SupportedLanguages returns the languages of the configured registry
+func SupportedLanguages() LanguageTags {
+	return currentRegistry().Languages()
+}
+
+func DefaultFallback() LanguageTags {
+	return append(LanguageTags{}, defaultFallback...)
+}
+
+func (l LanguageTags) Contains(tag LanguageTag) bool {
+	for _, lang := range l {
+		if lang == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Supported returns only the supported languages from the tags
+func (l LanguageTags) Supported() LanguageTags {
+	registry := currentRegistry()
+	var supported LanguageTags
+	for _, tag := range l {
+		if registry.Supported(tag) {
+			supported = append(supported, tag)
+		}
+	}
+	return supported
 }
 
 type MultilingualString map[LanguageTag]string
@@ -52,27 +96,81 @@ func (s MultilingualString) Empty() bool {
 	return true
 }
 
-func (s MultilingualString) MarshalJSON() ([]byte, error) {
-	s.RemoveUnsupported()
+// Best returns the first language of the chain the text is available in
+func (s MultilingualString) Best(chain LanguageTags) (LanguageTag, bool) {
+	for _, lang := range chain {
+		if s[lang] != "" {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Languages returns the languages the text is available in
+func (s MultilingualString) Languages() LanguageTags {
+	var langs LanguageTags
+	for lang, text := range s {
+		if lang != "" && text != "" {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
 
+// Filter returns a copy containing only the given languages
+func (s MultilingualString) Filter(langs []LanguageTag) MultilingualString {
+	filtered := make(MultilingualString)
+	for _, lang := range langs {
+		v, ok := s[lang]
+		if ok {
+			filtered[lang] = v
+		}
+	}
+	return filtered
+}
+
+// MarshalJSON omits unsupported languages, the text itself is left untouched
+func (s MultilingualString) MarshalJSON() ([]byte, error) {
+	registry := currentRegistry()
 	a := map[LanguageTag]string{}
 	for k, v := range s {
-		a[k] = v
+		if registry.Supported(k) {
+			a[k] = v
+		}
 	}
 
 	return json.Marshal(a)
 }
 
+// UnmarshalJSON reads the languages as BCP 47 tags, so "ua" and "uk-UA" are both stored as uk.
+// The text given under the canonical code wins over the one given under its alias.
+func (s *MultilingualString) UnmarshalJSON(data []byte) error {
+	var raw map[string]string
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	parsed := MultilingualString{}
+	for tag, text := range raw {
+		canonical, err := ParseLanguageTag(tag)
+		if err != nil {
+			// invalid tags are dropped like the unsupported ones
+			continue
+		}
+		if _, exact := raw[string(canonical)]; exact && string(canonical) != tag {
+			continue
+		}
+		parsed[canonical] = text
+	}
+	*s = parsed
+	return nil
+}
+
 func (s MultilingualString) RemoveUnsupported() {
+	registry := currentRegistry()
 	for lang := range s {
-		isSupported := false
-		for _, supportedLang := range supportedLanguages {
-			if supportedLang == lang {
-				isSupported = true
-				continue
-			}
-		}
-		if !isSupported {
+		if !registry.Supported(lang) {
 			delete(s, lang)
 		}
 	}
@@ -40,3 +40,23 @@ func TestTranslationEmpty(t *testing.T) {
 	field[Ukrainian] = "x"
 	assert.Equal(t, false, field.Empty())
 }
+
+func TestTranslationFilter(t *testing.T) {
+	title := MultilingualString{
+		Polish:    "tytuł",
+		Ukrainian: "титул",
+		English:   "title",
+	}
+
+	filtered := title.Filter([]LanguageTag{Ukrainian, English, "xx"})
+	assert.Equal(t, MultilingualString{Ukrainian: "титул", English: "title"}, filtered)
+	assert.Len(t, title, 3)
+}
+
+func TestLanguageTagsSupported(t *testing.T) {
+	assert.Equal(t, LanguageTags{Polish, English}, LanguageTags{Polish, "xx", English}.Supported())
+	assert.Nil(t, LanguageTags{"xx"}.Supported())
+	assert.Equal(t, LanguageTags{English, Polish, Ukrainian}, SupportedLanguages())
+	assert.True(t, LanguageTags{Polish, English}.Contains(English))
+	assert.False(t, LanguageTags{Polish}.Contains(Ukrainian))
+}
@@ -0,0 +1,13 @@
+package translation
+
+import (
+	"context"
+	"errors"
+)
+
+var UnsupportedLanguageErr = errors.New("language is not supported by the translator")
+
+// Translator translates plain text between languages, e.g. with a machine translation service
+type Translator interface {
+	Translate(ctx context.Context, text string, source LanguageTag, target LanguageTag) (string, error)
+}
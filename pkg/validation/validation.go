@@ -0,0 +1,131 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var InvalidErr = errors.New("validation failed")
+
+// Codes of FieldError, clients can rely on them, unlike on the messages
+const (
+	RequiredCode            = "required"
+	InvalidFormatCode       = "invalid_format"
+	TooLongCode             = "too_long"
+	InvalidCode             = "invalid"
+	InvalidLanguageCode     = "invalid_language"
+	UnsupportedLanguageCode = "unsupported_language"
+	DuplicateLanguageCode   = "duplicate_language"
+	NoCommonLanguageCode    = "no_common_language"
+)
+
+// Params are the values the code is parametrized with, e.g. max_length of too_long
+type Params map[string]interface{}
+
+// FieldError tells why the field was rejected. Field is the path of the field in the payload, e.g. "contact_details.mail" or "title.pl".
+// Err is the domain error the field error stands for, so errors.Is works with both.
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Params Params `json:"params,omitempty"`
+	Err    error  `json:"-"`
+}
+
+func NewFieldError(field string, code string, err error) FieldError {
+	return FieldError{Field: field, Code: code, Err: err}
+}
+
+// With returns the copy of the error with the param added
+func (e FieldError) With(name string, value interface{}) FieldError {
+	params := Params{}
+	for k, v := range e.Params {
+		params[k] = v
+	}
+	params[name] = value
+	e.Params = params
+	return e
+}
+
+func (e FieldError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %s", e.Field, e.Code, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Code)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors are all problems found while validating, they match InvalidErr and the domain errors of every field
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	var messages []string
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Error())
+	}
+	return fmt.Sprintf("%s: %s", InvalidErr, strings.Join(messages, ", "))
+}
+
+func (e Errors) Is(target error) bool {
+	if target == InvalidErr {
+		return true
+	}
+	for _, fieldErr := range e {
+		if errors.Is(fieldErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add appends the field error, it's meant to be used while collecting all problems before returning them by Err
+func (e *Errors) Add(fieldErr FieldError) {
+	*e = append(*e, fieldErr)
+}
+
+// Err returns nil when nothing was collected, so the result can be returned right away
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Prefixed returns the errors with paths nested in the field, e.g. mail becomes contact_details.mail
+func (e Errors) Prefixed(field string) Errors {
+	prefixed := make(Errors, 0, len(e))
+	for _, fieldErr := range e {
+		if fieldErr.Field == "" {
+			fieldErr.Field = field
+		} else {
+			fieldErr.Field = field + "." + fieldErr.Field
+		}
+		prefixed = append(prefixed, fieldErr)
+	}
+	return prefixed
+}
+
+// As returns the validation errors of err, ok is false if err is not a validation error
+func As(err error) (Errors, bool) {
+	var validationErrors Errors
+	if errors.As(err, &validationErrors) {
+		return validationErrors, true
+	}
+	var fieldErr FieldError
+	if errors.As(err, &fieldErr) {
+		return Errors{fieldErr}, true
+	}
+	return nil, false
+}
+
+// Prefix nests the paths of validation errors in the field, other errors are returned as they are
+func Prefix(err error, field string) error {
+	validationErrors, ok := As(err)
+	if !ok {
+		return err
+	}
+	return validationErrors.Prefixed(field)
+}
@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	missingErr := errors.New("missing")
+	var errs Errors
+	assert.NoError(t, errs.Err())
+
+	errs.Add(NewFieldError("mail", RequiredCode, missingErr))
+	errs.Add(NewFieldError("", InvalidCode, nil).With("reason", "x"))
+	err := errs.Err()
+	assert.ErrorIs(t, err, InvalidErr)
+	assert.ErrorIs(t, err, missingErr)
+	assert.NotErrorIs(t, err, errors.New("missing"))
+	assert.Equal(t, "validation failed: mail: required: missing, : invalid", err.Error())
+
+	prefixed, ok := As(Prefix(fmt.Errorf("wrapped: %w", err), "contact_details"))
+	assert.True(t, ok)
+	assert.Equal(t, "contact_details.mail", prefixed[0].Field)
+	assert.Equal(t, "contact_details", prefixed[1].Field)
+	assert.Equal(t, Params{"reason": "x"}, prefixed[1].Params)
+	assert.Equal(t, "mail", errs[0].Field, "prefixing makes a copy")
+
+	fieldErrors, ok := As(NewFieldError("title", TooLongCode, nil))
+	assert.True(t, ok)
+	assert.Len(t, fieldErrors, 1)
+
+	_, ok = As(missingErr)
+	assert.False(t, ok)
+	assert.Equal(t, missingErr, Prefix(missingErr, "mail"))
+}
+
+func TestFieldErrorWith(t *testing.T) {
+	base := NewFieldError("title", TooLongCode, nil).With("max_length", 100)
+	extended := base.With("language", "pl")
+	assert.Equal(t, Params{"max_length": 100}, base.Params)
+	assert.Equal(t, Params{"max_length": 100, "language": "pl"}, extended.Params)
+}
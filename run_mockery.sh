@@ -1,11 +1,12 @@
 #!/bin/bash
 function mock {
-  mockery --dir "$INPUT_DIR" --name "$NAME" --filename mock.go --output "$OUTPUT_DIR" --structname "$STRUCT_NAME" --outpkg "$OUT_PKG"
+  mockery --dir "$INPUT_DIR" --name "$NAME" --filename "$FILENAME" --output "$OUTPUT_DIR" --structname "$STRUCT_NAME" --outpkg "$OUT_PKG"
   rm -rf mocks
 }
 
 export NAME=Repository # Name of the interface which is going to be mocked
 export STRUCT_NAME=RepositoryMock # The output struct name
+export FILENAME=mock.go
 
 
 export INPUT_DIR=domain/advert
@@ -17,3 +18,31 @@ export INPUT_DIR=domain/user
 export OUTPUT_DIR=internal/user
 export OUT_PKG=user
 mock
+
+export INPUT_DIR=domain/report
+export OUTPUT_DIR=internal/report
+export OUT_PKG=report
+mock
+
+export INPUT_DIR=domain/favourite
+export OUTPUT_DIR=internal/favourite
+export OUT_PKG=favourite
+mock
+
+export INPUT_DIR=domain/search
+export OUTPUT_DIR=internal/search
+export OUT_PKG=search
+mock
+
+export INPUT_DIR=domain/conversation
+export OUTPUT_DIR=internal/conversation
+export OUT_PKG=conversation
+mock
+
+export NAME=LogRepository
+export STRUCT_NAME=LogRepositoryMock
+export FILENAME=log_mock.go
+export INPUT_DIR=domain/advert
+export OUTPUT_DIR=internal/advert
+export OUT_PKG=advert
+mock
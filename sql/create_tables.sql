@@ -8,7 +8,10 @@ create table users
     name         varchar(15),
     surname      varchar(15),
     mail         varchar(45),
-    phone_number varchar(15)
+    phone_number varchar(15),
+    role         varchar(15) default 'user' not null,
+    trusted      boolean     default false not null,
+    language     varchar(3)  default ''     not null
 );
 
 alter table users
@@ -16,18 +19,32 @@ alter table users
 
 create table adverts
 (
-    id              varchar(36),
-    user_id         varchar(36)
+    id                varchar(36),
+    user_id           varchar(36)
         constraint user___fk
             references users,
-    created_at      timestamp default now(),
-    updated_at      timestamp,
-    destroyed_at    timestamp,
-    title           varchar(50),
-    description     varchar(250),
-    type            varchar(15),
-    views           integer,
-    contact_details json
+    created_at        timestamp   default now(),
+    updated_at        timestamp,
+    destroyed_at      timestamp,
+    expires_at        timestamp   default now() + interval '14 days' not null,
+    archived_at       timestamp,
+    translated_at     timestamp,
+    type              varchar(15),
+    views             integer     default 0 not null,
+    contact_reveals   integer     default 0 not null,
+    contact_details   json,
+    city              varchar(60),
+    region            varchar(60),
+    country           varchar(60),
+    latitude          double precision,
+    longitude         double precision,
+    attributes        json        default '{}' not null,
+    status            varchar(15) default 'pending' not null,
+    moderation_reason varchar(500),
+    -- failed machine translations, the advert is left untranslated after too many of them
+    translation_attempts integer default 0 not null,
+    -- incremented on every change, so changes made to an outdated copy of the advert are rejected
+    version integer default 0 not null
 );
 
 alter table adverts
@@ -36,6 +53,301 @@ alter table adverts
 create unique index adverts_id_uindex
     on adverts (id);
 
+create index adverts_created_at_id_index
+    on adverts (created_at desc, id desc);
+
+create index adverts_expires_at_index
+    on adverts (expires_at)
+    where archived_at is null;
+
+create index adverts_status_index
+    on adverts (status);
+
+create index adverts_city_index
+    on adverts (lower(city));
+
+create index adverts_region_index
+    on adverts (lower(region));
+
+create index adverts_latitude_index
+    on adverts (latitude);
+
+create index adverts_untranslated_index
+    on adverts (translation_attempts, created_at)
+    where translated_at is null and destroyed_at is null and archived_at is null;
+
+-- great-circle distance between two points computed with haversine formula like domain.Coordinates.DistanceKm,
+-- rounding can push the sine of nearly antipodal points above 1, so it's capped to keep asin in its domain
+create function advert_distance_km(lat1 double precision, lon1 double precision,
+                                   lat2 double precision, lon2 double precision) returns double precision
+    language sql
+    immutable as
+$$
+select 2 * 6371 * asin(least(1, sqrt(
+            power(sin(radians(lat2 - lat1) / 2), 2) +
+            cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lon2 - lon1) / 2), 2)
+    )))
+$$;
+
+create table adverts_details
+(
+    id          varchar(36) not null
+        constraint adverts_details_pk
+            primary key,
+    advert_id   varchar(36)
+        constraint advert___fk
+            references adverts (id)
+            on delete cascade,
+    language           varchar(5),
+    title              varchar(100),
+    description        varchar(1000),
+    machine_translated boolean default false not null
+);
+
+alter table adverts_details
+    owner to postgres;
+
+create index adverts_details_advert_id_index
+    on adverts_details (advert_id);
+
+-- full-text search, there are no built-in polish, ukrainian and czech configurations so they use simple one with unaccent
+create extension if not exists unaccent;
+
+create text search configuration simple_unaccent (copy = simple);
+
+alter text search configuration simple_unaccent
+    alter mapping for hword, hword_part, word with unaccent, simple;
+
+create function advert_search_config(language varchar) returns regconfig
+    language sql
+    immutable as
+$$
+select case language
+           when 'en' then 'english'::regconfig
+           when 'de' then 'german'::regconfig
+           when 'ru' then 'russian'::regconfig
+           when 'ro' then 'romanian'::regconfig
+           else 'simple_unaccent'::regconfig
+           end
+$$;
+
+create function advert_search_vector(language varchar, title varchar, description varchar) returns tsvector
+    language sql
+    immutable as
+$$
+select setweight(to_tsvector(advert_search_config(language), coalesce(title, '')), 'A') ||
+       setweight(to_tsvector(advert_search_config(language), coalesce(description, '')), 'B')
+$$;
+
+create index adverts_details_search_index
+    on adverts_details using gin (advert_search_vector(language, title, description));
+
+create table advert_images
+(
+    id           varchar(36) not null
+        constraint advert_images_pk
+            primary key,
+    advert_id    varchar(36) not null
+        constraint advert_images_advert___fk
+            references adverts (id)
+            on delete cascade,
+    content_type varchar(15) not null,
+    width        integer     not null,
+    height       integer     not null,
+    created_at   timestamp   default now() not null
+);
+
+alter table advert_images
+    owner to postgres;
+
+create index advert_images_advert_id_index
+    on advert_images (advert_id);
+
+create table advert_favourites
+(
+    user_id    varchar(36) not null
+        constraint advert_favourites_user___fk
+            references users (id)
+            on delete cascade,
+    advert_id  varchar(36) not null
+        constraint advert_favourites_advert___fk
+            references adverts (id)
+            on delete cascade,
+    created_at timestamp default now() not null,
+    constraint advert_favourites_pk
+        primary key (user_id, advert_id)
+);
+
+alter table advert_favourites
+    owner to postgres;
+
+create index advert_favourites_user_id_created_at_index
+    on advert_favourites (user_id, created_at desc);
+
+create table advert_logs
+(
+    id         varchar(36) not null
+        constraint advert_logs_pk
+            primary key,
+    advert_id  varchar(36)
+        constraint advert_logs_advert___fk
+            references adverts (id)
+            on delete cascade,
+    -- no foreign key on purpose, the audit log has to outlive its users
+    user_id    varchar(36),
+    trigger    varchar(15),
+    meta       json,
+    created_at timestamp default now()
+);
+
+alter table advert_logs
+    owner to postgres;
+
+create index advert_logs_advert_id_index
+    on advert_logs (advert_id);
+
+create table advert_reports
+(
+    id          varchar(36) not null
+        constraint advert_reports_pk
+            primary key,
+    advert_id   varchar(36) not null
+        constraint advert_reports_advert___fk
+            references adverts (id)
+            on delete cascade,
+    -- null for anonymous reports
+    reporter_id varchar(36)
+        constraint advert_reports_reporter___fk
+            references users (id)
+            on delete set null,
+    -- address of the anonymous reporter, so it's counted once
+    reporter_ip varchar(45),
+    reason      varchar(15) not null,
+    details     varchar(1000) default '' not null,
+    created_at  timestamp   default now() not null,
+    resolved_at timestamp,
+    resolved_by varchar(36),
+    resolution  varchar(15)
+);
+
+alter table advert_reports
+    owner to postgres;
+
+create index advert_reports_open_index
+    on advert_reports (advert_id)
+    where resolved_at is null;
+
+create table conversations
+(
+    id              varchar(36)             not null
+        constraint conversations_pk
+            primary key,
+    advert_id       varchar(36)             not null
+        constraint conversations_advert___fk
+            references adverts (id)
+            on delete cascade,
+    owner_id        varchar(36)             not null
+        constraint conversations_owner___fk
+            references users (id)
+            on delete cascade,
+    seeker_id       varchar(36)             not null
+        constraint conversations_seeker___fk
+            references users (id)
+            on delete cascade,
+    owner_read_at   timestamp,
+    seeker_read_at  timestamp,
+    last_message_at timestamp default now() not null,
+    -- set when the owner blocks the seeker
+    blocked_at      timestamp,
+    created_at      timestamp default now() not null,
+    constraint conversations_advert_seeker_uindex
+        unique (advert_id, seeker_id)
+);
+
+alter table conversations
+    owner to postgres;
+
+create index conversations_owner_id_index
+    on conversations (owner_id, last_message_at desc);
+
+create index conversations_seeker_id_index
+    on conversations (seeker_id, last_message_at desc);
+
+create table conversation_messages
+(
+    id              varchar(36)             not null
+        constraint conversation_messages_pk
+            primary key,
+    conversation_id varchar(36)             not null
+        constraint conversation_messages_conversation___fk
+            references conversations (id)
+            on delete cascade,
+    sender_id       varchar(36)             not null,
+    body            varchar(2000)           not null,
+    created_at      timestamp default now() not null
+);
+
+alter table conversation_messages
+    owner to postgres;
+
+create index conversation_messages_conversation_id_created_at_index
+    on conversation_messages (conversation_id, created_at desc);
+
+create table saved_searches
+(
+    id         varchar(36)                    not null
+        constraint saved_searches_pk
+            primary key,
+    user_id    varchar(36)                    not null
+        constraint saved_searches_user___fk
+            references users (id)
+            on delete cascade,
+    -- empty arrays mean any type or language
+    types      varchar(15)[]    default '{}'  not null,
+    languages  varchar(5)[]     default '{}'  not null,
+    city       varchar(60)      default ''    not null,
+    region     varchar(60)      default ''    not null,
+    latitude   double precision,
+    longitude  double precision,
+    radius_km  double precision default 0     not null,
+    query      varchar(100)     default ''    not null,
+    created_at timestamp        default now() not null
+);
+
+alter table saved_searches
+    owner to postgres;
+
+create index saved_searches_user_id_index
+    on saved_searches (user_id);
+
+create index saved_searches_types_index
+    on saved_searches using gin (types);
+
+create table saved_search_matches
+(
+    search_id   varchar(36) not null
+        constraint saved_search_matches_search___fk
+            references saved_searches (id)
+            on delete cascade,
+    user_id     varchar(36) not null,
+    advert_id   varchar(36) not null
+        constraint saved_search_matches_advert___fk
+            references adverts (id)
+            on delete cascade,
+    created_at  timestamp default now() not null,
+    -- set once the match has been sent in the digest
+    notified_at timestamp,
+    constraint saved_search_matches_pk
+        primary key (search_id, advert_id)
+);
+
+alter table saved_search_matches
+    owner to postgres;
+
+create index saved_search_matches_pending_index
+    on saved_search_matches (created_at)
+    where notified_at is null;
+
 create unique index users_id_uindex
     on users (id);
 
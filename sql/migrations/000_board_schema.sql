@@ -0,0 +1,306 @@
+-- Upgrades the database created from the first create_tables.sql, which had only users and adverts, to the schema
+-- the following migrations start from: moderation, expiry, locations, attributes, images, favourites, history,
+-- reports, conversations, saved searches and full-text search of the texts in adverts_details.
+-- The texts have always been written to adverts_details, the title and description columns of adverts were never
+-- filled, so they are dropped. adverts_details is created only if it wasn't created by hand already.
+begin;
+
+alter table users
+    add column if not exists role    varchar(15) default 'user' not null,
+    add column if not exists trusted boolean     default false not null;
+
+-- adverts added before moderation existed stay listed, new ones wait for the review
+alter table adverts
+    add column if not exists status varchar(15) default 'published' not null;
+
+alter table adverts
+    alter column status set default 'pending';
+
+update adverts
+set views = 0
+where views is null;
+
+alter table adverts
+    drop column if exists title,
+    drop column if exists description,
+    alter column views set default 0,
+    alter column views set not null,
+    -- existing adverts get two weeks from the upgrade, so they don't disappear at once
+    add column if not exists expires_at        timestamp   default now() + interval '14 days' not null,
+    add column if not exists archived_at       timestamp,
+    add column if not exists translated_at     timestamp,
+    add column if not exists contact_reveals   integer     default 0 not null,
+    add column if not exists city              varchar(60),
+    add column if not exists region            varchar(60),
+    add column if not exists country           varchar(60),
+    add column if not exists latitude          double precision,
+    add column if not exists longitude         double precision,
+    add column if not exists attributes        json        default '{}' not null,
+    add column if not exists moderation_reason varchar(500);
+
+create index if not exists adverts_created_at_id_index
+    on adverts (created_at desc, id desc);
+
+create index if not exists adverts_expires_at_index
+    on adverts (expires_at)
+    where archived_at is null;
+
+create index if not exists adverts_status_index
+    on adverts (status);
+
+create index if not exists adverts_city_index
+    on adverts (lower(city));
+
+create index if not exists adverts_region_index
+    on adverts (lower(region));
+
+create index if not exists adverts_latitude_index
+    on adverts (latitude);
+
+create index if not exists adverts_untranslated_index
+    on adverts (created_at)
+    where translated_at is null and destroyed_at is null and archived_at is null;
+
+create or replace function advert_distance_km(lat1 double precision, lon1 double precision,
+                                              lat2 double precision, lon2 double precision) returns double precision
+    language sql
+    immutable as
+$$
+select 2 * 6371 * asin(sqrt(
+            power(sin(radians(lat2 - lat1) / 2), 2) +
+            cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lon2 - lon1) / 2), 2)
+    ))
+$$;
+
+create table if not exists adverts_details
+(
+    id          varchar(36) not null
+        constraint adverts_details_pk
+            primary key,
+    advert_id   varchar(36)
+        constraint advert___fk
+            references adverts (id)
+            on delete cascade,
+    language    varchar(5),
+    title       varchar(100),
+    description varchar(1000)
+);
+
+alter table adverts_details
+    alter column title type varchar(100),
+    alter column description type varchar(1000),
+    add column if not exists machine_translated boolean default false not null;
+
+create index if not exists adverts_details_advert_id_index
+    on adverts_details (advert_id);
+
+create extension if not exists unaccent;
+
+do
+$$
+    begin
+        if not exists(select from pg_ts_config where cfgname = 'simple_unaccent') then
+            create text search configuration simple_unaccent (copy = simple);
+            alter text search configuration simple_unaccent
+                alter mapping for hword, hword_part, word with unaccent, simple;
+        end if;
+    end
+$$;
+
+create or replace function advert_search_config(language varchar) returns regconfig
+    language sql
+    immutable as
+$$
+select case language
+           when 'en' then 'english'::regconfig
+           else 'simple_unaccent'::regconfig
+           end
+$$;
+
+create or replace function advert_search_vector(language varchar, title varchar, description varchar) returns tsvector
+    language sql
+    immutable as
+$$
+select setweight(to_tsvector(advert_search_config(language), coalesce(title, '')), 'A') ||
+       setweight(to_tsvector(advert_search_config(language), coalesce(description, '')), 'B')
+$$;
+
+create index if not exists adverts_details_search_index
+    on adverts_details using gin (advert_search_vector(language, title, description));
+
+create table if not exists advert_images
+(
+    id           varchar(36) not null
+        constraint advert_images_pk
+            primary key,
+    advert_id    varchar(36) not null
+        constraint advert_images_advert___fk
+            references adverts (id)
+            on delete cascade,
+    content_type varchar(15) not null,
+    width        integer     not null,
+    height       integer     not null,
+    created_at   timestamp   default now() not null
+);
+
+create index if not exists advert_images_advert_id_index
+    on advert_images (advert_id);
+
+create table if not exists advert_favourites
+(
+    user_id    varchar(36) not null
+        constraint advert_favourites_user___fk
+            references users (id)
+            on delete cascade,
+    advert_id  varchar(36) not null
+        constraint advert_favourites_advert___fk
+            references adverts (id)
+            on delete cascade,
+    created_at timestamp default now() not null,
+    constraint advert_favourites_pk
+        primary key (user_id, advert_id)
+);
+
+create index if not exists advert_favourites_user_id_created_at_index
+    on advert_favourites (user_id, created_at desc);
+
+create table if not exists advert_logs
+(
+    id         varchar(36) not null
+        constraint advert_logs_pk
+            primary key,
+    advert_id  varchar(36)
+        constraint advert_logs_advert___fk
+            references adverts (id)
+            on delete cascade,
+    user_id    varchar(36),
+    trigger    varchar(15),
+    meta       json,
+    created_at timestamp default now()
+);
+
+create index if not exists advert_logs_advert_id_index
+    on advert_logs (advert_id);
+
+create table if not exists advert_reports
+(
+    id          varchar(36) not null
+        constraint advert_reports_pk
+            primary key,
+    advert_id   varchar(36) not null
+        constraint advert_reports_advert___fk
+            references adverts (id)
+            on delete cascade,
+    reporter_id varchar(36)
+        constraint advert_reports_reporter___fk
+            references users (id)
+            on delete set null,
+    reason      varchar(15) not null,
+    details     varchar(1000) default '' not null,
+    created_at  timestamp   default now() not null,
+    resolved_at timestamp,
+    resolved_by varchar(36),
+    resolution  varchar(15)
+);
+
+create index if not exists advert_reports_open_index
+    on advert_reports (advert_id)
+    where resolved_at is null;
+
+create table if not exists conversations
+(
+    id              varchar(36)             not null
+        constraint conversations_pk
+            primary key,
+    advert_id       varchar(36)             not null
+        constraint conversations_advert___fk
+            references adverts (id)
+            on delete cascade,
+    owner_id        varchar(36)             not null
+        constraint conversations_owner___fk
+            references users (id)
+            on delete cascade,
+    seeker_id       varchar(36)             not null
+        constraint conversations_seeker___fk
+            references users (id)
+            on delete cascade,
+    owner_read_at   timestamp,
+    seeker_read_at  timestamp,
+    last_message_at timestamp default now() not null,
+    blocked_at      timestamp,
+    created_at      timestamp default now() not null,
+    constraint conversations_advert_seeker_uindex
+        unique (advert_id, seeker_id)
+);
+
+create index if not exists conversations_owner_id_index
+    on conversations (owner_id, last_message_at desc);
+
+create index if not exists conversations_seeker_id_index
+    on conversations (seeker_id, last_message_at desc);
+
+create table if not exists conversation_messages
+(
+    id              varchar(36)             not null
+        constraint conversation_messages_pk
+            primary key,
+    conversation_id varchar(36)             not null
+        constraint conversation_messages_conversation___fk
+            references conversations (id)
+            on delete cascade,
+    sender_id       varchar(36)             not null,
+    body            varchar(2000)           not null,
+    created_at      timestamp default now() not null
+);
+
+create index if not exists conversation_messages_conversation_id_created_at_index
+    on conversation_messages (conversation_id, created_at desc);
+
+create table if not exists saved_searches
+(
+    id         varchar(36)                    not null
+        constraint saved_searches_pk
+            primary key,
+    user_id    varchar(36)                    not null
+        constraint saved_searches_user___fk
+            references users (id)
+            on delete cascade,
+    types      varchar(15)[]    default '{}'  not null,
+    languages  varchar(5)[]     default '{}'  not null,
+    city       varchar(60)      default ''    not null,
+    region     varchar(60)      default ''    not null,
+    latitude   double precision,
+    longitude  double precision,
+    radius_km  double precision default 0     not null,
+    query      varchar(100)     default ''    not null,
+    created_at timestamp        default now() not null
+);
+
+create index if not exists saved_searches_user_id_index
+    on saved_searches (user_id);
+
+create index if not exists saved_searches_types_index
+    on saved_searches using gin (types);
+
+create table if not exists saved_search_matches
+(
+    search_id   varchar(36) not null
+        constraint saved_search_matches_search___fk
+            references saved_searches (id)
+            on delete cascade,
+    user_id     varchar(36) not null,
+    advert_id   varchar(36) not null
+        constraint saved_search_matches_advert___fk
+            references adverts (id)
+            on delete cascade,
+    created_at  timestamp default now() not null,
+    notified_at timestamp,
+    constraint saved_search_matches_pk
+        primary key (search_id, advert_id)
+);
+
+create index if not exists saved_search_matches_pending_index
+    on saved_search_matches (created_at)
+    where notified_at is null;
+
+commit;
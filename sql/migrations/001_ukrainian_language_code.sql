@@ -0,0 +1,29 @@
+-- Ukrainian texts and saved searches are moved from "ua" to the ISO 639-1 code "uk", the API still accepts "ua" as an alias.
+-- The search index is rebuilt, because advert_search_config now knows the languages the registry can add.
+begin;
+
+update adverts_details
+set language = 'uk'
+where language = 'ua';
+
+update saved_searches
+set languages = array_replace(languages, 'ua', 'uk')
+where 'ua' = any (languages);
+
+-- text search configurations of the languages which can be added by the registry
+create or replace function advert_search_config(language varchar) returns regconfig
+    language sql
+    immutable as
+$$
+select case language
+           when 'en' then 'english'::regconfig
+           when 'de' then 'german'::regconfig
+           when 'ru' then 'russian'::regconfig
+           when 'ro' then 'romanian'::regconfig
+           else 'simple_unaccent'::regconfig
+           end
+$$;
+
+reindex index adverts_details_search_index;
+
+commit;
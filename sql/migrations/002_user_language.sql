@@ -0,0 +1,8 @@
+-- Users get the language of API error messages, existing users start without one and keep getting
+-- messages in the language of their Accept-Language header until they choose it.
+begin;
+
+alter table users
+    add column language varchar(3) default '' not null;
+
+commit;
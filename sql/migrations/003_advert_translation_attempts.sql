@@ -0,0 +1,13 @@
+-- Failed machine translations are counted, so adverts which can't be translated stop blocking the translation queue.
+begin;
+
+alter table adverts
+    add column translation_attempts integer default 0 not null;
+
+drop index if exists adverts_untranslated_index;
+
+create index adverts_untranslated_index
+    on adverts (translation_attempts, created_at)
+    where translated_at is null and destroyed_at is null and archived_at is null;
+
+commit;
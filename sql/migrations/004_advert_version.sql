@@ -0,0 +1,7 @@
+-- Adverts get a version, so an owner editing an outdated copy can't revert changes made by moderators and workers.
+begin;
+
+alter table adverts
+    add column version integer default 0 not null;
+
+commit;
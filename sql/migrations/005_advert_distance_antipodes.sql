@@ -0,0 +1,15 @@
+-- advert_distance_km failed with "input is out of range" for nearly antipodal points, the sine is now capped at 1.
+begin;
+
+create or replace function advert_distance_km(lat1 double precision, lon1 double precision,
+                                              lat2 double precision, lon2 double precision) returns double precision
+    language sql
+    immutable as
+$$
+select 2 * 6371 * asin(least(1, sqrt(
+            power(sin(radians(lat2 - lat1) / 2), 2) +
+            cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lon2 - lon1) / 2), 2)
+    )))
+$$;
+
+commit;
@@ -0,0 +1,7 @@
+-- Anonymous reports keep the address of the reporter, so one client can't report the advert repeatedly and hide it alone.
+begin;
+
+alter table advert_reports
+    add column reporter_ip varchar(45);
+
+commit;